@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"kaepora/internal/back"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/util"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// diffSpoilerLogs writes the differences between two spoiler logs, each
+// given as a match ID or a spoiler log file.
+func diffSpoilerLogs(w io.Writer, b *back.Back, a, other string) error {
+	if a == "" || other == "" {
+		return errors.New("you must specify two match IDs or spoiler log files")
+	}
+
+	logA, err := loadSpoilerLog(b, a)
+	if err != nil {
+		return err
+	}
+	logB, err := loadSpoilerLog(b, other)
+	if err != nil {
+		return err
+	}
+
+	writeSpoilerLogDiff(w, oot.DiffSpoilerLogs(logA, logB))
+
+	return nil
+}
+
+func loadSpoilerLog(b *back.Back, name string) (oot.SpoilerLog, error) {
+	var raw []byte
+	if _, err := os.Stat(name); err == nil {
+		if raw, err = ioutil.ReadFile(name); err != nil {
+			return oot.SpoilerLog{}, err
+		}
+	} else {
+		id, err := uuid.Parse(name)
+		if err != nil {
+			return oot.SpoilerLog{}, fmt.Errorf("%q is neither a file nor a match ID", name)
+		}
+
+		match, err := b.GetMatch(util.UUIDAsBlob(id))
+		if err != nil {
+			return oot.SpoilerLog{}, err
+		}
+
+		if raw, err = ioutil.ReadAll(match.SpoilerLog.Uncompressed()); err != nil {
+			return oot.SpoilerLog{}, err
+		}
+	}
+
+	var ret oot.SpoilerLog
+	if err := json.Unmarshal(raw, &ret); err != nil {
+		return oot.SpoilerLog{}, fmt.Errorf("unable to parse spoiler log %s: %w", name, err)
+	}
+
+	return ret, nil
+}
+
+func writeSpoilerLogDiff(w io.Writer, diff oot.SpoilerLogDiff) {
+	if diff.IsEmpty() {
+		fmt.Fprintln(w, "Both spoiler logs are identical.")
+		return
+	}
+
+	if len(diff.Settings) > 0 {
+		fmt.Fprintln(w, "Settings:")
+		for _, v := range diff.Settings {
+			fmt.Fprintf(w, "  %s: %q -> %q\n", v.Name, v.A, v.B)
+		}
+	}
+
+	writeSetDiff(w, "Ways of the Hero", diff.WOTH)
+	writeSetDiff(w, "Barren regions", diff.Barren)
+
+	if len(diff.Spheres) > 0 || diff.SphereDepth[0] != diff.SphereDepth[1] {
+		fmt.Fprintf(w, "Spheres (depth %d -> %d):\n", diff.SphereDepth[0], diff.SphereDepth[1])
+		for _, v := range diff.Spheres {
+			fmt.Fprintf(w, "  %s: %d -> %d\n", v.Item, v.A, v.B)
+		}
+	}
+
+	if len(diff.MovedItems) > 0 {
+		fmt.Fprintln(w, "Moved items:")
+		for _, v := range diff.MovedItems {
+			fmt.Fprintf(w, "  %s: %s -> %s\n", v.Item, joinLocations(v.A), joinLocations(v.B))
+		}
+	}
+}
+
+func writeSetDiff(w io.Writer, title string, diff oot.SetDiff) {
+	if diff.IsEmpty() {
+		return
+	}
+
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, v := range diff.OnlyA {
+		fmt.Fprintf(w, "  - %s\n", v)
+	}
+	for _, v := range diff.OnlyB {
+		fmt.Fprintf(w, "  + %s\n", v)
+	}
+}
+
+func joinLocations(locations []oot.SpoilerLogLocation) string {
+	if len(locations) == 0 {
+		return "(none)"
+	}
+
+	strs := make([]string, len(locations))
+	for k := range locations {
+		strs[k] = string(locations[k])
+	}
+
+	return strings.Join(strs, ", ")
+}
@@ -3,12 +3,15 @@ package back
 
 import (
 	"fmt"
+	"kaepora/internal/blob"
 	"kaepora/internal/config"
 	"kaepora/internal/generator"
 	"kaepora/internal/generator/factory"
+	"kaepora/internal/generator/oot"
 	"kaepora/internal/util"
 	"kaepora/pkg/ootrapi"
 	"log"
+	"net/http"
 	"sync"
 	"time"
 
@@ -19,16 +22,40 @@ import (
 // It is the back-end of the web and bot front-ends.
 type Back struct {
 	db               *sqlx.DB
-	generatorFactory factory.Factory
+	generatorFactory generatorFactory
 	config           *config.Config
 
-	// notifications receives content from the Back and MUST be consumed externally.
-	notifications chan Notification
-
 	// It is possible to fetch the same session twice to count it down, this
 	// cache avoid starting the same session twice.  This is only used in
 	// countdownAndStartMatchSession which is _not_ run concurrently.
 	countingDown map[util.UUIDAsBlob]struct{}
+
+	// refillingSeedPools is set to 1 while the seed pools are being refilled
+	// in the background, see maybeRefillSeedPools.
+	refillingSeedPools int32
+
+	// seedgen is the queue through which all seeds are generated.
+	seedgen *seedgenQueue
+
+	// blobs stores the Match spoiler logs and patches, nil if they are kept
+	// in the DB.
+	blobs blob.Store
+
+	// blobsCollectedAt is when unreferenced blobs were last deleted, see
+	// maybeCollectBlobs.
+	blobsCollectedAt time.Time
+
+	// webhookClient POSTs the notifications to the webhooks.
+	webhookClient *http.Client
+
+	// sleep waits between seed generation attempts, overridden in tests.
+	sleep func(time.Duration)
+}
+
+// generatorFactory creates Generator instances from their ID, see
+// factory.Factory.
+type generatorFactory interface {
+	NewGenerator(id string) (generator.Generator, error)
 }
 
 // New creates a new ladder backend ready to be run with Run.
@@ -45,13 +72,36 @@ func New(sqlDriver, sqlDSN string, config *config.Config) (*Back, error) {
 		return nil, err
 	}
 
-	return &Back{
+	ootRunner, err := oot.NewRunner(config.OOTRunner)
+	if err != nil {
+		return nil, err
+	}
+
+	generatorFactory := factory.New(
+		ootrapi.New(config.OOTRAPIKey),
+		ootRunner,
+		config.ProcessGenerators(),
+	)
+
+	b := &Back{
 		db:               db,
 		config:           config,
-		notifications:    make(chan Notification, 32),
 		countingDown:     map[util.UUIDAsBlob]struct{}{},
-		generatorFactory: factory.New(ootrapi.New(config.OOTRAPIKey)),
-	}, nil
+		generatorFactory: generatorFactory,
+		webhookClient:    &http.Client{Timeout: webhookTimeout},
+		sleep:            time.Sleep,
+	}
+	b.seedgen = newSeedgenQueue(config.Seedgen.GetWorkers(), b.seedgenConcurrencyLimit)
+
+	if config.BlobStoreDir != "" {
+		store, err := blob.NewFS(config.BlobStoreDir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open blob store: %w", err)
+		}
+		b.blobs = store
+	}
+
+	return b, nil
 }
 
 // GetGenerator returns a new Generator by name.
@@ -59,18 +109,19 @@ func (b *Back) GetGenerator(name string) (generator.Generator, error) {
 	return b.generatorFactory.NewGenerator(name)
 }
 
-// GetNotificationsChan returns the channel on which the Back sends the textual
-// notifications destined to either a specific player or a whole channel.
-func (b *Back) GetNotificationsChan() <-chan Notification {
-	return b.notifications
-}
-
 // Run performs all the matchmaking business until the done channel is closed.
 func (b *Back) Run(wg *sync.WaitGroup, done <-chan struct{}) {
 	wg.Add(1)
 	defer wg.Done()
 	log.Print("info: starting Back dæmon")
 
+	if err := b.rebuildMissingLeagueStats(); err != nil {
+		log.Printf("error: unable to rebuild missing league stats: %s", err)
+	}
+
+	wg.Add(1)
+	go b.runWebhookDispatcher(wg, done)
+
 	for {
 		if err := b.runPeriodicTasks(); err != nil {
 			log.Printf("error: runPeriodicTasks: %s", err)
@@ -0,0 +1,280 @@
+package back
+
+import (
+	"errors"
+	"fmt"
+	"kaepora/internal/util"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// storeMatchBlobs moves the spoiler log and patch of the match to the blob
+// store, if any, leaving only their keys in the Match.
+func (b *Back) storeMatchBlobs(match *Match) error {
+	return b.storeBlobs(
+		(*[]byte)(&match.SpoilerLog), &match.SpoilerLogKey,
+		&match.SeedPatch, &match.SeedPatchKey,
+	)
+}
+
+// loadMatchBlobs fills the spoiler log and patch of a match from the blob
+// store when they are not stored in the DB.
+func (b *Back) loadMatchBlobs(match *Match) error {
+	if err := b.loadBlobs(
+		(*[]byte)(&match.SpoilerLog), match.SpoilerLogKey,
+		&match.SeedPatch, match.SeedPatchKey,
+	); err != nil {
+		return fmt.Errorf("unable to load blobs of match %s: %w", match.ID, err)
+	}
+
+	return nil
+}
+
+// storePooledSeedBlobs moves the spoiler log and patch of a pooled seed to
+// the blob store, if any, leaving only their keys in the PooledSeed.
+func (b *Back) storePooledSeedBlobs(seed *PooledSeed) error {
+	return b.storeBlobs(
+		(*[]byte)(&seed.SpoilerLog), &seed.SpoilerLogKey,
+		&seed.SeedPatch, &seed.SeedPatchKey,
+	)
+}
+
+// loadPooledSeedBlobs fills the spoiler log and patch of a pooled seed from
+// the blob store when they are not stored in the DB.
+func (b *Back) loadPooledSeedBlobs(seed *PooledSeed) error {
+	if err := b.loadBlobs(
+		(*[]byte)(&seed.SpoilerLog), seed.SpoilerLogKey,
+		&seed.SeedPatch, seed.SeedPatchKey,
+	); err != nil {
+		return fmt.Errorf("unable to load blobs of pooled seed %s: %w", seed.ID, err)
+	}
+
+	return nil
+}
+
+func (b *Back) storeBlobs(spoilerLog *[]byte, spoilerLogKey *string, seedPatch *[]byte, seedPatchKey *string) error {
+	if b.blobs == nil {
+		return nil
+	}
+
+	if len(*spoilerLog) > 0 {
+		key, err := b.blobs.Put(*spoilerLog)
+		if err != nil {
+			return fmt.Errorf("unable to store spoiler log: %w", err)
+		}
+		*spoilerLogKey = key
+		*spoilerLog = nil
+	}
+
+	if len(*seedPatch) > 0 {
+		key, err := b.blobs.Put(*seedPatch)
+		if err != nil {
+			return fmt.Errorf("unable to store seed patch: %w", err)
+		}
+		*seedPatchKey = key
+		*seedPatch = nil
+	}
+
+	return nil
+}
+
+func (b *Back) loadBlobs(spoilerLog *[]byte, spoilerLogKey string, seedPatch *[]byte, seedPatchKey string) (err error) {
+	if spoilerLogKey != "" {
+		if *spoilerLog, err = b.getBlob(spoilerLogKey); err != nil {
+			return fmt.Errorf("spoiler log: %w", err)
+		}
+	}
+
+	if seedPatchKey != "" {
+		if *seedPatch, err = b.getBlob(seedPatchKey); err != nil {
+			return fmt.Errorf("seed patch: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Back) getBlob(key string) ([]byte, error) {
+	if b.blobs == nil {
+		return nil, errors.New("no blob store configured")
+	}
+
+	return b.blobs.Get(key)
+}
+
+// MigrateBlobsToStore moves all the spoiler logs and patches still stored in
+// the DB to the configured blob store and returns the number of moved matches
+// and pooled seeds.
+func (b *Back) MigrateBlobsToStore() (int, error) {
+	if b.blobs == nil {
+		return 0, errors.New("no blob store configured, set BlobStoreDir")
+	}
+
+	var matchIDs, pooledIDs []util.UUIDAsBlob
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		if err := tx.Select(&matchIDs, `
+            SELECT ID FROM Match
+            WHERE length(SpoilerLog) > 0 OR length(SeedPatch) > 0`,
+		); err != nil {
+			return err
+		}
+
+		return tx.Select(&pooledIDs, `
+            SELECT ID FROM PooledSeed
+            WHERE length(SpoilerLog) > 0 OR length(SeedPatch) > 0`,
+		)
+	}); err != nil {
+		return 0, err
+	}
+
+	// One transaction per row so an interrupted migration can be resumed.
+	for _, id := range matchIDs {
+		if err := b.transaction(func(tx *sqlx.Tx) error {
+			match, err := getMatchByID(tx, id)
+			if err != nil {
+				return err
+			}
+
+			if err := b.storeMatchBlobs(&match); err != nil {
+				return err
+			}
+
+			return match.update(tx)
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, id := range pooledIDs {
+		if err := b.transaction(func(tx *sqlx.Tx) error {
+			seed, err := getPooledSeedByID(tx, id)
+			if err != nil {
+				return err
+			}
+
+			if err := b.storePooledSeedBlobs(&seed); err != nil {
+				return err
+			}
+
+			return seed.updateBlobs(tx)
+		}); err != nil {
+			return len(matchIDs), err
+		}
+	}
+
+	moved := len(matchIDs) + len(pooledIDs)
+	if moved > 0 {
+		// Reclaim the space freed by the blobs.
+		if _, err := b.db.Exec("VACUUM"); err != nil {
+			return moved, err
+		}
+	}
+
+	return moved, nil
+}
+
+// VerifyBlobs ensures all the blobs referenced by matches exist and match
+// their hash, corrupted blobs are logged.
+// Returns the number of blobs checked.
+func (b *Back) VerifyBlobs() (int, error) {
+	var keys []string
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		keys, err = getReferencedBlobKeys(tx)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	var failed int
+	for _, key := range keys {
+		if _, err := b.getBlob(key); err != nil {
+			log.Printf("error: blob %s: %s", key, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return len(keys), fmt.Errorf("%d/%d blobs failed verification", failed, len(keys))
+	}
+
+	return len(keys), nil
+}
+
+// blobCollectMinAge is how long an unreferenced blob is kept, blobs are
+// stored before the row referencing them is inserted.
+const blobCollectMinAge = 1 * time.Hour
+
+// CollectBlobs deletes the blobs that are no longer referenced by any match
+// or pooled seed, eg. after a seed pool was invalidated.
+// Returns the number of deleted blobs.
+func (b *Back) CollectBlobs() (int, error) {
+	if b.blobs == nil {
+		return 0, nil
+	}
+
+	var keys []string
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		keys, err = getReferencedBlobKeys(tx)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	referenced := make(map[string]struct{}, len(keys))
+	for _, v := range keys {
+		referenced[v] = struct{}{}
+	}
+
+	var deleted int
+	err := b.blobs.Walk(func(key string, putAt time.Time) error {
+		if _, ok := referenced[key]; ok || time.Since(putAt) < blobCollectMinAge {
+			return nil
+		}
+
+		if err := b.blobs.Delete(key); err != nil {
+			return err
+		}
+		deleted++
+
+		return nil
+	})
+
+	return deleted, err
+}
+
+// maybeCollectBlobs runs CollectBlobs at most once a day.
+func (b *Back) maybeCollectBlobs() {
+	if time.Since(b.blobsCollectedAt) < 24*time.Hour {
+		return
+	}
+	b.blobsCollectedAt = time.Now()
+
+	deleted, err := b.CollectBlobs()
+	if err != nil {
+		log.Printf("error: unable to collect blobs: %s", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("info: deleted %d unreferenced blobs", deleted)
+	}
+}
+
+// getReferencedBlobKeys returns the keys of all the blobs referenced by
+// matches and pooled seeds.
+func getReferencedBlobKeys(tx *sqlx.Tx) (ret []string, _ error) {
+	if err := tx.Select(&ret, `
+        SELECT SpoilerLogKey FROM Match WHERE SpoilerLogKey != ''
+        UNION
+        SELECT SeedPatchKey FROM Match WHERE SeedPatchKey != ''
+        UNION
+        SELECT SpoilerLogKey FROM PooledSeed WHERE SpoilerLogKey != ''
+        UNION
+        SELECT SeedPatchKey FROM PooledSeed WHERE SeedPatchKey != ''`,
+	); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,121 @@
+package back // nolint:testpackage
+
+import (
+	"io/ioutil"
+	"kaepora/internal/blob"
+	"kaepora/internal/util"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestMigrateBlobsToStore(t *testing.T) {
+	back := createFixturedTestBack(t)
+	innerTestMatchMaking(t, back)
+
+	if _, err := back.MigrateBlobsToStore(); err == nil {
+		t.Fatal("expected migration to fail without a blob store")
+	}
+
+	dir, err := ioutil.TempDir("", "kaepora-blobs-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	back.blobs, err = blob.NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := back.MigrateBlobsToStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved == 0 {
+		t.Fatal("expected matches to be migrated")
+	}
+
+	var id util.UUIDAsBlob
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		var left int
+		if err := tx.Get(&left, `
+            SELECT COUNT(*) FROM Match
+            WHERE LENGTH(SeedPatch) > 0 OR LENGTH(SpoilerLog) > 0 OR SeedPatchKey = ''`,
+		); err != nil {
+			return err
+		}
+		if left != 0 {
+			t.Errorf("expected all blobs to be moved out of the DB, %d matches left", left)
+		}
+
+		return tx.Get(&id, `SELECT ID FROM Match LIMIT 1`)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	match, err := back.GetMatch(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(match.SeedPatch) == 0 {
+		t.Error("expected the patch to be loaded from the blob store")
+	}
+
+	if _, err := back.VerifyBlobs(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollectBlobs(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	dir, err := ioutil.TempDir("", "kaepora-blobs-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	if back.blobs, err = blob.NewFS(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	innerTestMatchMaking(t, back)
+	orphan, err := back.blobs.Put([]byte("orphan"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Make everything old enough to be collected but the next Put.
+	past := time.Now().Add(-2 * blobCollectMinAge)
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		return os.Chtimes(path, past, past)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	recent, err := back.blobs.Put([]byte("recent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := back.CollectBlobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected only the orphan blob to be deleted, got %d", deleted)
+	}
+	if _, err := back.blobs.Get(orphan); err == nil {
+		t.Error("expected the orphan blob to be deleted")
+	}
+	if _, err := back.blobs.Get(recent); err != nil {
+		t.Errorf("expected the recent blob to be kept: %s", err)
+	}
+	if count, err := back.VerifyBlobs(); err != nil || count == 0 {
+		t.Errorf("expected referenced blobs to be kept, %d verified: %v", count, err)
+	}
+}
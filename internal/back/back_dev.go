@@ -3,6 +3,7 @@ package back
 import (
 	"fmt"
 	"kaepora/internal/back/schedule"
+	"kaepora/internal/generator"
 	"kaepora/internal/generator/factory"
 	"kaepora/internal/generator/oot"
 	"kaepora/internal/util"
@@ -13,50 +14,85 @@ import (
 	"gopkg.in/guregu/null.v4"
 )
 
-// SendDevSeed generates a seed from a league settings and sends it over
-// Discord. This was originally for dev purposes but is now accessible to
+// SendDevSeed queues the generation of a seed from a league settings and
+// sends it over Discord once generated, it returns the position of the seed in
+// the queue. This was originally for dev purposes but is now accessible to
 // everyone.
-func (b *Back) SendDevSeed(discordID, leagueShortCode, seed, version string) error {
-	return b.transaction(func(tx *sqlx.Tx) error {
-		league, err := getLeagueByShortCode(tx, leagueShortCode)
+// Kaepora does not run tournaments, their seeds are requested by admins and
+// are queued before the practice seeds.
+func (b *Back) SendDevSeed(discordID, leagueShortCode, seed, version string) (int, error) {
+	var league League
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		league, err = getLeagueByShortCode(tx, leagueShortCode)
 		if err != nil {
 			return fmt.Errorf("could not find League: %w", err)
 		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
 
-		generatorID := league.Generator
-		if version != "" {
-			generatorID = factory.OverrideVersion(generatorID, version)
-		}
+	generatorID := league.Generator
+	if version != "" {
+		generatorID = factory.OverrideVersion(generatorID, version)
+	}
 
-		gen, err := b.GetGenerator(generatorID)
-		if err != nil {
-			return err
-		}
+	gen, err := b.GetGenerator(generatorID)
+	if err != nil {
+		return 0, err
+	}
 
-		out, err := gen.Generate(league.Settings, seed)
-		if err != nil {
-			return err
-		}
+	priority := SeedgenPriorityPractice
+	if b.config.IsDiscordIDAdmin(discordID) {
+		priority = SeedgenPriorityTournament
+	}
 
-		if err := gen.UnlockSpoilerLog(out.State); err != nil {
-			return err
-		}
+	player := Player{DiscordID: null.NewString(discordID, true)}
+	pos := b.seedgen.push(
+		priority, generatorID,
+		fmt.Sprintf("!seed %s", league.ShortCode), discordID,
+		func() {
+			if err := b.generateAndSendDevSeed(gen, league.Settings, seed, player); err != nil {
+				log.Printf("error: unable to generate seed %s: %s", seed, err)
+				b.sendDevSeedFailureNotification(player, seed)
+			}
+		},
+	)
 
-		zlibLog, err := util.NewZLIBBlob(out.SpoilerLog)
-		if err != nil {
-			return err
-		}
+	return pos, nil
+}
+
+func (b *Back) generateAndSendDevSeed(
+	gen generator.Generator,
+	settings, seed string,
+	player Player,
+) error {
+	out, err := b.generate(gen, settings, seed)
+	if err != nil {
+		return err
+	}
 
-		player := Player{DiscordID: null.NewString(discordID, true)}
-		b.sendMatchSeedNotification(
-			MatchSession{},
+	if err := gen.UnlockSpoilerLog(out.State); err != nil {
+		return err
+	}
+
+	zlibLog, err := util.NewZLIBBlob(out.SpoilerLog)
+	if err != nil {
+		return err
+	}
+
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		return b.sendMatchSeedNotification(
+			tx, MatchSession{},
 			gen.GetDownloadURL(out.State), out,
 			player, Player{},
 		)
-		b.sendRawSpoilerLogNotification(player, seed, zlibLog)
+	}); err != nil {
+		return err
+	}
+	b.sendRawSpoilerLogNotification(player, seed, zlibLog)
 
-		return nil
-	})
+	return nil
 }
 
 // LoadFixtures fills the DB with dev data.
@@ -0,0 +1,74 @@
+package back
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/util"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// seedDifficulty computes the difficulty of a seed from its spoiler log,
+// seeds without an OoT spoiler log have a zero difficulty.
+func seedDifficulty(spoilerLog []byte) oot.SeedDifficulty {
+	if len(spoilerLog) == 0 {
+		return oot.SeedDifficulty{}
+	}
+
+	var parsed oot.SpoilerLog
+	if err := json.Unmarshal(spoilerLog, &parsed); err != nil {
+		log.Printf("debug: unable to parse spoiler log for difficulty: %s", err)
+		return oot.SeedDifficulty{}
+	}
+
+	return parsed.Difficulty()
+}
+
+// ComputeMatchDifficulties computes the difficulty of the seeds that were
+// generated before difficulties were stored and returns the number of
+// updated matches.
+func (b *Back) ComputeMatchDifficulties() (int, error) {
+	var ids []util.UUIDAsBlob
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		return tx.Select(&ids, `
+            SELECT ID FROM Match
+            WHERE Difficulty = '' AND (length(SpoilerLog) > 0 OR SpoilerLogKey != '')`,
+		)
+	}); err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, id := range ids {
+		if err := b.transaction(func(tx *sqlx.Tx) error {
+			match, err := getMatchByID(tx, id)
+			if err != nil {
+				return err
+			}
+			if err := b.loadMatchBlobs(&match); err != nil {
+				return err
+			}
+
+			raw, err := ioutil.ReadAll(match.SpoilerLog.Uncompressed())
+			if err != nil {
+				return err
+			}
+
+			match.Difficulty = seedDifficulty(raw)
+			if match.Difficulty.IsZero() {
+				return nil
+			}
+			count++
+
+			// Not match.update, the blobs may have been loaded from the store.
+			_, err = tx.Exec(`UPDATE Match SET Difficulty = ? WHERE ID = ?`, match.Difficulty, match.ID)
+			return err
+		}); err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,288 @@
+package back
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"kaepora/internal/back/seedstats"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/util"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// LeagueStats are the precomputed stats of a league. Misc stats are cheap
+// SQL aggregates recomputed after each ranking update, the other stats are
+// updated in the transaction closing each session, one session at a time as
+// decoding spoiler logs is slow. Settings value stats need the median of all
+// times and are recomputed from all closed sessions.
+// Stats are never built when read, leagues without stats are rebuilt when
+// the Back starts, see rebuildMissingLeagueStats.
+type LeagueStats struct {
+	LeagueID  util.UUIDAsBlob
+	UpdatedAt util.TimeAsTimestamp
+
+	StatsMisc
+	SeedStats      seedstats.Stats
+	Attendance     Attendance
+	SeedTimes      SeedTimes
+	SettingsValues SettingsValueStats
+}
+
+func getLeagueStats(tx *sqlx.Tx, leagueID util.UUIDAsBlob) (LeagueStats, error) {
+	var ret LeagueStats
+	if err := tx.Get(&ret, `SELECT * FROM LeagueStats WHERE LeagueID = ? LIMIT 1`, leagueID); err != nil {
+		return LeagueStats{}, err
+	}
+
+	return ret, nil
+}
+
+func (s *LeagueStats) upsert(tx *sqlx.Tx) error {
+	s.UpdatedAt = util.TimeAsTimestamp(time.Now())
+	_, err := tx.NamedExec(`
+        INSERT OR REPLACE INTO LeagueStats (
+            LeagueID, UpdatedAt,
+            RankedPlayers, PlayersOnLeaderboard, SeedsPlayed, Forfeits,
+            DoubleForfeits, FirstLadderRace, TotalSeedTime,
+            AveragePlayersPerRace, MostPlayersInARace,
+            SeedStats, Attendance, SeedTimes, SettingsValues
+        ) VALUES (
+            :LeagueID, :UpdatedAt,
+            :RankedPlayers, :PlayersOnLeaderboard, :SeedsPlayed, :Forfeits,
+            :DoubleForfeits, :FirstLadderRace, :TotalSeedTime,
+            :AveragePlayersPerRace, :MostPlayersInARace,
+            :SeedStats, :Attendance, :SeedTimes, :SettingsValues
+        )`,
+		s,
+	)
+
+	return err
+}
+
+// GetMiscStats returns the stored misc stats of a league.
+func (b *Back) GetMiscStats(shortcode string) (StatsMisc, error) {
+	stats, err := b.getStoredLeagueStats(shortcode)
+	if err != nil {
+		return StatsMisc{}, err
+	}
+
+	return stats.StatsMisc, nil
+}
+
+// GetSeedStats returns the stored seed stats of a league.
+func (b *Back) GetSeedStats(shortcode string) (seedstats.Stats, error) {
+	stats, err := b.getStoredLeagueStats(shortcode)
+	if err != nil {
+		return seedstats.Stats{}, err
+	}
+
+	return stats.SeedStats, nil
+}
+
+// GetAttendanceStats returns the stored attendance of a league.
+func (b *Back) GetAttendanceStats(shortcode string) (Attendance, error) {
+	stats, err := b.getStoredLeagueStats(shortcode)
+	if err != nil {
+		return Attendance{}, err
+	}
+
+	return stats.Attendance, nil
+}
+
+// getStoredLeagueStats returns the stats of a league or empty stats if they
+// were never built, it never writes.
+func (b *Back) getStoredLeagueStats(shortcode string) (ret LeagueStats, _ error) {
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		league, err := getLeagueByShortCode(tx, shortcode)
+		if err != nil {
+			return err
+		}
+
+		ret, err = getLeagueStats(tx, league.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			ret, err = LeagueStats{LeagueID: league.ID, SeedStats: *seedstats.New()}, nil
+		}
+
+		return err
+	}); err != nil {
+		return LeagueStats{}, err
+	}
+
+	return ret, nil
+}
+
+// RebuildLeagueStats recomputes all the stats of a league from scratch.
+func (b *Back) RebuildLeagueStats(shortcode string) error {
+	return b.transaction(func(tx *sqlx.Tx) error {
+		league, err := getLeagueByShortCode(tx, shortcode)
+		if err != nil {
+			return err
+		}
+
+		_, err = b.rebuildLeagueStats(tx, league)
+		return err
+	})
+}
+
+// rebuildMissingLeagueStats builds the stats of every league that has none,
+// eg. new leagues or after a migration discarded them. Each league is rebuilt
+// in its own transaction.
+func (b *Back) rebuildMissingLeagueStats() error {
+	var leagues []League
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		return tx.Select(&leagues, `
+            SELECT League.* FROM League
+            LEFT JOIN LeagueStats ON LeagueStats.LeagueID = League.ID
+            WHERE LeagueStats.LeagueID IS NULL`,
+		)
+	}); err != nil {
+		return err
+	}
+
+	for k := range leagues {
+		if err := b.transaction(func(tx *sqlx.Tx) error {
+			_, err := b.rebuildLeagueStats(tx, leagues[k])
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Back) rebuildLeagueStats(tx *sqlx.Tx, league League) (LeagueStats, error) {
+	start := time.Now()
+	stats := LeagueStats{
+		LeagueID:  league.ID,
+		SeedStats: *seedstats.New(),
+	}
+
+	var err error
+	if stats.StatsMisc, err = computeMiscStats(tx, league); err != nil {
+		return LeagueStats{}, err
+	}
+
+	var sessions []MatchSession
+	if err := tx.Select(
+		&sessions,
+		`SELECT * FROM MatchSession WHERE LeagueID = ? AND Status = ?`,
+		league.ID, MatchSessionStatusClosed,
+	); err != nil {
+		return LeagueStats{}, err
+	}
+	for k := range sessions {
+		stats.Attendance.Add(sessions[k])
+	}
+
+	times, err := getLeagueSeedTimes(tx, league.ID)
+	if err != nil {
+		return LeagueStats{}, err
+	}
+	for _, v := range times {
+		stats.SeedTimes.Add(time.Duration(v) * time.Second)
+	}
+
+	if stats.SettingsValues, err = getSettingsValueStats(tx, league.ID); err != nil {
+		return LeagueStats{}, err
+	}
+
+	if err := b.mapSpoilerLogs(tx, league.ID, func(r io.Reader) error {
+		addSeedStats(&stats.SeedStats, r)
+		return nil
+	}); err != nil {
+		return LeagueStats{}, err
+	}
+
+	if err := stats.upsert(tx); err != nil {
+		return LeagueStats{}, err
+	}
+
+	log.Printf(
+		"info: rebuilt stats of league %s from %d seeds in %s",
+		league.ShortCode, stats.SeedStats.Seeds, time.Since(start),
+	)
+
+	return stats, nil
+}
+
+// addSessionToLeagueStats adds a newly closed session and its matches to the
+// stats of its league. It must run in the transaction closing the session so
+// each session is counted exactly once. Leagues without stats are built from
+// scratch, the closed session included.
+func (b *Back) addSessionToLeagueStats(tx *sqlx.Tx, session MatchSession, matches []Match) error {
+	stats, err := getLeagueStats(tx, session.LeagueID)
+	if errors.Is(err, sql.ErrNoRows) {
+		league, err := getLeagueByID(tx, session.LeagueID)
+		if err != nil {
+			return err
+		}
+
+		_, err = b.rebuildLeagueStats(tx, league)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	stats.Attendance.Add(session)
+	for k := range matches {
+		match := matches[k]
+		for _, v := range match.Entries {
+			if v.Status == MatchEntryStatusFinished {
+				stats.SeedTimes.Add(v.EndedAt.Time.Time().Sub(v.StartedAt.Time.Time()))
+			}
+		}
+
+		if !match.HasEnded() {
+			continue
+		}
+
+		if err := b.loadMatchBlobs(&match); err != nil {
+			return err
+		}
+		addSeedStats(&stats.SeedStats, match.SpoilerLog.Uncompressed())
+	}
+
+	if stats.SettingsValues, err = getSettingsValueStats(tx, session.LeagueID); err != nil {
+		return err
+	}
+
+	return stats.upsert(tx)
+}
+
+// refreshLeagueMiscStats recomputes the misc stats of a league if it has
+// stats, it is idempotent and must run after the rankings were updated.
+func refreshLeagueMiscStats(tx *sqlx.Tx, leagueID util.UUIDAsBlob) error {
+	stats, err := getLeagueStats(tx, leagueID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	league, err := getLeagueByID(tx, leagueID)
+	if err != nil {
+		return err
+	}
+	if stats.StatsMisc, err = computeMiscStats(tx, league); err != nil {
+		return err
+	}
+
+	return stats.upsert(tx)
+}
+
+// addSeedStats adds a spoiler log to the seed stats, unreadable logs are
+// skipped so they can't prevent sessions from closing.
+func addSeedStats(stats *seedstats.Stats, r io.Reader) {
+	var l oot.SpoilerLog
+	if err := json.NewDecoder(r).Decode(&l); err != nil {
+		log.Printf("warning: skipping unreadable spoiler log in seed stats: %s", err)
+		return
+	}
+
+	stats.Add(l)
+}
@@ -0,0 +1,199 @@
+package back // nolint:testpackage
+
+import (
+	"kaepora/internal/util"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestLeagueStats(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	// insertEndedSession creates a session past its start date whose matches
+	// have all ended so the next closeMatchSessionsAndUpdateRanks closes it.
+	insertEndedSession := func(shortcode string, logs ...string) {
+		if err := back.transaction(func(tx *sqlx.Tx) error {
+			league, err := getLeagueByShortCode(tx, shortcode)
+			if err != nil {
+				return err
+			}
+			p1, err := getPlayerByName(tx, "Zelda")
+			if err != nil {
+				return err
+			}
+			p2, err := getPlayerByName(tx, "Impa")
+			if err != nil {
+				return err
+			}
+
+			start := time.Now().Add(-3 * time.Hour)
+			session := NewMatchSession(league.ID, start)
+			session.Status = MatchSessionStatusInProgress
+			session.PlayerIDs = util.UUIDArrayAsJSON{p1.ID.UUID(), p2.ID.UUID()}
+			if err := session.insert(tx); err != nil {
+				return err
+			}
+
+			for _, v := range logs {
+				match, err := NewMatch(tx, session, "1")
+				if err != nil {
+					return err
+				}
+				match.EndedAt = util.NewNullTimeAsTimestamp(time.Now())
+				if match.SpoilerLog, err = util.NewZLIBBlob([]byte(v)); err != nil {
+					return err
+				}
+				if err := match.insert(tx); err != nil {
+					return err
+				}
+
+				for k, status := range []MatchEntryStatus{MatchEntryStatusFinished, MatchEntryStatusForfeit} {
+					entry := NewMatchEntry(match.ID, []util.UUIDAsBlob{p1.ID, p2.ID}[k])
+					entry.Status = status
+					entry.StartedAt = util.NewNullTimeAsTimestamp(start)
+					entry.EndedAt = util.NewNullTimeAsTimestamp(start.Add(2*time.Hour + 10*time.Minute))
+					if err := entry.insert(tx); err != nil {
+						return err
+					}
+				}
+			}
+
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	closeSessions := func() {
+		if err := back.closeMatchSessionsAndUpdateRanks(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	insertEndedSession("testa",
+		`{"locations": {"Song from Impa": "Progressive Hookshot"}}`,
+		`{"locations": {"Song from Impa": "Bow"}}`,
+	)
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`UPDATE MatchSession SET Status = ?`, MatchSessionStatusClosed)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading never builds the stats.
+	stats, err := back.GetSeedStats("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Seeds != 0 {
+		t.Fatalf("expected no seeds before the stats are built, got %d", stats.Seeds)
+	}
+
+	// Closing a session of a league without stats builds them, the closed
+	// session included.
+	insertEndedSession("testa", `{"locations": {"Sheik in Crater": "Bow"}}`)
+	closeSessions()
+	if stats, _ := back.GetSeedStats("testa"); stats.Seeds != 3 {
+		t.Errorf("expected 3 seeds after the first close, got %d", stats.Seeds)
+	}
+
+	// Next closes add to the stats exactly once, unreadable logs are skipped.
+	insertEndedSession("testa", `{"locations": {"Sheik in Crater": "Bow"}}`, `not JSON`)
+	closeSessions()
+	closeSessions()
+
+	var incremental LeagueStats
+	if err := back.transaction(func(tx *sqlx.Tx) (err error) {
+		league, err := getLeagueByShortCode(tx, "testa")
+		if err != nil {
+			return err
+		}
+		incremental, err = getLeagueStats(tx, league.ID)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if incremental.SeedStats.Seeds != 4 {
+		t.Errorf("expected 4 seeds after update, got %d", incremental.SeedStats.Seeds)
+	}
+	// Misc stats are only recomputed by the refresh following the rankings.
+	if incremental.SeedsPlayed != 5 || incremental.AveragePlayersPerRace != 2 || incremental.MostPlayersInARace != 2 {
+		t.Errorf("unexpected misc stats after update: %+v", incremental.StatsMisc)
+	}
+	if expected := (SeedTimes{0, 5}); incremental.SeedTimes != expected {
+		t.Errorf("unexpected seed times %v", incremental.SeedTimes)
+	}
+	var sessions int
+	for _, slot := range incremental.Attendance.Sessions {
+		for _, v := range slot {
+			sessions += v
+		}
+	}
+	if sessions != 3 {
+		t.Errorf("expected 3 sessions in the attendance, got %d", sessions)
+	}
+
+	if err := back.RebuildLeagueStats("testa"); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := back.getStoredLeagueStats("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuilt.UpdatedAt = incremental.UpdatedAt
+	if !reflect.DeepEqual(incremental, rebuilt) {
+		t.Errorf("rebuilt stats differ\nupdated: %+v\nrebuilt: %+v", incremental, rebuilt)
+	}
+
+	// Other leagues are left alone.
+	if stats, _ := back.GetSeedStats("testb"); stats.Seeds != 0 {
+		t.Errorf("expected no seeds in testb, got %d", stats.Seeds)
+	}
+}
+
+func TestRebuildMissingLeagueStats(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	getSeedsPlayed := func(shortcode string) (ret int) {
+		if err := back.transaction(func(tx *sqlx.Tx) error {
+			league, err := getLeagueByShortCode(tx, shortcode)
+			if err != nil {
+				return err
+			}
+			stats, err := getLeagueStats(tx, league.ID)
+			ret = stats.SeedsPlayed
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		return ret
+	}
+
+	if err := back.rebuildMissingLeagueStats(); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"testa", "testb"} {
+		if seeds := getSeedsPlayed(v); seeds != 0 {
+			t.Errorf("expected no seeds played in %s, got %d", v, seeds)
+		}
+	}
+
+	// Existing stats are kept as is.
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`UPDATE LeagueStats SET SeedsPlayed = 42`)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := back.rebuildMissingLeagueStats(); err != nil {
+		t.Fatal(err)
+	}
+	if seeds := getSeedsPlayed("testa"); seeds != 42 {
+		t.Errorf("expected existing stats to be kept, got %d seeds played", seeds)
+	}
+}
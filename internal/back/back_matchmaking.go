@@ -6,10 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"kaepora/internal/generator"
 	"kaepora/internal/util"
 	"log"
 	"math/big"
-	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -64,61 +64,33 @@ func (b *Back) generateAndSendSeeds(tx *sqlx.Tx, session MatchSession) error {
 	return nil
 }
 
-// doParallelSeedGeneration generates the seeds for the given matches of the
-// given MatchSession in parallel using one worker per available CPU core.
+// doParallelSeedGeneration queues the seeds generation for the given
+// matches of the given MatchSession and waits for them to be sent.
 // players must be a prefetched Player.ID->Player map for the given matches.
 func (b *Back) doParallelSeedGeneration(
 	session MatchSession,
 	matches []Match,
 	players map[util.UUIDAsBlob]Player,
 ) {
-	type pl struct {
-		match   Match
-		session MatchSession
-		p1, p2  Player
-	}
-
 	start := time.Now()
-	worker := func(ch <-chan pl, wg *sync.WaitGroup) {
-		defer wg.Done()
-		for v := range ch {
-			curSeedStart := time.Now()
-			log.Printf("debug: generating seed %s for match %s", v.match.Seed, v.match.ID)
-			if err := b.generateAndSendMatchSeed(v.match, v.session, v.p1, v.p2); err != nil {
-				log.Printf("unable to generate and send seed: %s", err)
-			}
-			log.Printf("info: generated seed %s in %s (%s)", v.match.Seed, time.Since(curSeedStart), time.Since(start))
-		}
-	}
-
-	cpus := runtime.NumCPU()
-	// HACK, arbitrary rate limit for external services, let the API client do
-	// the rate-limiting.
-	if gen, err := b.generatorFactory.NewGenerator(matches[0].Generator); err == nil {
-		if gen.IsExternal() {
-			cpus = 10
-			log.Printf("debug: external seedgen, setting limit to %d", cpus)
-		}
-	}
-
-	pool := make(chan pl, cpus)
-	log.Printf("debug: limiting seedgen to %d at a time", cpus)
-
 	var wg sync.WaitGroup
-	for i := 0; i < cpus; i++ {
-		wg.Add(1)
-		go worker(pool, &wg)
-	}
 
 	for k := range matches {
-		pool <- pl{
-			match:   matches[k],
-			session: session,
-			p1:      players[matches[k].Entries[0].PlayerID],
-			p2:      players[matches[k].Entries[1].PlayerID],
-		}
+		match := matches[k]
+		p1 := players[match.Entries[0].PlayerID]
+		p2 := players[match.Entries[1].PlayerID]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			curSeedStart := time.Now()
+			log.Printf("debug: generating seed %s for match %s", match.Seed, match.ID)
+			if err := b.generateAndSendMatchSeed(match, session, p1, p2); err != nil {
+				log.Printf("unable to generate and send seed: %s", err)
+			}
+			log.Printf("info: generated seed %s in %s (%s)", match.Seed, time.Since(curSeedStart), time.Since(start))
+		}()
 	}
-	close(pool)
 
 	wg.Wait()
 	log.Printf("info: generated %d seeds in %s", len(matches), time.Since(start))
@@ -131,12 +103,13 @@ func (b *Back) generateAndSendMatchSeed(
 	session MatchSession,
 	p1, p2 Player,
 ) error {
-	gen, err := b.generatorFactory.NewGenerator(match.Generator)
+	out, pooled, err := b.generateOrTakePooledSeed(&match, p1, p2)
 	if err != nil {
 		return err
 	}
 
-	out, err := gen.Generate(match.Settings, match.Seed)
+	// Generator is fetched after generation as a fallback may have been used.
+	gen, err := b.generatorFactory.NewGenerator(match.Generator)
 	if err != nil {
 		return err
 	}
@@ -148,18 +121,70 @@ func (b *Back) generateAndSendMatchSeed(
 
 	match.SeedPatch = out.SeedPatch
 	match.GeneratorState = out.State
-
-	if err := b.transaction(match.update); err != nil {
+	match.Difficulty = seedDifficulty(out.SpoilerLog)
+	if err := b.storeMatchBlobs(&match); err != nil {
 		return err
 	}
 
-	b.sendMatchSeedNotification(
-		session,
-		gen.GetDownloadURL(out.State), out,
-		p1, p2,
+	return b.transaction(func(tx *sqlx.Tx) error {
+		if err := match.update(tx); err != nil {
+			return err
+		}
+
+		if pooled.Valid {
+			if err := deletePooledSeed(tx, pooled.UUID); err != nil {
+				return err
+			}
+		}
+
+		return b.sendMatchSeedNotification(
+			tx, session,
+			gen.GetDownloadURL(out.State), out,
+			p1, p2,
+		)
+	})
+}
+
+// generateOrTakePooledSeed returns the output of the PooledSeed assigned to
+// the match during matchmaking if there is one, or generates the seed using
+// the League generator chain.
+// The returned ID is the one of the PooledSeed that was used, if any.
+func (b *Back) generateOrTakePooledSeed(match *Match, p1, p2 Player) (
+	generator.Output, util.NullUUIDAsBlob, error,
+) {
+	var (
+		pooled PooledSeed
+		found  bool
 	)
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		pooled, err = getPooledSeedByMatchID(tx, match.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		found = true
+		return nil
+	}); err != nil {
+		return generator.Output{}, util.NullUUIDAsBlob{}, err
+	}
 
-	return nil
+	if !found {
+		out, err := b.generateWithFallback(match, p1, p2)
+		return out, util.NullUUIDAsBlob{}, err
+	}
+
+	log.Printf("debug: using pooled seed %s for match %s", pooled.ID, match.ID)
+	if err := b.loadPooledSeedBlobs(&pooled); err != nil {
+		return generator.Output{}, util.NullUUIDAsBlob{}, err
+	}
+	out, err := pooled.Output()
+	if err != nil {
+		return generator.Output{}, util.NullUUIDAsBlob{}, err
+	}
+
+	return out, util.NullUUIDAsBlob{UUID: pooled.ID, Valid: true}, nil
 }
 
 // hashFromSpoilerLog extracts the "seed hash" from a OoT-Randomizer spoiler log.
@@ -212,6 +237,17 @@ func (b *Back) matchMakeSession(tx *sqlx.Tx, session MatchSession) error {
 		if err != nil {
 			return err
 		}
+
+		pooled, ok, err := b.takePooledSeed(tx, match)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := pooled.assignToMatch(tx, &match); err != nil {
+				return err
+			}
+		}
+
 		if err := match.insert(tx); err != nil {
 			return err
 		}
@@ -229,6 +265,19 @@ func (b *Back) matchMakeSession(tx *sqlx.Tx, session MatchSession) error {
 	return nil
 }
 
+// takePooledSeed returns a PooledSeed for the Match if one was generated
+// using the current content of the Match settings.
+func (b *Back) takePooledSeed(tx *sqlx.Tx, match Match) (PooledSeed, bool, error) {
+	settingsHash, err := b.settingsHash(match.Generator, match.Settings)
+	if err != nil {
+		// Not worth failing the matchmaking, the seed will be generated.
+		log.Printf("warning: unable to hash settings %s: %s", match.Settings, err)
+		return PooledSeed{}, false, nil
+	}
+
+	return takePooledSeed(tx, match, settingsHash)
+}
+
 func clamp(v, min, max int) int {
 	if v > max {
 		return max
@@ -280,7 +329,9 @@ func (b *Back) ensureSessionIsValidForMatchMaking(tx *sqlx.Tx, session MatchSess
 		}
 		log.Printf("info: removed odd player %s (%s) from session %s", player.ID, player.Name, session.ID.UUID())
 
-		b.sendOddKickNotification(player)
+		if err := b.sendOddKickNotification(tx, player); err != nil {
+			return MatchSession{}, false, err
+		}
 	}
 
 	if err := session.update(tx); err != nil {
@@ -8,6 +8,7 @@ import (
 	"kaepora/internal/util"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -39,22 +40,6 @@ func TestMatchMaking(t *testing.T) {
 
 // nolint:funlen
 func innerTestMatchMaking(t *testing.T, back *Back) {
-	notifs := make(map[NotificationType]int)
-
-	// Consume notifications to avoid filling and blocking the chan
-	notifsDone := make(chan struct{})
-	go func(c <-chan Notification, done chan struct{}) {
-		for {
-			select {
-			case notif := <-c:
-				log.Printf("test: got notification: %s", notif.String())
-				notifs[notif.Type]++
-			case <-done:
-				return
-			}
-		}
-	}(back.GetNotificationsChan(), notifsDone)
-
 	session, err := createSessionAndJoin(back)
 	if err != nil {
 		t.Fatal(err)
@@ -113,7 +98,11 @@ func innerTestMatchMaking(t *testing.T, back *Back) {
 		NotificationTypeMatchEnd:   6 + 3, // 1 per joined player, plus one for those who finish first
 		NotificationTypeSpoilerLog: 6,     // 1 per joined player
 	}
-	close(notifsDone)
+	notifs := make(map[NotificationType]int)
+	for _, notif := range dispatchAllNotifications(t, back) {
+		log.Printf("test: got notification: %s", notif.String())
+		notifs[notif.Type]++
+	}
 	if !reflect.DeepEqual(expected, notifs) {
 		t.Errorf("notifications count does not match\nexpected: %#v\nactual  : %#v", expected, notifs)
 	}
@@ -399,6 +388,16 @@ func createJoinableSession(back *Back) (MatchSession, League, error) {
 	return session, league, nil
 }
 
+// testMigrationsDir is resolved before any test changes the working
+// directory.
+var testMigrationsDir = func() string {
+	dir, err := filepath.Abs("../../resources/migrations")
+	if err != nil {
+		panic(err)
+	}
+	return dir
+}()
+
 func createFixturedTestBack(t *testing.T) *Back {
 	f, err := ioutil.TempFile("", "*.db")
 	if err != nil {
@@ -411,7 +410,7 @@ func createFixturedTestBack(t *testing.T) *Back {
 	})
 
 	migrator, err := migrate.New(
-		"file://../../resources/migrations",
+		"file://"+testMigrationsDir,
 		"sqlite3://"+path,
 	)
 	if err != nil {
@@ -46,6 +46,9 @@ func (b *Back) runPeriodicTasks() error {
 		return err
 	}
 
+	b.maybeRefillSeedPools()
+	b.maybeCollectBlobs()
+
 	return nil
 }
 
@@ -73,7 +76,13 @@ func (b *Back) createNextScheduledMatchSessions() error {
 				return err
 			}
 
+			index, err := countMatchSessionsBefore(tx, league.ID, next)
+			if err != nil {
+				return err
+			}
+
 			sess := NewMatchSession(league.ID, next)
+			sess.Settings = league.Rotation().Pick(next, index)
 			if err := sess.insert(tx); err != nil {
 				return err
 			}
@@ -147,6 +156,15 @@ func (b *Back) makeMatchSessionsPreparing() ([]MatchSession, error) {
 				continue
 			}
 
+			league, err := getLeagueByID(tx, sessions[k].LeagueID)
+			if err != nil {
+				return err
+			}
+			// Voting closes now, matchmaking copies the session settings.
+			if err := tallySettingsVote(tx, &sessions[k], league); err != nil {
+				return err
+			}
+
 			log.Printf("debug: put session %s in MatchSessionStatusPreparing", sessions[k].ID)
 			sessions[k].Status = MatchSessionStatusPreparing
 			if err := sessions[k].update(tx); err != nil {
@@ -354,6 +372,12 @@ func (b *Back) closeMatchSessionsAndUpdateRanks() error {
 			if err := sessions[k].update(tx); err != nil {
 				return err
 			}
+
+			// Stats are updated along the closing so no session is counted
+			// twice or missed.
+			if err := b.addSessionToLeagueStats(tx, sessions[k], matches[sessions[k].ID]); err != nil {
+				return err
+			}
 		}
 		return nil
 	}); err != nil {
@@ -366,6 +390,10 @@ func (b *Back) closeMatchSessionsAndUpdateRanks() error {
 			if err := b.updateLeagueRankings(tx, sessions[k].LeagueID, now); err != nil {
 				return err
 			}
+
+			if err := refreshLeagueMiscStats(tx, sessions[k].LeagueID); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -373,6 +401,10 @@ func (b *Back) closeMatchSessionsAndUpdateRanks() error {
 		return err
 	}
 
+	for k := range sessions {
+		b.indexClosedMatches(matches[sessions[k].ID])
+	}
+
 	// In a separate transaction to avoid delaying ranking updates and working with stale data.
 	return b.transaction(func(tx *sqlx.Tx) error {
 		for k := range sessions {
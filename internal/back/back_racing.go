@@ -140,7 +140,7 @@ func (b *Back) endActiveMatch(player Player, forfeit bool) (Match, error) {
 			self.update(tx),
 			against.update(tx),
 			match.update(tx),
-			b.maybeSendMatchEndNotifications(tx, player, self, against, against.PlayerID),
+			b.maybeSendMatchEndNotifications(tx, match, player, self, against, against.PlayerID),
 		}); err != nil {
 			return err
 		}
@@ -154,7 +154,11 @@ func (b *Back) endActiveMatch(player Player, forfeit bool) (Match, error) {
 	if err := b.sendPrivateRecapForSessionID(ret.MatchSessionID, player); err != nil {
 		return Match{}, err
 	}
-	b.sendSpoilerLogNotification(player, ret.ID)
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		return b.sendSpoilerLogNotification(tx, player, ret.ID)
+	}); err != nil {
+		return Match{}, err
+	}
 
 	if ret.HasEnded() {
 		go func() {
@@ -221,6 +225,7 @@ func (b *Back) CancelActiveMatchSession(playerID util.UUIDAsBlob) (MatchSession,
 
 func (b *Back) maybeSendMatchEndNotifications(
 	tx *sqlx.Tx,
+	match Match,
 	player Player,
 	selfEntry MatchEntry, againstEntry MatchEntry,
 	opponentID util.UUIDAsBlob,
@@ -231,16 +236,26 @@ func (b *Back) maybeSendMatchEndNotifications(
 		}
 	}
 
-	if againstEntry.HasEnded() {
-		opponent, err := getPlayerByID(tx, opponentID)
-		if err != nil {
-			return err
-		}
+	if !againstEntry.HasEnded() {
+		return nil
+	}
 
-		if err := b.sendMatchEndNotification(tx, againstEntry, selfEntry, opponent); err != nil {
-			return err
-		}
+	opponent, err := getPlayerByID(tx, opponentID)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	if err := b.sendMatchEndNotification(tx, againstEntry, selfEntry, opponent); err != nil {
+		return err
+	}
+
+	// Results are only public once both entries have ended.
+	if !match.HasEnded() {
+		return nil
+	}
+
+	return queueWebhookEvent(tx, NotificationTypeMatchEnd, &MatchEndPayload{
+		Self:     newNotificationEntry(selfEntry, player.Name),
+		Opponent: newNotificationEntry(againstEntry, opponent.Name),
+	})
 }
@@ -0,0 +1,149 @@
+package back
+
+import (
+	"fmt"
+	"kaepora/internal/generator"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// maybeRefillSeedPools starts refilling the seed pools of all leagues in the
+// background unless a refill is already running or the pool is disabled.
+func (b *Back) maybeRefillSeedPools() {
+	if b.config.SeedPoolSize <= 0 {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&b.refillingSeedPools, 0, 1) {
+		log.Printf("debug: seed pool refill already running")
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&b.refillingSeedPools, 0)
+		if err := b.refillSeedPools(); err != nil {
+			log.Printf("error: unable to refill seed pools: %s", err)
+		}
+	}()
+}
+
+// refillSeedPools generates seeds for each league until their pool contains
+// SeedPoolSize valid seeds. Seeds are generated one at a time to leave the
+// generator resources to the seeds needed right now by matchmaking.
+func (b *Back) refillSeedPools() error {
+	var leagues []League
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		leagues, err = getLeagues(tx)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	for k := range leagues {
+		if err := b.refillSeedPool(leagues[k]); err != nil {
+			log.Printf("error: unable to refill seed pool for %s: %s", leagues[k].ShortCode, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Back) refillSeedPool(league League) error {
+	var settings string
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		settings, err = nextSessionSettings(tx, league)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	settingsHash, err := b.settingsHash(league.Generator, settings)
+	if err != nil {
+		return err
+	}
+
+	var missing int
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		if err := invalidateSeedPool(tx, league); err != nil {
+			return err
+		}
+		if err := invalidateSeedPoolContent(tx, league, settingsHash); err != nil {
+			return err
+		}
+
+		available, err := countAvailablePooledSeeds(tx, league, settings, settingsHash)
+		missing = b.config.SeedPoolSize - available
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if missing <= 0 {
+		return nil
+	}
+
+	gen, err := b.generatorFactory.NewGenerator(league.Generator)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("info: generating %d seeds for the %s pool", missing, league.ShortCode)
+	for i := 0; i < missing; i++ {
+		start := time.Now()
+		// google/uuid.v4 are generated using a CSPRNG
+		seed := uuid.New().String()
+		var out generator.Output
+		b.seedgen.wait(
+			SeedgenPriorityPractice, league.Generator,
+			fmt.Sprintf("%s seed pool", league.ShortCode), "",
+			func() {
+				out, err = b.generate(gen, settings, seed)
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		pooled, err := NewPooledSeed(league, settings, settingsHash, seed, out)
+		if err != nil {
+			return err
+		}
+		if err := b.storePooledSeedBlobs(&pooled); err != nil {
+			return err
+		}
+
+		var stale bool
+		if err := b.transaction(func(tx *sqlx.Tx) error {
+			// The league or its next session may have changed while we were
+			// generating.
+			current, err := getLeagueByID(tx, league.ID)
+			if err != nil {
+				return err
+			}
+			currentSettings, err := nextSessionSettings(tx, current)
+			if err != nil {
+				return err
+			}
+			if current.Generator != pooled.Generator || currentSettings != pooled.Settings {
+				log.Printf("debug: discarding stale pooled seed for %s", league.ShortCode)
+				stale = true
+				return nil
+			}
+
+			return pooled.insert(tx)
+		}); err != nil {
+			return err
+		}
+		if stale {
+			return nil
+		}
+
+		log.Printf("debug: pooled seed %s for %s in %s", seed, league.ShortCode, time.Since(start))
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package back // nolint:testpackage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"kaepora/internal/back/rotation"
+	"kaepora/internal/back/schedule"
+	"kaepora/internal/blob"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSeedPool(t *testing.T) {
+	back := createFixturedTestBack(t)
+	back.config.SeedPoolSize = 2
+
+	league, err := back.GetLeagueByShortcode("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := back.refillSeedPool(league); err != nil {
+		t.Fatal(err)
+	}
+	if n := countPool(t, back, league); n != 2 {
+		t.Fatalf("expected 2 pooled seeds, got %d", n)
+	}
+
+	// Refilling a full pool is a no-op.
+	if err := back.refillSeedPool(league); err != nil {
+		t.Fatal(err)
+	}
+	if n := countPool(t, back, league); n != 2 {
+		t.Fatalf("expected 2 pooled seeds after refill, got %d", n)
+	}
+
+	// Matchmaking consumes the pool before generating seeds.
+	// Disable the background refill triggered by the periodic tasks.
+	back.config.SeedPoolSize = 0
+	innerTestMatchMaking(t, back)
+	back.config.SeedPoolSize = 2
+	if n := countPool(t, back, league); n != 0 {
+		t.Fatalf("expected the pool to be empty after matchmaking, got %d", n)
+	}
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		var left int
+		if err := tx.Get(&left, `SELECT COUNT(*) FROM PooledSeed`); err != nil {
+			return err
+		}
+		if left != 0 {
+			t.Errorf("expected assigned pooled seeds to be deleted once sent, %d left", left)
+		}
+
+		var missing int
+		if err := tx.Get(&missing, `SELECT COUNT(*) FROM Match WHERE LENGTH(SeedPatch) = 0`); err != nil {
+			return err
+		}
+		if missing != 0 {
+			t.Errorf("expected every Match to store its patch, %d are missing", missing)
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing the settings invalidates the pool.
+	if err := back.refillSeedPool(league); err != nil {
+		t.Fatal(err)
+	}
+	league.Settings = "s4.json"
+	if err := back.UpdateLeague(league); err != nil {
+		t.Fatal(err)
+	}
+	if n := countPool(t, back, league); n != 0 {
+		t.Fatalf("expected settings change to empty the pool, got %d", n)
+	}
+}
+
+// The pool is filled for the settings picked by the rotation of the next
+// session, not the League default ones.
+func TestSeedPoolRotation(t *testing.T) {
+	back := createFixturedTestBack(t)
+	back.config.SeedPoolSize = 2
+
+	league, err := back.GetLeagueByShortcode("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hours := schedule.NewDayOfWeekScheduler()
+	hours.SetAll([]string{"20:00 UTC"})
+	payload, err := json.Marshal(hours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	league.Schedule = schedule.Config{Type: schedule.TypeDayOfWeek, Payload: payload}
+	league.SettingsRotation = rotation.Config{
+		Type:    rotation.TypeRoundRobin,
+		Payload: []byte(`{"Settings": ["a.json", "b.json"]}`),
+	}
+	if err := back.UpdateLeague(league); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := back.refillSeedPool(league); err != nil {
+		t.Fatal(err)
+	}
+	if n := countPool(t, back, league); n != 2 {
+		t.Fatalf("expected 2 pooled seeds, got %d", n)
+	}
+
+	var settings []string
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		return tx.Select(&settings, `SELECT DISTINCT Settings FROM PooledSeed`)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(settings) != 1 || settings[0] != "a.json" {
+		t.Errorf("expected seeds for the rotation settings, got %v", settings)
+	}
+
+	// Creating the session does not change its settings, the pool is kept.
+	if err := back.createNextScheduledMatchSessions(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countPool(t, back, league); n != 2 {
+		t.Fatalf("expected the pool to be kept once the session is created, got %d", n)
+	}
+}
+
+func TestSeedPoolBlobStore(t *testing.T) {
+	back := createFixturedTestBack(t)
+	back.config.SeedPoolSize = 2
+
+	dir, err := ioutil.TempDir("", "kaepora-blobs-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	back.blobs, err = blob.NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	league, err := back.GetLeagueByShortcode("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := back.refillSeedPool(league); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		var inDB int
+		if err := tx.Get(&inDB, `
+            SELECT COUNT(*) FROM PooledSeed
+            WHERE LENGTH(SeedPatch) > 0 OR LENGTH(SpoilerLog) > 0 OR SeedPatchKey = ''`,
+		); err != nil {
+			return err
+		}
+		if inDB != 0 {
+			t.Errorf("expected pooled seed blobs to be in the blob store, %d are in the DB", inDB)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := back.VerifyBlobs(); err != nil {
+		t.Error(err)
+	}
+
+	back.config.SeedPoolSize = 0
+	innerTestMatchMaking(t, back)
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		var missing int
+		if err := tx.Get(&missing, `SELECT COUNT(*) FROM Match WHERE SeedPatchKey = ''`); err != nil {
+			return err
+		}
+		if missing != 0 {
+			t.Errorf("expected every Match to store its patch, %d are missing", missing)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSeedPoolSettingsContentChange(t *testing.T) {
+	back := createFixturedTestBack(t)
+	back.config.SeedPoolSize = 2
+
+	dir, err := ioutil.TempDir("", "kaepora-settings-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "s3.json")
+	if err := ioutil.WriteFile(path, []byte(`{"a": 1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	league, err := back.GetLeagueByShortcode("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	league.Settings = path
+	if err := back.UpdateLeague(league); err != nil {
+		t.Fatal(err)
+	}
+	if err := back.refillSeedPool(league); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"a": 2}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if n := countPool(t, back, league); n != 0 {
+		t.Fatalf("expected seeds generated with the old content to be unusable, got %d", n)
+	}
+
+	if err := back.refillSeedPool(league); err != nil {
+		t.Fatal(err)
+	}
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		var total int
+		if err := tx.Get(&total, `SELECT COUNT(*) FROM PooledSeed`); err != nil {
+			return err
+		}
+		if total != 2 {
+			t.Errorf("expected stale seeds to be replaced, got %d pooled seeds", total)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countPool(t *testing.T, back *Back, league League) (ret int) {
+	t.Helper()
+
+	var settings string
+	if err := back.transaction(func(tx *sqlx.Tx) (err error) {
+		settings, err = nextSessionSettings(tx, league)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	settingsHash, err := back.settingsHash(league.Generator, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		var err error
+		ret, err = countAvailablePooledSeeds(tx, league, settings, settingsHash)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return ret
+}
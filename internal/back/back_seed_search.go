@@ -0,0 +1,252 @@
+package back
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/util"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// seedSearchMaxHits caps the number of placements returned by SearchSeeds.
+const seedSearchMaxHits = 500
+
+// SeedSearchQuery selects item placements among the indexed seeds of a
+// league. Item and Location are case-insensitive substrings, at least one of
+// them is required. Zero values disable the other filters.
+type SeedSearchQuery struct {
+	Item, Location string
+	Generator      string
+	From, To       time.Time // on Match.StartedAt, To is exclusive
+}
+
+// SeedSearchHit is a single item placement matching a SeedSearchQuery.
+type SeedSearchHit struct {
+	MatchID   util.UUIDAsBlob
+	StartedAt util.NullTimeAsTimestamp
+	Generator string
+	Location  string
+	Item      string
+}
+
+// SeedSearchResult holds the placements matching a SeedSearchQuery.
+type SeedSearchResult struct {
+	Seeds        int // indexed seeds matching the filters
+	MatchedSeeds int // seeds with at least one matching placement
+	Hits         []SeedSearchHit
+	Truncated    bool // true if there were more than seedSearchMaxHits hits
+}
+
+// Frequency returns the percentage of seeds having a matching placement.
+func (r SeedSearchResult) Frequency() float64 {
+	if r.Seeds == 0 {
+		return 0
+	}
+
+	return 100.0 * float64(r.MatchedSeeds) / float64(r.Seeds)
+}
+
+// SearchSeeds looks for item placements in the indexed seeds of a league.
+func (b *Back) SearchSeeds(shortcode string, q SeedSearchQuery) (ret SeedSearchResult, _ error) {
+	if q.Item == "" && q.Location == "" {
+		return SeedSearchResult{}, util.ErrPublic("an item or a location is required")
+	}
+
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		league, err := getLeagueByShortCode(tx, shortcode)
+		if err != nil {
+			return err
+		}
+
+		ret, err = searchSeeds(tx, league.ID, q)
+		return err
+	}); err != nil {
+		return SeedSearchResult{}, err
+	}
+
+	return ret, nil
+}
+
+func searchSeeds(tx *sqlx.Tx, leagueID util.UUIDAsBlob, q SeedSearchQuery) (ret SeedSearchResult, _ error) {
+	scope := squirrel.And{
+		squirrel.Eq{"Match.LeagueID": leagueID},
+		squirrel.Expr("EXISTS (SELECT 1 FROM SeedLocation WHERE SeedLocation.MatchID = Match.ID)"),
+	}
+	if q.Generator != "" {
+		scope = append(scope, squirrel.Eq{"Match.Generator": q.Generator})
+	}
+	if !q.From.IsZero() {
+		scope = append(scope, squirrel.GtOrEq{"Match.StartedAt": util.TimeAsTimestamp(q.From)})
+	}
+	if !q.To.IsZero() {
+		scope = append(scope, squirrel.Lt{"Match.StartedAt": util.TimeAsTimestamp(q.To)})
+	}
+
+	query, args, err := squirrel.Select("COUNT(*)").From("Match").Where(scope).ToSql()
+	if err != nil {
+		return SeedSearchResult{}, err
+	}
+	if err := tx.Get(&ret.Seeds, query, args...); err != nil {
+		return SeedSearchResult{}, err
+	}
+
+	placement := append(squirrel.And{}, scope...)
+	if q.Item != "" {
+		placement = append(placement, squirrel.Expr("SeedLocation.Item LIKE ?", "%"+q.Item+"%"))
+	}
+	if q.Location != "" {
+		placement = append(placement, squirrel.Expr("SeedLocation.Location LIKE ?", "%"+q.Location+"%"))
+	}
+
+	query, args, err = squirrel.Select("COUNT(DISTINCT Match.ID)").
+		From("SeedLocation").
+		Join("Match ON (Match.ID = SeedLocation.MatchID)").
+		Where(placement).ToSql()
+	if err != nil {
+		return SeedSearchResult{}, err
+	}
+	if err := tx.Get(&ret.MatchedSeeds, query, args...); err != nil {
+		return SeedSearchResult{}, err
+	}
+
+	query, args, err = squirrel.Select(
+		"Match.ID AS MatchID", "Match.StartedAt", "Match.Generator",
+		"SeedLocation.Location", "SeedLocation.Item",
+	).
+		From("SeedLocation").
+		Join("Match ON (Match.ID = SeedLocation.MatchID)").
+		Where(placement).
+		OrderBy("Match.StartedAt DESC", "SeedLocation.Location").
+		Limit(seedSearchMaxHits + 1).ToSql()
+	if err != nil {
+		return SeedSearchResult{}, err
+	}
+	if err := tx.Select(&ret.Hits, query, args...); err != nil {
+		return SeedSearchResult{}, err
+	}
+
+	if len(ret.Hits) > seedSearchMaxHits {
+		ret.Hits = ret.Hits[:seedSearchMaxHits]
+		ret.Truncated = true
+	}
+
+	return ret, nil
+}
+
+// GetLeagueGenerators returns the generators used by the indexed seeds of a
+// league, to filter searches.
+func (b *Back) GetLeagueGenerators(shortcode string) (ret []string, _ error) {
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		league, err := getLeagueByShortCode(tx, shortcode)
+		if err != nil {
+			return err
+		}
+
+		return tx.Select(&ret, `
+            SELECT DISTINCT Generator FROM Match
+            WHERE LeagueID = ? AND EXISTS (
+                SELECT 1 FROM SeedLocation WHERE SeedLocation.MatchID = Match.ID
+            ) ORDER BY Generator`,
+			league.ID,
+		)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// indexSeedLocations stores the item placements of a match for searching,
+// matches without an OoT spoiler log are not indexed.
+func indexSeedLocations(tx *sqlx.Tx, match Match) error {
+	raw, err := ioutil.ReadAll(match.SpoilerLog.Uncompressed())
+	if err != nil {
+		return err
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var parsed oot.SpoilerLog
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return fmt.Errorf("unable to parse spoiler log of match %s: %w", match.ID, err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM SeedLocation WHERE MatchID = ?`, match.ID); err != nil {
+		return err
+	}
+
+	for location, item := range parsed.Locations {
+		if _, err := tx.Exec(
+			`INSERT INTO SeedLocation (MatchID, LeagueID, Location, Item) VALUES (?, ?, ?, ?)`,
+			match.ID, match.LeagueID, string(location), string(item),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexClosedMatches indexes the seeds of the given matches, errors are only
+// logged as the index can be rebuilt using IndexSeedLocations.
+func (b *Back) indexClosedMatches(matches []Match) {
+	for k := range matches {
+		match := matches[k]
+		if err := b.loadMatchBlobs(&match); err != nil {
+			log.Printf("warning: unable to index match %s: %s", match.ID, err)
+			continue
+		}
+
+		if err := b.transaction(func(tx *sqlx.Tx) error {
+			return indexSeedLocations(tx, match)
+		}); err != nil {
+			log.Printf("warning: unable to index match %s: %s", match.ID, err)
+		}
+	}
+}
+
+// IndexSeedLocations indexes the seeds of closed sessions that were not
+// indexed yet and returns the number of indexed matches. Matches that can't be
+// indexed are skipped with a warning.
+func (b *Back) IndexSeedLocations() (int, error) {
+	var ids []util.UUIDAsBlob
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		return tx.Select(&ids, `
+            SELECT Match.ID FROM Match
+            INNER JOIN MatchSession ON (Match.MatchSessionID = MatchSession.ID)
+            WHERE MatchSession.Status = ?
+                AND (length(Match.SpoilerLog) > 0 OR Match.SpoilerLogKey != '')
+                AND NOT EXISTS (SELECT 1 FROM SeedLocation WHERE SeedLocation.MatchID = Match.ID)`,
+			MatchSessionStatusClosed,
+		)
+	}); err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, id := range ids {
+		if err := b.transaction(func(tx *sqlx.Tx) error {
+			match, err := getMatchByID(tx, id)
+			if err != nil {
+				return err
+			}
+			if err := b.loadMatchBlobs(&match); err != nil {
+				return err
+			}
+
+			return indexSeedLocations(tx, match)
+		}); err != nil {
+			log.Printf("warning: unable to index match %s: %s", id, err)
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,90 @@
+package back // nolint:testpackage
+
+import (
+	"kaepora/internal/util"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSeedSearch(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	logs := []string{
+		`{"locations": {"Song from Impa": "Progressive Hookshot", "KF Midos Top Left Chest": "Bow"}}`,
+		`{"locations": {"Song from Impa": "Zeldas Lullaby", "KF Midos Top Left Chest": "Progressive Hookshot"}}`,
+		`{"locations": {"Sheik in Crater": "Progressive Hookshot"}}`,
+	}
+
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		league, err := getLeagueByShortCode(tx, "testa")
+		if err != nil {
+			return err
+		}
+
+		session := NewMatchSession(league.ID, time.Now())
+		session.Status = MatchSessionStatusClosed
+		if err := session.insert(tx); err != nil {
+			return err
+		}
+
+		for k, v := range logs {
+			match, err := NewMatch(tx, session, "1")
+			if err != nil {
+				return err
+			}
+			match.StartedAt = util.NewNullTimeAsTimestamp(time.Date(2020, 1, k+1, 20, 0, 0, 0, time.UTC))
+			if match.SpoilerLog, err = util.NewZLIBBlob([]byte(v)); err != nil {
+				return err
+			}
+			if err := match.insert(tx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := back.IndexSeedLocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(logs) {
+		t.Fatalf("expected %d indexed seeds, got %d", len(logs), count)
+	}
+	if count, _ := back.IndexSeedLocations(); count != 0 {
+		t.Errorf("expected indexed seeds to be skipped, got %d", count)
+	}
+
+	cases := []struct {
+		query           SeedSearchQuery
+		seeds, matching int
+	}{
+		{SeedSearchQuery{Item: "hookshot"}, 3, 3},
+		{SeedSearchQuery{Item: "Hookshot", Location: "Song from"}, 3, 1},
+		{SeedSearchQuery{Location: "Song from"}, 3, 2},
+		{SeedSearchQuery{Item: "Hookshot", From: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}, 2, 2},
+		{SeedSearchQuery{Item: "Hookshot", To: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}, 1, 1},
+		{SeedSearchQuery{Item: "Hookshot", Generator: "nope"}, 0, 0},
+	}
+
+	for k, c := range cases {
+		res, err := back.SearchSeeds("testa", c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Seeds != c.seeds || res.MatchedSeeds != c.matching {
+			t.Errorf("case %d: expected %d/%d seeds, got %d/%d", k, c.matching, c.seeds, res.MatchedSeeds, res.Seeds)
+		}
+		if len(res.Hits) < res.MatchedSeeds {
+			t.Errorf("case %d: expected at least one hit per seed", k)
+		}
+	}
+
+	if _, err := back.SearchSeeds("testa", SeedSearchQuery{}); err == nil {
+		t.Error("expected an empty query to be rejected")
+	}
+}
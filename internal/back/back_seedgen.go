@@ -0,0 +1,76 @@
+package back
+
+import (
+	"fmt"
+	"kaepora/internal/generator"
+	"kaepora/internal/util"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// generateWithFallback generates the seed of a Match by trying each generator
+// of the League chain in order, each generator is retried with an exponential
+// backoff before falling back to the next one.
+// Each attempt is queued separately using the priority and concurrency limit
+// of its own generator, no worker slot is held while waiting between attempts.
+// On success match.Generator is set to the generator that was actually used.
+// Players and admins are notified if a fallback was used or if every attempt
+// failed.
+func (b *Back) generateWithFallback(match *Match, p1, p2 Player) (generator.Output, error) {
+	var league League
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		league, err = getLeagueByID(tx, match.LeagueID)
+		return err
+	}); err != nil {
+		return generator.Output{}, err
+	}
+
+	chain := append([]string{match.Generator}, league.FallbackGeneratorsList()...)
+	attempts := b.config.Seedgen.GetAttempts()
+	label := fmt.Sprintf("match %s (%s vs. %s)", match.ID, p1.Name, p2.Name)
+
+	var errs []error
+	for _, name := range chain {
+		gen, err := b.generatorFactory.NewGenerator(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		delay := b.config.Seedgen.GetRetryDelay()
+		for i := 1; i <= attempts; i++ {
+			var out generator.Output
+			b.seedgen.wait(SeedgenPriorityRanked, name, label, "", func() {
+				out, err = b.generate(gen, match.Settings, match.Seed)
+			})
+			if err == nil {
+				if name != match.Generator {
+					b.sendSeedgenFallbackNotification(*match, name, p1, p2)
+					match.Generator = name
+				}
+
+				return out, nil
+			}
+
+			log.Printf(
+				"warning: attempt %d/%d to generate seed %s with %s failed: %s",
+				i, attempts, match.Seed, name, err,
+			)
+			errs = append(errs, fmt.Errorf("%s (attempt %d): %w", name, i, err))
+
+			if i < attempts {
+				b.sleep(delay)
+				delay *= 2
+			}
+		}
+	}
+
+	err := fmt.Errorf(
+		"all seed generation attempts failed for match %s: %w",
+		match.ID, util.ConcatErrors(errs),
+	)
+	b.sendSeedgenFailureNotification(*match, p1, p2, err)
+
+	return generator.Output{}, err
+}
@@ -0,0 +1,185 @@
+package back // nolint:testpackage
+
+import (
+	"errors"
+	"kaepora/internal/generator"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// flakyGeneratorFactory creates generators that fail a given number of times
+// before succeeding, a negative count fails forever.
+type flakyGeneratorFactory struct {
+	mu       sync.Mutex
+	failures map[string]int
+	calls    []string // generator ID of each Generate call, in order
+}
+
+func (f *flakyGeneratorFactory) NewGenerator(id string) (generator.Generator, error) {
+	return flakyGenerator{Test: generator.NewTest(), factory: f, id: id}, nil
+}
+
+type flakyGenerator struct {
+	*generator.Test
+	factory *flakyGeneratorFactory
+	id      string
+}
+
+func (g flakyGenerator) Generate(settings, seed string) (generator.Output, error) {
+	g.factory.mu.Lock()
+	g.factory.calls = append(g.factory.calls, g.id)
+	failures := g.factory.failures[g.id]
+	if failures > 0 {
+		g.factory.failures[g.id]--
+	}
+	g.factory.mu.Unlock()
+
+	if failures != 0 {
+		return generator.Output{}, errors.New("flaky generator failure")
+	}
+
+	return g.Test.Generate(settings, seed)
+}
+
+func createSeedgenTestMatch(t *testing.T, back *Back, fallbacks string) (Match, Player, Player) {
+	t.Helper()
+
+	var (
+		match  Match
+		p1, p2 Player
+	)
+	if err := back.transaction(func(tx *sqlx.Tx) (err error) {
+		league, err := getLeagueByShortCode(tx, "testa")
+		if err != nil {
+			return err
+		}
+		league.Generator = "main:v1"
+		league.FallbackGenerators = fallbacks
+		if err := league.update(tx); err != nil {
+			return err
+		}
+
+		if p1, err = getPlayerByName(tx, "Zelda"); err != nil {
+			return err
+		}
+		if p2, err = getPlayerByName(tx, "Impa"); err != nil {
+			return err
+		}
+
+		session := NewMatchSession(league.ID, time.Now())
+		if err := session.insert(tx); err != nil {
+			return err
+		}
+		match, err = NewMatch(tx, session, "seed")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return match, p1, p2
+}
+
+func setupFlakySeedgen(back *Back, failures map[string]int) (*flakyGeneratorFactory, *[]time.Duration) {
+	factory := &flakyGeneratorFactory{failures: failures}
+	back.generatorFactory = factory
+	back.config.Seedgen.Attempts = 3
+	back.config.Seedgen.RetryDelay = 1
+
+	var sleeps []time.Duration
+	back.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	return factory, &sleeps
+}
+
+func TestGenerateWithFallbackRetry(t *testing.T) {
+	back := createFixturedTestBack(t)
+	factory, sleeps := setupFlakySeedgen(back, map[string]int{"main:v1": 2})
+	match, p1, p2 := createSeedgenTestMatch(t, back, "fallback:v1")
+
+	if _, err := back.generateWithFallback(&match, p1, p2); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"main:v1", "main:v1", "main:v1"}; !reflect.DeepEqual(expected, factory.calls) {
+		t.Errorf("unexpected attempts\nexpected: %v\nactual  : %v", expected, factory.calls)
+	}
+	if expected := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(expected, *sleeps) {
+		t.Errorf("unexpected backoff\nexpected: %v\nactual  : %v", expected, *sleeps)
+	}
+	if match.Generator != "main:v1" {
+		t.Errorf("expected the main generator to be kept, got %s", match.Generator)
+	}
+	if notifs := dispatchAllNotifications(t, back); len(notifs) != 0 {
+		t.Errorf("expected no notifications, got %d", len(notifs))
+	}
+}
+
+func TestGenerateWithFallbackOrder(t *testing.T) {
+	back := createFixturedTestBack(t)
+	factory, _ := setupFlakySeedgen(back, map[string]int{"main:v1": -1, "first:v1": -1})
+	match, p1, p2 := createSeedgenTestMatch(t, back, "first:v1,second:v1")
+
+	if _, err := back.generateWithFallback(&match, p1, p2); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"main:v1", "main:v1", "main:v1",
+		"first:v1", "first:v1", "first:v1",
+		"second:v1",
+	}
+	if !reflect.DeepEqual(expected, factory.calls) {
+		t.Errorf("unexpected attempts\nexpected: %v\nactual  : %v", expected, factory.calls)
+	}
+	if match.Generator != "second:v1" {
+		t.Errorf("expected the match generator to be the used fallback, got %s", match.Generator)
+	}
+
+	notifs := dispatchAllNotifications(t, back)
+	if len(notifs) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notifs))
+	}
+	for _, v := range notifs {
+		if v.Type != NotificationTypeMatchSeedFallback {
+			t.Errorf("expected a fallback notification, got %v", v.Type)
+		}
+	}
+}
+
+func TestGenerateWithFallbackFailure(t *testing.T) {
+	back := createFixturedTestBack(t)
+	factory, sleeps := setupFlakySeedgen(back, map[string]int{"main:v1": -1, "fallback:v1": -1})
+	match, p1, p2 := createSeedgenTestMatch(t, back, "fallback:v1")
+
+	if _, err := back.generateWithFallback(&match, p1, p2); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	expected := []string{
+		"main:v1", "main:v1", "main:v1",
+		"fallback:v1", "fallback:v1", "fallback:v1",
+	}
+	if !reflect.DeepEqual(expected, factory.calls) {
+		t.Errorf("unexpected attempts\nexpected: %v\nactual  : %v", expected, factory.calls)
+	}
+	if len(*sleeps) != 4 {
+		t.Errorf("expected 4 backoffs, got %d", len(*sleeps))
+	}
+	if match.Generator != "main:v1" {
+		t.Errorf("expected the match generator to be left untouched, got %s", match.Generator)
+	}
+
+	notifs := dispatchAllNotifications(t, back)
+	if len(notifs) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notifs))
+	}
+	for _, v := range notifs {
+		if v.Type != NotificationTypeMatchSeedFailure {
+			t.Errorf("expected a failure notification, got %v", v.Type)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package back
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"kaepora/internal/generator"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/util"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// GetSettingsPresets returns all versions of all presets.
+func (b *Back) GetSettingsPresets() (ret []SettingsPreset, _ error) {
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		ret, err = getSettingsPresets(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetSettingsPreset returns a single preset version and all the versions
+// sharing its name.
+func (b *Back) GetSettingsPreset(id util.UUIDAsBlob) (
+	preset SettingsPreset, versions []SettingsPreset, _ error,
+) {
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		preset, err = getSettingsPresetByID(tx, id)
+		if err != nil {
+			return err
+		}
+
+		versions, err = getSettingsPresetVersions(tx, preset.Name)
+		return err
+	}); err != nil {
+		return SettingsPreset{}, nil, err
+	}
+
+	return preset, versions, nil
+}
+
+// CreateSettingsPresetVersion stores a new version of a preset, creating the
+// preset if it does not exist yet.
+func (b *Back) CreateSettingsPresetVersion(name, content, changelog string) (
+	preset SettingsPreset, _ error,
+) {
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		preset, err = newSettingsPresetVersion(tx, name, content, changelog)
+		return err
+	}); err != nil {
+		return SettingsPreset{}, err
+	}
+
+	return preset, nil
+}
+
+// RollLeagueOntoSettingsPreset makes a league use the given preset version in
+// place of the version it currently uses, only upcoming matches are affected.
+func (b *Back) RollLeagueOntoSettingsPreset(leagueID, presetID util.UUIDAsBlob) error {
+	return b.transaction(func(tx *sqlx.Tx) error {
+		league, err := getLeagueByID(tx, leagueID)
+		if err != nil {
+			return err
+		}
+
+		preset, err := getSettingsPresetByID(tx, presetID)
+		if err != nil {
+			return err
+		}
+
+		league.Settings, err = rollSettingsOntoPreset(league.Settings, preset)
+		if err != nil {
+			return err
+		}
+
+		return league.update(tx)
+	})
+}
+
+// generate generates a seed after replacing the preset references in settings
+// by files the generator can read.
+func (b *Back) generate(gen generator.Generator, settings, seed string) (generator.Output, error) {
+	resolved, err := b.resolveSettings(settings)
+	if err != nil {
+		return generator.Output{}, err
+	}
+
+	return gen.Generate(resolved, seed)
+}
+
+// resolveSettings writes the presets referenced in settings to the presets
+// cache directory and returns the settings value with the references replaced
+// by the absolute paths of the written files.
+func (b *Back) resolveSettings(settings string) (string, error) {
+	parts := splitSettings(settings)
+	var presets []SettingsPreset
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		for _, v := range parts {
+			if !isSettingsPresetRef(v) {
+				continue
+			}
+
+			preset, err := getSettingsPresetByRef(tx, v)
+			if err != nil {
+				return err
+			}
+			presets = append(presets, preset)
+		}
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	if len(presets) == 0 {
+		return settings, nil
+	}
+
+	dir, err := settingsPresetsCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	for k, v := range parts {
+		for _, preset := range presets {
+			if v != preset.Ref() {
+				continue
+			}
+
+			path, err := writeSettingsPreset(dir, preset)
+			if err != nil {
+				return "", fmt.Errorf("unable to write preset %s: %w", preset.Ref(), err)
+			}
+			parts[k] = path
+		}
+	}
+
+	return strings.Join(parts, ":"), nil
+}
+
+// settingsHash returns a hash of the content of the settings files used by a
+// settings value. Presets are immutable so their reference is enough, files
+// that do not exist are only hashed by name as not all generators read them.
+func (b *Back) settingsHash(generatorID, settings string) (string, error) {
+	dir, err := b.settingsDir(generatorID)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, v := range splitSettings(settings) {
+		fmt.Fprintf(h, "%s\x00", v)
+		if isSettingsPresetRef(v) {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(generator.SettingsPath(dir, v))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", err
+		}
+		fmt.Fprintf(h, "%d\x00", len(content))
+		_, _ = h.Write(content)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// settingsDir returns the directory in which a generator resolves settings
+// names.
+func (b *Back) settingsDir(generatorID string) (string, error) {
+	if conf, ok := b.config.ProcessGenerators()[generatorName(generatorID)]; ok {
+		return conf.SettingsDir, nil
+	}
+
+	return oot.GetBaseDir()
+}
+
+// settingsPresetsCacheDir returns the directory presets are written to for
+// the generators to read them, outside of the resources tree.
+func settingsPresetsCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Abs(filepath.Join(dir, "kaepora", "presets"))
+}
+
+// writeSettingsPreset writes the preset in dir and returns its path.
+// File names include the content hash so an existing file is never rewritten.
+func writeSettingsPreset(dir string, preset SettingsPreset) (string, error) {
+	path := filepath.Join(dir, preset.FileName())
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile(dir, ".tmp-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(preset.Content); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return path, os.Rename(f.Name(), path)
+}
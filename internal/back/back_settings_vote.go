@@ -0,0 +1,143 @@
+package back
+
+import (
+	"database/sql"
+	"errors"
+	"kaepora/internal/util"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// VoteForSettingsByShortcode registers the vote of a player for the settings
+// of the joinable session of a league. option is either the 1-based position
+// of the option or its value. It returns the League and the voted settings.
+func (b *Back) VoteForSettingsByShortcode(player Player, shortcode, option string) (
+	league League,
+	settings string,
+	_ error,
+) {
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		league, err = getLeagueByShortCode(tx, shortcode)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return util.ErrPublic("could not find a league with this shortcode, try `!leagues`")
+			}
+			return err
+		}
+
+		session, err := getNextJoinableMatchSessionForLeague(tx, league.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return util.ErrPublic("could not find a joinable race for the given league")
+			}
+			return err
+		}
+
+		settings, err = voteForSettingsTx(tx, session, league, player.ID, option)
+		return err
+	}); err != nil {
+		return League{}, "", err
+	}
+
+	return league, settings, nil
+}
+
+// VoteForSettings registers the vote of a player for the settings of the
+// given session, see VoteForSettingsByShortcode.
+func (b *Back) VoteForSettings(sessionID, playerID util.UUIDAsBlob, option string) error {
+	return b.transaction(func(tx *sqlx.Tx) error {
+		session, err := getMatchSessionByID(tx, sessionID)
+		if err != nil {
+			return err
+		}
+
+		league, err := getLeagueByID(tx, session.LeagueID)
+		if err != nil {
+			return err
+		}
+
+		_, err = voteForSettingsTx(tx, session, league, playerID, option)
+		return err
+	})
+}
+
+func voteForSettingsTx(
+	tx *sqlx.Tx,
+	session MatchSession,
+	league League,
+	playerID util.UUIDAsBlob,
+	option string,
+) (string, error) {
+	if err := session.canVoteForSettings(league, playerID); err != nil {
+		return "", err
+	}
+
+	settings, err := parseSettingsVoteOption(league.SettingsVoteOptionsList(), option)
+	if err != nil {
+		return "", err
+	}
+
+	vote := NewSettingsVote(session.ID, playerID, settings)
+	return settings, vote.upsert(tx)
+}
+
+// GetSettingsVoteResults returns the votes received by each option of the
+// League of the session.
+func (b *Back) GetSettingsVoteResults(session MatchSession, league League) (ret []SettingsVoteResult, _ error) {
+	return ret, b.transaction(func(tx *sqlx.Tx) (err error) {
+		ret, err = getSettingsVoteResults(tx, session, league)
+		return err
+	})
+}
+
+// GetPlayerSettingsVote returns the settings a player voted for in a session
+// or an empty string if the player did not vote.
+func (b *Back) GetPlayerSettingsVote(sessionID, playerID util.UUIDAsBlob) (ret string, _ error) {
+	return ret, b.transaction(func(tx *sqlx.Tx) error {
+		err := tx.Get(
+			&ret,
+			`SELECT Settings FROM SettingsVote WHERE MatchSessionID = ? AND PlayerID = ?`,
+			sessionID, playerID,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	})
+}
+
+// leadingSettingsVote returns the option currently winning the settings vote
+// of the session, the second return value is false if no one voted.
+func leadingSettingsVote(tx *sqlx.Tx, session MatchSession, league League) (SettingsVoteResult, bool, error) {
+	if !league.HasSettingsVote() {
+		return SettingsVoteResult{}, false, nil
+	}
+
+	results, err := getSettingsVoteResults(tx, session, league)
+	if err != nil {
+		return SettingsVoteResult{}, false, err
+	}
+
+	winner, ok := winningSettingsVoteResult(results)
+	return winner, ok, nil
+}
+
+// tallySettingsVote replaces the settings of the session with the most voted
+// option, the session is left untouched if no one voted.
+// The caller is responsible for updating the session.
+func tallySettingsVote(tx *sqlx.Tx, session *MatchSession, league League) error {
+	winner, ok, err := leadingSettingsVote(tx, *session, league)
+	if err != nil || !ok {
+		return err
+	}
+
+	log.Printf(
+		"info: session %s settings voted to %s (%d votes)",
+		session.ID, winner.Settings, winner.Votes,
+	)
+	session.Settings = winner.Settings
+
+	return nil
+}
@@ -2,9 +2,13 @@ package back
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"io"
 	"kaepora/internal/util"
 	"log"
+	"math"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -19,134 +23,219 @@ type StatsMisc struct {
 	AveragePlayersPerRace, MostPlayersInARace int
 }
 
-func (b *Back) GetMiscStats(shortcode string) (misc StatsMisc, _ error) { // nolint:funlen
+func computeMiscStats(tx *sqlx.Tx, league League) (misc StatsMisc, _ error) { // nolint:funlen
 	start := time.Now()
 	defer func() { log.Printf("info: computed misc stats in %s", time.Since(start)) }()
 
-	if err := b.transaction(func(tx *sqlx.Tx) error {
-		league, err := getLeagueByShortCode(tx, shortcode)
-		if err != nil {
-			return err
-		}
-
-		queries := []struct {
-			Dst   interface{}
-			Query string
-			Args  []interface{}
-		}{
-			{&misc.RankedPlayers, `SELECT COUNT(*) FROM PlayerRating WHERE LeagueID = ?`, []interface{}{league.ID}},
-			{
-				&misc.PlayersOnLeaderboard,
-				`SELECT COUNT(*) FROM PlayerRating WHERE LeagueID = ? AND Deviation < ?`,
-				[]interface{}{league.ID, DeviationThreshold},
-			},
-
-			{
-				&misc.SeedsPlayed,
-				`SELECT COUNT(*) FROM Match
+	queries := []struct {
+		Dst   interface{}
+		Query string
+		Args  []interface{}
+	}{
+		{&misc.RankedPlayers, `SELECT COUNT(*) FROM PlayerRating WHERE LeagueID = ?`, []interface{}{league.ID}},
+		{
+			&misc.PlayersOnLeaderboard,
+			`SELECT COUNT(*) FROM PlayerRating WHERE LeagueID = ? AND Deviation < ?`,
+			[]interface{}{league.ID, DeviationThreshold},
+		},
+
+		{
+			&misc.SeedsPlayed,
+			`SELECT COUNT(*) FROM Match
                 INNER JOIN MatchSession ON (Match.MatchSessionID = MatchSession.ID)
                 WHERE MatchSession.LeagueID = ? AND MatchSession.Status = ?`,
-				[]interface{}{league.ID, MatchSessionStatusClosed},
-			},
-			{
-				&misc.Forfeits,
-				`SELECT COUNT(*) FROM MatchEntry
+			[]interface{}{league.ID, MatchSessionStatusClosed},
+		},
+		{
+			&misc.Forfeits,
+			`SELECT COUNT(*) FROM MatchEntry
                 INNER JOIN Match ON (MatchEntry.MatchID = Match.ID)
                 INNER JOIN MatchSession ON (Match.MatchSessionID = MatchSession.ID)
                 WHERE Match.LeagueID = ? AND MatchEntry.Status = ? AND MatchSession.Status = ?`,
-				[]interface{}{league.ID, MatchEntryStatusForfeit, MatchSessionStatusClosed},
-			},
-			{
-				&misc.DoubleForfeits,
-				`SELECT COUNT(*) FROM (SELECT COUNT(*) as cnt FROM "MatchEntry"
+			[]interface{}{league.ID, MatchEntryStatusForfeit, MatchSessionStatusClosed},
+		},
+		{
+			&misc.DoubleForfeits,
+			`SELECT COUNT(*) FROM (SELECT COUNT(*) as cnt FROM "MatchEntry"
                 INNER JOIN Match ON (MatchEntry.MatchID = Match.ID)
                 INNER JOIN MatchSession ON (Match.MatchSessionID = MatchSession.ID)
                 WHERE Match.LeagueID = ? AND MatchEntry.Status == ? AND MatchSession.Status = ?
                 GROUP BY MatchEntry.MatchID HAVING cnt > 1)`,
-				[]interface{}{league.ID, MatchEntryStatusForfeit, MatchSessionStatusClosed},
-			},
-			{
-				&misc.TotalSeedTime,
-				`SELECT COALESCE(? * SUM(MatchEntry.EndedAt - MatchEntry.StartedAt), 0) FROM MatchEntry
+			[]interface{}{league.ID, MatchEntryStatusForfeit, MatchSessionStatusClosed},
+		},
+		{
+			&misc.TotalSeedTime,
+			`SELECT COALESCE(? * SUM(MatchEntry.EndedAt - MatchEntry.StartedAt), 0) FROM MatchEntry
                 INNER JOIN Match ON (MatchEntry.MatchID = Match.ID)
                 INNER JOIN MatchSession ON (Match.MatchSessionID = MatchSession.ID)
                 WHERE Match.LeagueID = ? AND MatchSession.Status = ?`,
-				[]interface{}{time.Second, league.ID, MatchSessionStatusClosed},
-			},
-			{
-				&misc.FirstLadderRace,
-				`SELECT StartDate FROM MatchSession
+			[]interface{}{time.Second, league.ID, MatchSessionStatusClosed},
+		},
+		{
+			&misc.FirstLadderRace,
+			`SELECT StartDate FROM MatchSession
                 WHERE LeagueID = ?
                 ORDER BY StartDate ASC LIMIT 1`,
-				[]interface{}{league.ID},
-			},
-			{
-				&misc.AveragePlayersPerRace,
-				`SELECT COALESCE(round(avg(json_array_length(PlayerIDs))), 0)
-                FROM MatchSession WHERE LeagueID = ? AND MatchSession.Status = ?`,
-				[]interface{}{league.ID, MatchSessionStatusClosed},
-			},
-			{
-				&misc.MostPlayersInARace,
-				`SELECT COALESCE(max(json_array_length(PlayerIDs)), 0)
-                FROM MatchSession WHERE LeagueID = ? AND MatchSession.Status = ?`,
-				[]interface{}{league.ID, MatchSessionStatusClosed},
-			},
-		}
+			[]interface{}{league.ID},
+		},
+	}
 
-		for _, v := range queries {
-			if err := tx.Get(v.Dst, v.Query, v.Args...); err != nil {
-				// Ignore empty results, that's just an empty league.
-				if err != sql.ErrNoRows {
-					return err
-				}
+	for _, v := range queries {
+		if err := tx.Get(v.Dst, v.Query, v.Args...); err != nil {
+			// Ignore empty results, that's just an empty league.
+			if err != sql.ErrNoRows {
+				return StatsMisc{}, err
 			}
 		}
+	}
 
-		return nil
-	}); err != nil {
+	// Counted here rather than in SQL to not require the JSON1 extension.
+	var playerIDs []util.UUIDArrayAsJSON
+	if err := tx.Select(
+		&playerIDs,
+		`SELECT PlayerIDs FROM MatchSession WHERE LeagueID = ? AND Status = ?`,
+		league.ID, MatchSessionStatusClosed,
+	); err != nil {
 		return StatsMisc{}, err
 	}
 
+	var total int
+	for _, v := range playerIDs {
+		total += len(v)
+		if len(v) > misc.MostPlayersInARace {
+			misc.MostPlayersInARace = len(v)
+		}
+	}
+	if len(playerIDs) > 0 {
+		misc.AveragePlayersPerRace = int(math.Round(float64(total) / float64(len(playerIDs))))
+	}
+
 	return misc, nil
 }
 
-// MapSpoilerLogs applies a function on all the spoilers log of completed
+// AttendanceSlots is the number of time slots a day is split into in the
+// Attendance stats.
+const AttendanceSlots = 3
+
+// Attendance counts the players of the closed sessions of a league per time
+// slot of the day (UTC) and per day of the week, Monday first.
+type Attendance struct {
+	Players  [AttendanceSlots][7]int
+	Sessions [AttendanceSlots][7]int
+}
+
+func (a *Attendance) Add(session MatchSession) {
+	t := session.StartDate.Time().UTC()
+	slot := t.Hour() / (24 / AttendanceSlots)
+	dow := (int(t.Weekday()) + 6) % 7
+
+	a.Players[slot][dow] += len(session.PlayerIDs)
+	a.Sessions[slot][dow]++
+}
+
+// Average returns the average number of players of the sessions of a slot.
+func (a Attendance) Average(slot, dow int) int {
+	if a.Sessions[slot][dow] == 0 {
+		return 0
+	}
+
+	return a.Players[slot][dow] / a.Sessions[slot][dow]
+}
+
+func (a *Attendance) Scan(src interface{}) error {
+	*a = Attendance{}
+	return scanJSON(src, a)
+}
+
+func (a Attendance) Value() (driver.Value, error) {
+	return valueJSON(a)
+}
+
+// SeedTimes is the histogram of the finish times of a league, the first bin
+// holds the times under two hours, the next ones are 30 minutes wide, and the
+// last one holds the times of five hours and more.
+type SeedTimes [8]int
+
+func (t *SeedTimes) Add(d time.Duration) {
+	var i int
+	if d >= 2*time.Hour {
+		i = int((d-2*time.Hour)/(30*time.Minute)) + 1
+	}
+	if i >= len(t) {
+		i = len(t) - 1
+	}
+
+	t[i]++
+}
+
+func (t *SeedTimes) Scan(src interface{}) error {
+	*t = SeedTimes{}
+	return scanJSON(src, t)
+}
+
+func (t SeedTimes) Value() (driver.Value, error) {
+	return valueJSON(t)
+}
+
+func scanJSON(src interface{}, dst interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), dst)
+	case []byte:
+		return json.Unmarshal(src, dst)
+	default:
+		return fmt.Errorf("expected []byte or string, got %T", src)
+	}
+}
+
+func valueJSON(v interface{}) (driver.Value, error) {
+	str, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.Value(str), nil
+}
+
+// mapSpoilerLogs applies a function on all the spoilers log of completed
 // matches of a league.
-func (b *Back) MapSpoilerLogs(
-	shortcode string,
+func (b *Back) mapSpoilerLogs(
+	tx *sqlx.Tx,
+	leagueID util.UUIDAsBlob,
 	cb func(io.Reader) error,
 ) error {
-	return b.transaction(func(tx *sqlx.Tx) error {
-		league, err := getLeagueByShortCode(tx, shortcode)
-		if err != nil {
-			return err
-		}
-
-		rows, err := tx.Query(`
-            SELECT Match.SpoilerLog FROM Match
-            INNER JOIN MatchSession ON (Match.MatchSessionID = MatchSession.ID)
-            WHERE Match.LeagueID = ? AND MatchSession.Status = ? AND Match.EndedAt IS NOT NULL`,
-			league.ID, MatchSessionStatusClosed,
-		)
-		if err != nil {
+	rows, err := tx.Query(`
+        SELECT Match.SpoilerLog, Match.SpoilerLogKey FROM Match
+        INNER JOIN MatchSession ON (Match.MatchSessionID = MatchSession.ID)
+        WHERE Match.LeagueID = ? AND MatchSession.Status = ? AND Match.EndedAt IS NOT NULL`,
+		leagueID, MatchSessionStatusClosed,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		buf util.ZLIBBlob
+		key string
+	)
+	for rows.Next() {
+		if err := rows.Scan(&buf, &key); err != nil {
 			return err
 		}
-		defer rows.Close()
 
-		var buf util.ZLIBBlob
-		for rows.Next() {
-			if err := rows.Scan(&buf); err != nil {
-				return err
-			}
-
-			if err := cb(buf.Uncompressed()); err != nil {
-				return err
+		if key != "" {
+			if buf, err = b.getBlob(key); err != nil {
+				return fmt.Errorf("unable to load spoiler log: %w", err)
 			}
+		}
 
-			buf = buf[:0]
+		if err := cb(buf.Uncompressed()); err != nil {
+			return err
 		}
 
-		return rows.Err()
-	})
+		buf = buf[:0]
+	}
+
+	return rows.Err()
 }
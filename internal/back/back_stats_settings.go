@@ -0,0 +1,289 @@
+package back
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/util"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SettingValueStats relates a shuffled setting value to the outcome of the
+// seeds rolled with it.
+type SettingValueStats struct {
+	Name, Value string
+
+	Seeds    int // seeds rolled with this value
+	Entries  int // players who raced these seeds
+	Forfeits int
+
+	MedianTime time.Duration // of the finished entries
+	// MedianShift is MedianTime minus the median of all the league finish
+	// times, positive when the value makes seeds longer.
+	MedianShift time.Duration
+}
+
+// SettingsValueStats are the SettingValueStats of a league, as stored in its
+// LeagueStats.
+type SettingsValueStats []SettingValueStats
+
+func (s *SettingsValueStats) Scan(src interface{}) error {
+	*s = nil
+	return scanJSON(src, s)
+}
+
+func (s SettingsValueStats) Value() (driver.Value, error) {
+	if s == nil {
+		return valueJSON([]SettingValueStats{})
+	}
+
+	return valueJSON([]SettingValueStats(s))
+}
+
+// GetSettingsValueStats returns the stored impact of each shuffled setting
+// value on the finish times and forfeits of a league, sorted by decreasing
+// absolute shift. Leagues without shuffled settings have no stats.
+func (b *Back) GetSettingsValueStats(shortcode string) ([]SettingValueStats, error) {
+	stats, err := b.getStoredLeagueStats(shortcode)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.SettingsValues, nil
+}
+
+type settingsValueAcc struct {
+	SettingValueStats
+	times []time.Duration
+}
+
+// getSettingsValueStats computes the SettingsValueStats of a league from all
+// its closed sessions.
+func getSettingsValueStats(tx *sqlx.Tx, leagueID util.UUIDAsBlob) (SettingsValueStats, error) {
+	rows, err := tx.Queryx(`
+        SELECT Match.ID, Match.GeneratorState, MatchEntry.Status,
+            COALESCE(MatchEntry.EndedAt - MatchEntry.StartedAt, 0) AS Time
+        FROM MatchEntry
+        INNER JOIN Match ON (Match.ID = MatchEntry.MatchID)
+        INNER JOIN MatchSession ON (MatchSession.ID = Match.MatchSessionID)
+        WHERE Match.LeagueID = ? AND MatchSession.Status = ?
+        ORDER BY Match.ID`,
+		leagueID, MatchSessionStatusClosed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		accs    = map[string]*settingsValueAcc{} // "name=value" => acc
+		all     []time.Duration
+		lastID  string
+		current []*settingsValueAcc // accumulators of the current match
+	)
+
+	for rows.Next() {
+		var row struct {
+			ID             []byte
+			GeneratorState []byte
+			Status         MatchEntryStatus
+			Time           int64
+		}
+		if err := rows.StructScan(&row); err != nil {
+			return nil, err
+		}
+
+		if string(row.ID) != lastID {
+			lastID = string(row.ID)
+			current, err = settingsValueAccsForState(accs, row.GeneratorState)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		d := time.Duration(row.Time) * time.Second
+		if row.Status == MatchEntryStatusFinished {
+			all = append(all, d)
+		}
+
+		for _, acc := range current {
+			acc.Entries++
+			switch row.Status { // nolint:exhaustive
+			case MatchEntryStatusFinished:
+				acc.times = append(acc.times, d)
+			case MatchEntryStatusForfeit:
+				acc.Forfeits++
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	median := medianDuration(all)
+	ret := make([]SettingValueStats, 0, len(accs))
+	for _, acc := range accs {
+		if len(acc.times) > 0 {
+			acc.MedianTime = medianDuration(acc.times)
+			acc.MedianShift = acc.MedianTime - median
+		}
+		ret = append(ret, acc.SettingValueStats)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := absDuration(ret[i].MedianShift), absDuration(ret[j].MedianShift)
+		if a == b {
+			return ret[i].Name+ret[i].Value < ret[j].Name+ret[j].Value
+		}
+
+		return a > b
+	})
+
+	return ret, nil
+}
+
+// settingsValueAccsForState returns the accumulators of the settings values
+// rolled for a match, creating them as needed and counting the seed.
+func settingsValueAccsForState(accs map[string]*settingsValueAcc, stateJSON []byte) ([]*settingsValueAcc, error) {
+	if len(stateJSON) == 0 {
+		return nil, nil
+	}
+
+	var state oot.State
+	if err := json.Unmarshal(stateJSON, &state); err != nil {
+		return nil, err
+	}
+
+	ret := make([]*settingsValueAcc, 0, len(state.SettingsPatch))
+	for name, v := range state.SettingsPatch {
+		value := fmt.Sprintf("%v", v)
+		key := name + "=" + value
+		if _, ok := accs[key]; !ok {
+			accs[key] = &settingsValueAcc{
+				SettingValueStats: SettingValueStats{Name: name, Value: value},
+			}
+		}
+
+		accs[key].Seeds++
+		ret = append(ret, accs[key])
+	}
+
+	return ret, nil
+}
+
+func medianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+
+	return sorted[mid]
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
+
+// PlayerSettingValueStats is the performance of a single player on the seeds
+// rolled with a shuffled setting value.
+type PlayerSettingValueStats struct {
+	LeagueID    util.UUIDAsBlob
+	Name, Value string
+
+	Races, Wins, Losses, Forfeits int
+	MedianTime                    time.Duration // of the finished races
+}
+
+// ComputePlayerSettingsValueStats returns the performance of a player for
+// each settings value rolled in the given matches, as returned by
+// GetPlayerMatches. The result is sorted by league, setting, and value.
+func ComputePlayerSettingsValueStats(
+	playerID util.UUIDAsBlob,
+	matches []Match,
+) ([]PlayerSettingValueStats, error) {
+	type acc struct {
+		PlayerSettingValueStats
+		times []time.Duration
+	}
+	accs := map[string]*acc{} // "league/name=value" => acc
+
+	for k := range matches {
+		if len(matches[k].GeneratorState) == 0 {
+			continue
+		}
+
+		var state oot.State
+		if err := json.Unmarshal(matches[k].GeneratorState, &state); err != nil {
+			return nil, err
+		}
+
+		self, _, err := matches[k].GetPlayerAndOpponentEntries(playerID)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, v := range state.SettingsPatch {
+			value := fmt.Sprintf("%v", v)
+			key := matches[k].LeagueID.String() + "/" + name + "=" + value
+			if _, ok := accs[key]; !ok {
+				accs[key] = &acc{PlayerSettingValueStats: PlayerSettingValueStats{
+					LeagueID: matches[k].LeagueID,
+					Name:     name,
+					Value:    value,
+				}}
+			}
+
+			cur := accs[key]
+			cur.Races++
+			switch self.Outcome {
+			case MatchEntryOutcomeWin:
+				cur.Wins++
+			case MatchEntryOutcomeLoss:
+				cur.Losses++
+			case MatchEntryOutcomeDraw:
+			}
+
+			switch self.Status { // nolint:exhaustive
+			case MatchEntryStatusForfeit:
+				cur.Forfeits++
+			case MatchEntryStatusFinished:
+				cur.times = append(cur.times, self.EndedAt.Time.Time().Sub(self.StartedAt.Time.Time()))
+			}
+		}
+	}
+
+	ret := make([]PlayerSettingValueStats, 0, len(accs))
+	for _, v := range accs {
+		v.MedianTime = medianDuration(v.times)
+		ret = append(ret, v.PlayerSettingValueStats)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].LeagueID != ret[j].LeagueID {
+			return ret[i].LeagueID.String() < ret[j].LeagueID.String()
+		}
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+
+		return ret[i].Value < ret[j].Value
+	})
+
+	return ret, nil
+}
@@ -0,0 +1,166 @@
+package back // nolint:testpackage
+
+import (
+	"kaepora/internal/util"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSettingsValueStats(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	// Entries as minutes to finish, negative for forfeits.
+	matches := []struct {
+		state   string
+		entries [2]int
+	}{
+		{`{"SettingsPatch": {"shuffle_song_items": true}}`, [2]int{200, 220}},
+		{`{"SettingsPatch": {"shuffle_song_items": true}}`, [2]int{180, -1}},
+		{`{"SettingsPatch": {"shuffle_song_items": false}}`, [2]int{100, 120}},
+		{`{"SettingsPatch": {"shuffle_song_items": false}}`, [2]int{140, 160}},
+	}
+
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		league, err := getLeagueByShortCode(tx, "testa")
+		if err != nil {
+			return err
+		}
+		players := [2]Player{}
+		for k, name := range []string{"Darunia", "Nabooru"} {
+			if players[k], err = getPlayerByName(tx, name); err != nil {
+				return err
+			}
+		}
+
+		session := NewMatchSession(league.ID, time.Now())
+		session.Status = MatchSessionStatusClosed
+		if err := session.insert(tx); err != nil {
+			return err
+		}
+
+		for _, v := range matches {
+			match, err := NewMatch(tx, session, "1")
+			if err != nil {
+				return err
+			}
+			match.GeneratorState = []byte(v.state)
+			if err := match.insert(tx); err != nil {
+				return err
+			}
+
+			start := time.Now().Add(-24 * time.Hour)
+			for k, minutes := range v.entries {
+				entry := NewMatchEntry(match.ID, players[k].ID)
+				entry.StartedAt = util.NewNullTimeAsTimestamp(start)
+				entry.Status = MatchEntryStatusFinished
+				if minutes < 0 {
+					entry.Status = MatchEntryStatusForfeit
+					minutes = 10
+				}
+				entry.EndedAt = util.NewNullTimeAsTimestamp(start.Add(time.Duration(minutes) * time.Minute))
+				if err := entry.insert(tx); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stats are only computed when the league stats are built.
+	if stats, _ := back.GetSettingsValueStats("testa"); len(stats) != 0 {
+		t.Fatalf("expected no stats before the league stats are built, got %d", len(stats))
+	}
+	if err := back.RebuildLeagueStats("testa"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := back.GetSettingsValueStats("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Median of all finish times is 160m.
+	expected := []SettingValueStats{
+		{
+			Name: "shuffle_song_items", Value: "true",
+			Seeds: 2, Entries: 4, Forfeits: 1,
+			MedianTime: 200 * time.Minute, MedianShift: 40 * time.Minute,
+		},
+		{
+			Name: "shuffle_song_items", Value: "false",
+			Seeds: 2, Entries: 4,
+			MedianTime: 130 * time.Minute, MedianShift: -30 * time.Minute,
+		},
+	}
+
+	if len(stats) != len(expected) {
+		t.Fatalf("expected %d values, got %d: %#v", len(expected), len(stats), stats)
+	}
+	for k := range expected {
+		if stats[k] != expected[k] {
+			t.Errorf("expected %#v, got %#v", expected[k], stats[k])
+		}
+	}
+}
+
+func TestComputePlayerSettingsValueStats(t *testing.T) {
+	player, opponent := util.NewUUIDAsBlob(), util.NewUUIDAsBlob()
+	league := util.NewUUIDAsBlob()
+	start := time.Now().Add(-24 * time.Hour)
+
+	newMatch := func(state string, status MatchEntryStatus, outcome MatchEntryOutcome, minutes int) Match {
+		self := MatchEntry{
+			PlayerID:  player,
+			Status:    status,
+			Outcome:   outcome,
+			StartedAt: util.NewNullTimeAsTimestamp(start),
+			EndedAt:   util.NewNullTimeAsTimestamp(start.Add(time.Duration(minutes) * time.Minute)),
+		}
+
+		return Match{
+			LeagueID:       league,
+			GeneratorState: []byte(state),
+			Entries:        []MatchEntry{{PlayerID: opponent}, self},
+		}
+	}
+
+	on := `{"SettingsPatch": {"shuffle_overworld_entrances": true}}`
+	off := `{"SettingsPatch": {"shuffle_overworld_entrances": false}}`
+	matches := []Match{
+		newMatch(on, MatchEntryStatusFinished, MatchEntryOutcomeLoss, 200),
+		newMatch(on, MatchEntryStatusForfeit, MatchEntryOutcomeLoss, 10),
+		newMatch(on, MatchEntryStatusFinished, MatchEntryOutcomeWin, 180),
+		newMatch(off, MatchEntryStatusFinished, MatchEntryOutcomeWin, 120),
+		newMatch("", MatchEntryStatusFinished, MatchEntryOutcomeWin, 120), // not shuffled
+	}
+
+	stats, err := ComputePlayerSettingsValueStats(player, matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []PlayerSettingValueStats{
+		{
+			LeagueID: league, Name: "shuffle_overworld_entrances", Value: "false",
+			Races: 1, Wins: 1, MedianTime: 120 * time.Minute,
+		},
+		{
+			LeagueID: league, Name: "shuffle_overworld_entrances", Value: "true",
+			Races: 3, Wins: 1, Losses: 2, Forfeits: 1, MedianTime: 190 * time.Minute,
+		},
+	}
+
+	if len(stats) != len(expected) {
+		t.Fatalf("expected %d values, got %d: %#v", len(expected), len(stats), stats)
+	}
+	for k := range expected {
+		if stats[k] != expected[k] {
+			t.Errorf("expected %#v, got %#v", expected[k], stats[k])
+		}
+	}
+}
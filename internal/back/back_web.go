@@ -4,7 +4,12 @@ package back
 // Please do not call them outside of the webserver.
 
 import (
+	"database/sql"
+	"errors"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/generator/oot/settings"
 	"kaepora/internal/util"
+	"log"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -104,6 +109,83 @@ func (b *Back) GetLeaguesMap() (map[util.UUIDAsBlob]League, error) {
 	return ret, nil
 }
 
+// ShuffledSettingsLeague is a league using shuffled settings along with the
+// parameters its settings are shuffled with.
+type ShuffledSettingsLeague struct {
+	League     League
+	Parameters settings.Parameters
+}
+
+// GetShuffledSettingsLeagues returns the leagues using shuffled settings,
+// leagues whose settings cannot be loaded are omitted.
+func (b *Back) GetShuffledSettingsLeagues() ([]ShuffledSettingsLeague, error) {
+	leagues, err := b.GetLeagues()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]ShuffledSettingsLeague, 0, len(leagues))
+	for _, league := range leagues {
+		switch generatorName(league.Generator) {
+		case oot.SettingsRandomizerName, oot.SettingsRandomizerAPIName:
+		default:
+			continue
+		}
+
+		resolved, err := b.resolveSettings(league.Settings)
+		if err != nil {
+			log.Printf("warning: unable to resolve settings of league %s: %v", league.ShortCode, err)
+			continue
+		}
+
+		s, err := oot.LoadShuffledSettings(resolved)
+		if err != nil {
+			log.Printf("warning: unable to load settings of league %s: %v", league.ShortCode, err)
+			continue
+		}
+
+		ret = append(ret, ShuffledSettingsLeague{
+			League:     league,
+			Parameters: s.Parameters,
+		})
+	}
+
+	return ret, nil
+}
+
+// GetScheduledSettings returns the settings a League sessions will use for
+// each of the given dates, sorted and including sessions not yet created.
+func (b *Back) GetScheduledSettings(league League, dates []time.Time) ([]string, error) {
+	ret := make([]string, len(dates))
+	if len(dates) == 0 {
+		return ret, nil
+	}
+
+	rotation := league.Rotation()
+	return ret, b.transaction(func(tx *sqlx.Tx) error {
+		first, err := countMatchSessionsBefore(tx, league.ID, dates[0])
+		if err != nil {
+			return err
+		}
+
+		for k, date := range dates {
+			session, err := getMatchSessionByStartDate(tx, league.ID, date)
+			if err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+
+				session = NewMatchSession(league.ID, date)
+				session.Settings = rotation.Pick(date, first+k)
+			}
+
+			ret[k] = session.SettingsOrDefault(league)
+		}
+
+		return nil
+	})
+}
+
 func (b *Back) GetLeagueByShortcode(shortcode string) (ret League, _ error) {
 	return ret, b.transaction(func(tx *sqlx.Tx) (err error) {
 		ret, err = getLeagueByShortCode(tx, shortcode)
@@ -118,6 +200,13 @@ func (b *Back) GetLeague(id util.UUIDAsBlob) (ret League, _ error) {
 	})
 }
 
+func (b *Back) GetGame(id util.UUIDAsBlob) (ret Game, _ error) {
+	return ret, b.transaction(func(tx *sqlx.Tx) (err error) {
+		ret, err = getGameByID(tx, id)
+		return err
+	})
+}
+
 func (b *Back) UpdateLeague(l League) error {
 	return b.transaction(l.update)
 }
@@ -243,7 +332,22 @@ func (b *Back) GetMatchSession(id util.UUIDAsBlob) (
 	return session, matches, players, nil
 }
 
-func (b *Back) GetMatch(id util.UUIDAsBlob) (match Match, _ error) {
+func (b *Back) GetMatch(id util.UUIDAsBlob) (Match, error) {
+	match, err := b.GetMatchWithoutBlobs(id)
+	if err != nil {
+		return Match{}, err
+	}
+
+	if err := b.LoadMatchBlobs(&match); err != nil {
+		return Match{}, err
+	}
+
+	return match, nil
+}
+
+// GetMatchWithoutBlobs returns a Match without loading its spoiler log and
+// seed patch from the blob store, see LoadMatchBlobs.
+func (b *Back) GetMatchWithoutBlobs(id util.UUIDAsBlob) (match Match, _ error) {
 	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
 		match, err = getMatchByID(tx, id)
 		if err != nil {
@@ -258,6 +362,12 @@ func (b *Back) GetMatch(id util.UUIDAsBlob) (match Match, _ error) {
 	return match, nil
 }
 
+// LoadMatchBlobs loads the spoiler log and seed patch of a Match returned by
+// GetMatchWithoutBlobs.
+func (b *Back) LoadMatchBlobs(match *Match) error {
+	return b.loadMatchBlobs(match)
+}
+
 func (b *Back) GetPlayerByName(name string) (player Player, _ error) {
 	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
 		player, err = getPlayerByName(tx, name)
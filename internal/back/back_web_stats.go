@@ -17,6 +17,9 @@ import (
 
 const emptySVG = `<svg xmlns="http://www.w3.org/2000/svg"/>`
 
+// GetRatingsDistributionGraph renders the current ratings of a league. It is
+// not stored with the LeagueStats as it only reads the PlayerRating of the
+// league, which change on every ranking update.
 func (b *Back) GetRatingsDistributionGraph(shortcode string) ([]byte, error) {
 	start := time.Now()
 	defer func() { log.Printf("info: computed ratings stats in %s", time.Since(start)) }()
@@ -107,28 +110,15 @@ func (b *Back) getRatingsStats(
 	return bars, float64(maxValue) / float64(valuesCount), nil
 }
 
+// GetLeagueSeedTimesGraph renders the finish times stored in the league
+// stats.
 func (b *Back) GetLeagueSeedTimesGraph(shortcode string) ([]byte, error) {
-	var svg []byte
-	start := time.Now()
-	defer func() { log.Printf("info: computed seed completion stats in %s", time.Since(start)) }()
-
-	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
-		league, err := getLeagueByShortCode(tx, shortcode)
-		if err != nil {
-			return err
-		}
-
-		svg, err = generateLeagueSeedTimesGraph(tx, league.ID)
-		if err != nil {
-			return err
-		}
-
-		return nil
-	}); err != nil {
+	stats, err := b.getStoredLeagueStats(shortcode)
+	if err != nil {
 		return nil, err
 	}
 
-	return svg, nil
+	return generateSeedTimesGraph(stats.SeedTimes)
 }
 
 func generatePlayerSeedTimesGraph(tx *sqlx.Tx, playerID, leagueID util.UUIDAsBlob) ([]byte, error) {
@@ -151,10 +141,17 @@ func generatePlayerSeedTimesGraph(tx *sqlx.Tx, playerID, leagueID util.UUIDAsBlo
 		return nil, err
 	}
 
-	return generateSeedTimesGraph(times)
+	var histogram SeedTimes
+	for _, v := range times {
+		histogram.Add(time.Duration(v) * time.Second)
+	}
+
+	return generateSeedTimesGraph(histogram)
 }
 
-func generateLeagueSeedTimesGraph(tx *sqlx.Tx, leagueID util.UUIDAsBlob) ([]byte, error) {
+// getLeagueSeedTimes returns the finish times in seconds of all the finished
+// entries of the closed sessions of a league.
+func getLeagueSeedTimes(tx *sqlx.Tx, leagueID util.UUIDAsBlob) ([]int, error) {
 	var times []int
 	if err := tx.Select(
 		&times,
@@ -173,10 +170,10 @@ func generateLeagueSeedTimesGraph(tx *sqlx.Tx, leagueID util.UUIDAsBlob) ([]byte
 		return nil, err
 	}
 
-	return generateSeedTimesGraph(times)
+	return times, nil
 }
 
-func generateSeedTimesGraph(times []int) ([]byte, error) {
+func generateSeedTimesGraph(histogram SeedTimes) ([]byte, error) {
 	style := chart.Style{
 		FontColor:   drawing.ColorBlack,
 		FillColor:   drawing.ColorFromHex("1d72aa"),
@@ -196,17 +193,9 @@ func generateSeedTimesGraph(times []int) ([]byte, error) {
 	}
 
 	var hasValue bool
-	for _, v := range times {
-		i := ((v - (2 * 3600)) / (30 * 60)) + 1
-		if i < 0 {
-			i = 0
-		}
-		if i >= len(bars) {
-			i = len(bars) - 1
-		}
-
-		bars[i].Value++
-		hasValue = true
+	for i, v := range histogram {
+		bars[i].Value = float64(v)
+		hasValue = hasValue || v > 0
 	}
 
 	if !hasValue {
@@ -0,0 +1,139 @@
+package back
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"kaepora/internal/util"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	webhookTimeout          = 10 * time.Second
+	webhookDispatchInterval = 2 * time.Second
+)
+
+// GetWebhooks returns all the configured webhooks, oldest first.
+func (b *Back) GetWebhooks() (ret []Webhook, _ error) {
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		ret, err = getWebhooks(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// CreateWebhook registers a new webhook receiving the notifications of the
+// given types (NotificationType names) from now on.
+func (b *Back) CreateWebhook(url string, types []string) (webhook Webhook, _ error) {
+	webhook, err := NewWebhook(url, types)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	if err := b.transaction(webhook.insert); err != nil {
+		return Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook, its pending deliveries are discarded.
+func (b *Back) DeleteWebhook(id util.UUIDAsBlob) error {
+	return b.transaction(func(tx *sqlx.Tx) error {
+		return deleteWebhook(tx, id)
+	})
+}
+
+// runWebhookDispatcher sends the webhook notifications until done is closed.
+// This is separate from the main loop to deliver events quickly and so slow
+// endpoints cannot delay the matchmaking.
+func (b *Back) runWebhookDispatcher(wg *sync.WaitGroup, done <-chan struct{}) {
+	defer wg.Done()
+
+	for {
+		if _, err := b.dispatchWebhookNotifications(); err != nil {
+			log.Printf("error: dispatchWebhookNotifications: %s", err)
+		}
+
+		select {
+		case <-time.After(webhookDispatchInterval):
+		case <-done:
+			return
+		}
+	}
+}
+
+// dispatchWebhookNotifications sends the due webhook notifications of the
+// outbox and returns how many were sent.
+func (b *Back) dispatchWebhookNotifications() (int, error) {
+	return b.dispatchOutbox(true, b.sendWebhookNotification)
+}
+
+func (b *Back) sendWebhookNotification(n OutboxNotification) error {
+	id, err := uuid.Parse(n.Recipient)
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	var webhook Webhook
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		webhook, err = getWebhookByID(tx, util.UUIDAsBlob(id))
+		return err
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("info: dropping notification %s for deleted webhook %s", n.ID, n.Recipient)
+			return nil
+		}
+		return err
+	}
+
+	body, err := json.Marshal(WebhookEvent{
+		ID:        n.ID.String(),
+		Type:      n.TypeName(),
+		CreatedAt: n.CreatedAt.Time(),
+		Payload:   json.RawMessage(n.Payload),
+	})
+	if err != nil {
+		return err
+	}
+
+	signature, err := webhook.sign(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, signature)
+	req.Header.Set(WebhookEventHeader, n.TypeName())
+	req.Header.Set(WebhookDeliveryHeader, n.ID.String())
+
+	res, err := b.webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook %s answered %s", webhook.URL, res.Status)
+	}
+
+	return nil
+}
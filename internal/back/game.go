@@ -49,3 +49,12 @@ func getGames(tx *sqlx.Tx) ([]Game, error) {
 
 	return ret, nil
 }
+
+func getGameByID(tx *sqlx.Tx, id util.UUIDAsBlob) (Game, error) {
+	var ret Game
+	if err := tx.Get(&ret, "SELECT * FROM Game WHERE ID = ? LIMIT 1", id); err != nil {
+		return Game{}, err
+	}
+
+	return ret, nil
+}
@@ -1,9 +1,11 @@
 package back
 
 import (
+	"kaepora/internal/back/rotation"
 	"kaepora/internal/back/schedule"
 	"kaepora/internal/util"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -24,6 +26,19 @@ type League struct {
 	Settings  string
 	Schedule  schedule.Config
 
+	// SettingsRotation picks the Settings of each MatchSession, sessions use
+	// Settings when the rotation picks nothing.
+	SettingsRotation rotation.Config
+
+	// SettingsVoteOptions is a comma-separated list of settings runners can
+	// vote for while a session is joinable, the most voted replaces the
+	// session settings.
+	SettingsVoteOptions string
+
+	// FallbackGenerators is a comma-separated list of generators to try in
+	// order when Generator fails to generate a seed.
+	FallbackGenerators string
+
 	AnnounceDiscordChannelID null.String
 }
 
@@ -37,6 +52,8 @@ func NewLeague(name string, shortCode string, gameID util.UUIDAsBlob, generator,
 		ShortCode: shortCode,
 		Settings:  settings,
 		Schedule:  schedule.Config{},
+
+		SettingsRotation: rotation.Config{},
 	}
 }
 
@@ -49,7 +66,65 @@ func (l *League) Scheduler() schedule.Scheduler {
 	return s
 }
 
+// Rotation returns the settings rotation of the League, the configuration is
+// validated when the League is saved.
+func (l *League) Rotation() rotation.Rotation {
+	r, _ := rotation.New(l.SettingsRotation)
+	return r
+}
+
+// FallbackGeneratorsList returns the FallbackGenerators as a slice.
+func (l *League) FallbackGeneratorsList() []string {
+	return splitCommaList(l.FallbackGenerators)
+}
+
+// SettingsVoteOptionsList returns the SettingsVoteOptions as a slice.
+func (l *League) SettingsVoteOptionsList() []string {
+	return splitCommaList(l.SettingsVoteOptions)
+}
+
+// HasSettingsVote returns true if runners can vote for the League sessions
+// settings (tpl helper).
+func (l *League) HasSettingsVote() bool {
+	return len(l.SettingsVoteOptionsList()) > 0
+}
+
+func splitCommaList(str string) []string {
+	var ret []string
+	for _, v := range strings.Split(str, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
+
+// validate ensures the presets referenced by all the settings the League can
+// use exist.
+func (l *League) validate(tx *sqlx.Tx) error {
+	if err := validateSettingsPresetRefs(tx, l.Settings); err != nil {
+		return err
+	}
+
+	r, err := rotation.New(l.SettingsRotation)
+	if err != nil {
+		return err
+	}
+	for _, v := range append(r.All(), l.SettingsVoteOptionsList()...) {
+		if err := validateSettingsPresetRefs(tx, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (l *League) insert(tx *sqlx.Tx) error {
+	if err := l.validate(tx); err != nil {
+		return err
+	}
+
 	query, args, err := squirrel.Insert("League").SetMap(squirrel.Eq{
 		"ID":        l.ID,
 		"CreatedAt": l.CreatedAt,
@@ -60,6 +135,9 @@ func (l *League) insert(tx *sqlx.Tx) error {
 		"Settings":  l.Settings,
 		"Schedule":  l.Schedule,
 
+		"SettingsRotation":         l.SettingsRotation,
+		"SettingsVoteOptions":      l.SettingsVoteOptions,
+		"FallbackGenerators":       l.FallbackGenerators,
 		"AnnounceDiscordChannelID": l.AnnounceDiscordChannelID,
 	}).ToSql()
 	if err != nil {
@@ -74,6 +152,10 @@ func (l *League) insert(tx *sqlx.Tx) error {
 }
 
 func (l *League) update(tx *sqlx.Tx) error {
+	if err := l.validate(tx); err != nil {
+		return err
+	}
+
 	query, args, err := squirrel.Update("League").SetMap(squirrel.Eq{
 		"GameID":    l.GameID,
 		"Generator": l.Generator,
@@ -82,6 +164,9 @@ func (l *League) update(tx *sqlx.Tx) error {
 		"Settings":  l.Settings,
 		"Schedule":  l.Schedule,
 
+		"SettingsRotation":         l.SettingsRotation,
+		"SettingsVoteOptions":      l.SettingsVoteOptions,
+		"FallbackGenerators":       l.FallbackGenerators,
 		"AnnounceDiscordChannelID": l.AnnounceDiscordChannelID,
 	}).Where("League.ID = ?", l.ID).ToSql()
 	if err != nil {
@@ -92,7 +177,8 @@ func (l *League) update(tx *sqlx.Tx) error {
 		return err
 	}
 
-	return nil
+	// Pre-generated seeds are useless if the generator or settings changed.
+	return invalidateSeedPool(tx, *l)
 }
 
 func getLeagues(tx *sqlx.Tx) ([]League, error) {
@@ -134,6 +220,11 @@ func deleteLeague(tx *sqlx.Tx, id util.UUIDAsBlob) error {
 	queries := []string{
 		"DELETE FROM PlayerRatingHistory WHERE LeagueID = ?",
 		"DELETE FROM PlayerRating WHERE LeagueID = ?",
+		"DELETE FROM PooledSeed WHERE LeagueID = ?",
+		"DELETE FROM SeedLocation WHERE LeagueID = ?",
+		"DELETE FROM LeagueStats WHERE LeagueID = ?",
+		"DELETE FROM SettingsVote WHERE MatchSessionID IN (" +
+			"SELECT MatchSession.ID FROM MatchSession WHERE MatchSession.LeagueID = ?)",
 		"DELETE FROM MatchEntry WHERE MatchID IN (" +
 			"SELECT Match.ID FROM Match WHERE Match.LeagueID = ?)",
 		"DELETE FROM Match WHERE LeagueID = ?",
@@ -0,0 +1,84 @@
+package back // nolint:testpackage
+
+import (
+	"encoding/json"
+	"kaepora/internal/back/rotation"
+	"kaepora/internal/back/schedule"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestLeagueSettingsRotation(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	league, err := back.GetLeagueByShortcode("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hours := schedule.NewDayOfWeekScheduler()
+	hours.SetAll([]string{"20:00 UTC"})
+	payload, err := json.Marshal(hours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	league.Schedule = schedule.Config{Type: schedule.TypeDayOfWeek, Payload: payload}
+	league.SettingsRotation = rotation.Config{Type: "nope"}
+	if err := back.UpdateLeague(league); err == nil {
+		t.Error("expected an invalid rotation to be rejected")
+	}
+
+	league.SettingsRotation = rotation.Config{
+		Type:    rotation.TypeRoundRobin,
+		Payload: []byte(`{"Settings": ["a.json", "b.json"]}`),
+	}
+	if err := back.UpdateLeague(league); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := back.createNextScheduledMatchSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	notifs := dispatchAllNotifications(t, back)
+	if len(notifs) != 1 {
+		t.Fatalf("expected one announcement, got %d notifications", len(notifs))
+	}
+	if payload := notifs[0].Payload.(*MatchSessionStatusUpdatePayload); payload.Settings != "a.json" {
+		t.Errorf("expected the announcement to contain the settings, got %q", payload.Settings)
+	}
+
+	var session MatchSession
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		session, err = getNextMatchSessionForLeague(tx, league.ID)
+		if err != nil {
+			return err
+		}
+
+		match, err := NewMatch(tx, session, "seed")
+		if err != nil {
+			return err
+		}
+		if match.Settings != "a.json" {
+			t.Errorf("expected match to use the session settings, got %s", match.Settings)
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	next := session.StartDate.Time()
+	settings, err := back.GetScheduledSettings(league, []time.Time{
+		next, next.Add(24 * time.Hour), next.Add(48 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a.json", "b.json", "a.json"}; !reflect.DeepEqual(expected, settings) {
+		t.Errorf("expected %v, got %v", expected, settings)
+	}
+}
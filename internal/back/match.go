@@ -2,6 +2,7 @@ package back
 
 import (
 	"fmt"
+	"kaepora/internal/generator/oot"
 	"kaepora/internal/util"
 	"time"
 
@@ -30,6 +31,14 @@ type Match struct {
 	GeneratorState []byte        // arbitrary JSON, depends on Generator
 	SeedPatch      []byte        // arbitrary binary, depends on Generator, hopefully already compressed
 
+	// When a blob store is configured SpoilerLog and SeedPatch are stored
+	// there under these keys and are empty in the DB, see loadMatchBlobs.
+	SpoilerLogKey string
+	SeedPatchKey  string
+
+	// Difficulty is computed from the spoiler log when the seed is generated.
+	Difficulty oot.SeedDifficulty
+
 	// Two entries, one per Player.
 	Entries []MatchEntry `db:"-"`
 }
@@ -46,7 +55,7 @@ func NewMatch(tx *sqlx.Tx, session MatchSession, seed string) (Match, error) {
 		LeagueID:       session.LeagueID,
 		MatchSessionID: session.ID,
 		Generator:      league.Generator,
-		Settings:       league.Settings,
+		Settings:       session.SettingsOrDefault(league),
 		Seed:           seed,
 	}, nil
 }
@@ -77,6 +86,12 @@ func (m *Match) LosingEntry() MatchEntry {
 	return m.Entries[1]
 }
 
+// HasSeedPatch returns true if a patch was generated for the match, wherever
+// it is stored.
+func (m *Match) HasSeedPatch() bool {
+	return len(m.SeedPatch) > 0 || m.SeedPatchKey != ""
+}
+
 func (m *Match) end() {
 	m.EndedAt = util.NewNullTimeAsTimestamp(time.Now())
 }
@@ -104,6 +119,10 @@ func (m *Match) insert(tx *sqlx.Tx) error {
 		"Seed":           m.Seed,
 		"SpoilerLog":     m.SpoilerLog,
 		"GeneratorState": m.GeneratorState,
+		"SeedPatch":      m.SeedPatch,
+		"SpoilerLogKey":  m.SpoilerLogKey,
+		"SeedPatchKey":   m.SeedPatchKey,
+		"Difficulty":     m.Difficulty,
 	}).ToSql()
 	if err != nil {
 		return err
@@ -123,8 +142,13 @@ func (m *Match) update(tx *sqlx.Tx) error {
 		"StartedAt": m.StartedAt,
 		"EndedAt":   m.EndedAt,
 
+		"Generator":      m.Generator,
 		"SpoilerLog":     m.SpoilerLog,
 		"GeneratorState": m.GeneratorState,
+		"SeedPatch":      m.SeedPatch,
+		"SpoilerLogKey":  m.SpoilerLogKey,
+		"SeedPatchKey":   m.SeedPatchKey,
+		"Difficulty":     m.Difficulty,
 	}).Where("Match.ID = ?", m.ID).ToSql()
 	if err != nil {
 		return err
@@ -41,6 +41,54 @@ type MatchSession struct {
 	StartDate util.TimeAsDateTimeTZ
 	Status    MatchSessionStatus
 	PlayerIDs util.UUIDArrayAsJSON // sorted by join date asc
+
+	// Settings picked by the League SettingsRotation when the session was
+	// created, empty if the League Settings are to be used.
+	Settings string
+}
+
+// SettingsOrDefault returns the settings the session matches will use.
+func (s *MatchSession) SettingsOrDefault(league League) string {
+	if s.Settings != "" {
+		return s.Settings
+	}
+
+	return league.Settings
+}
+
+// nextSessionSettings returns the settings the next session of the League
+// will most likely be played with: the ones of the upcoming session with its
+// leading vote if any, or the ones the rotation will pick for the next
+// scheduled session.
+func nextSessionSettings(tx *sqlx.Tx, league League) (string, error) {
+	session, err := getNextMatchSessionForLeague(tx, league.ID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return "", err
+		}
+
+		next := league.Scheduler().Next()
+		if next.IsZero() {
+			return league.Settings, nil
+		}
+
+		index, err := countMatchSessionsBefore(tx, league.ID, next)
+		if err != nil {
+			return "", err
+		}
+		session = NewMatchSession(league.ID, next)
+		session.Settings = league.Rotation().Pick(next, index)
+	}
+
+	vote, ok, err := leadingSettingsVote(tx, session, league)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return vote.Settings, nil
+	}
+
+	return session.SettingsOrDefault(league), nil
 }
 
 // IsJoinable returns true if players can join the session (tpl helper).
@@ -86,6 +134,21 @@ func getMatchSessionByStartDate(tx *sqlx.Tx, leagueID util.UUIDAsBlob, startDate
 	return ret, nil
 }
 
+// countMatchSessionsBefore returns the number of sessions of a League that
+// start before the given date.
+func countMatchSessionsBefore(tx *sqlx.Tx, leagueID util.UUIDAsBlob, startDate time.Time) (int, error) {
+	var ret int
+	query := `
+        SELECT COUNT(*) FROM MatchSession
+        WHERE MatchSession.LeagueID = ? AND
+              DATETIME(MatchSession.StartDate) < DATETIME(?)`
+	if err := tx.Get(&ret, query, leagueID, util.TimeAsDateTimeTZ(startDate)); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
 func getMatchSessionByID(tx *sqlx.Tx, id util.UUIDAsBlob) (MatchSession, error) {
 	var ret MatchSession
 	query := `SELECT * FROM MatchSession WHERE MatchSession.ID = ? LIMIT 1`
@@ -238,6 +301,7 @@ func (s *MatchSession) insert(tx *sqlx.Tx) error {
 		"StartDate": s.StartDate,
 		"Status":    s.Status,
 		"PlayerIDs": s.PlayerIDs,
+		"Settings":  s.Settings,
 	}).ToSql()
 	if err != nil {
 		return err
@@ -319,6 +383,7 @@ func (s *MatchSession) update(tx *sqlx.Tx) error {
 		"StartDate": s.StartDate,
 		"Status":    s.Status,
 		"PlayerIDs": s.PlayerIDs,
+		"Settings":  s.Settings,
 	}).
 		Where("MatchSession.ID = ?", s.ID).
 		ToSql()
@@ -401,34 +466,3 @@ func getActiveSessionsForLeagueID(tx *sqlx.Tx, leagueID util.UUIDAsBlob) ([]Matc
 
 	return ret, nil
 }
-
-func (b *Back) MapMatchSessions(
-	shortcode string,
-	cb func(MatchSession) error,
-) error {
-	return b.transaction(func(tx *sqlx.Tx) error {
-		league, err := getLeagueByShortCode(tx, shortcode)
-		if err != nil {
-			return err
-		}
-
-		rows, err := tx.Queryx(`SELECT * FROM MatchSession WHERE LeagueID = ?`, league.ID)
-		if err != nil {
-			return err
-		}
-		defer rows.Close()
-
-		for rows.Next() {
-			var m MatchSession
-			if err := rows.StructScan(&m); err != nil {
-				return err
-			}
-
-			if err := cb(m); err != nil {
-				return err
-			}
-		}
-
-		return rows.Err()
-	})
-}
@@ -9,8 +9,6 @@ import (
 	"kaepora/internal/generator/oot"
 	"kaepora/internal/util"
 	"log"
-	"text/tabwriter"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -21,6 +19,7 @@ type NotificationRecipientType int
 const (
 	NotificationRecipientTypeDiscordChannel NotificationRecipientType = 0
 	NotificationRecipientTypeDiscordUser    NotificationRecipientType = 1
+	NotificationRecipientTypeWebhook        NotificationRecipientType = 2 // Recipient is the Webhook ID, sent by the Back
 )
 
 type NotificationType int
@@ -35,6 +34,8 @@ const (
 	NotificationTypeMatchSessionRecap
 	NotificationTypeSpoilerLog
 	NotificationTypeLeagueLeaderboardUpdate
+	NotificationTypeMatchSeedFallback
+	NotificationTypeMatchSeedFailure
 )
 
 type NotificationFile struct {
@@ -43,13 +44,16 @@ type NotificationFile struct {
 	Reader      io.Reader
 }
 
+// Notification is an event sent to a player or a channel, Payload is a
+// pointer to the payload struct of the notification Type (eg.
+// *MatchEndPayload for NotificationTypeMatchEnd) that front-ends render in
+// their own format.
 type Notification struct {
 	RecipientType NotificationRecipientType
 	Recipient     string
 	Type          NotificationType
+	Payload       interface{}
 	Files         []NotificationFile
-
-	body bytes.Buffer
 }
 
 func (n *Notification) SetDiscordUserRecipient(userID string) {
@@ -57,35 +61,14 @@ func (n *Notification) SetDiscordUserRecipient(userID string) {
 	n.RecipientType = NotificationRecipientTypeDiscordUser
 }
 
-func (n *Notification) Printf(str string, args ...interface{}) (int, error) {
-	return fmt.Fprintf(&n.body, str, args...)
-}
-
-func (n *Notification) Print(args ...interface{}) (int, error) {
-	return fmt.Fprint(&n.body, args...)
-}
-
-func (n *Notification) Read(p []byte) (int, error) {
-	return n.body.Read(p)
-}
-
-func (n *Notification) Write(p []byte) (int, error) {
-	return n.body.Write(p)
-}
-
-func (n *Notification) Reset() {
-	n.body.Reset()
-	n.Files = nil
-}
-
 func NotificationTypeName(typ NotificationType) string {
 	switch typ {
 	case NotificationTypeMatchSessionStatusUpdate:
 		return "MatchSessionStatusUpdate"
 	case NotificationTypeLeagueLeaderboardUpdate:
-		return "TypeLeagueLeaderboardUpdate"
+		return "LeagueLeaderboardUpdate"
 	case NotificationTypeMatchSessionCountdown:
-		return "TypeMatchSessionCountdown:"
+		return "MatchSessionCountdown"
 	case NotificationTypeMatchSessionOddKick:
 		return "MatchSessionOddKick"
 	case NotificationTypeMatchSessionEmpty:
@@ -98,17 +81,44 @@ func NotificationTypeName(typ NotificationType) string {
 		return "MatchSeed"
 	case NotificationTypeMatchEnd:
 		return "MatchEnd"
+	case NotificationTypeMatchSeedFallback:
+		return "MatchSeedFallback"
+	case NotificationTypeMatchSeedFailure:
+		return "MatchSeedFailure"
 	default:
 		return "invalid"
 	}
 }
 
+// AllNotificationTypes returns every NotificationType.
+func AllNotificationTypes() []NotificationType {
+	ret := make([]NotificationType, 0, NotificationTypeMatchSeedFailure+1)
+	for typ := NotificationTypeMatchSessionStatusUpdate; typ <= NotificationTypeMatchSeedFailure; typ++ {
+		ret = append(ret, typ)
+	}
+
+	return ret
+}
+
+// ParseNotificationType is the reverse of NotificationTypeName.
+func ParseNotificationType(name string) (NotificationType, error) {
+	for _, typ := range AllNotificationTypes() {
+		if NotificationTypeName(typ) == name {
+			return typ, nil
+		}
+	}
+
+	return 0, util.ErrPublic(fmt.Sprintf("unknown notification type: %s", name))
+}
+
 func NotificationRecipientTypeName(typ NotificationRecipientType) string {
 	switch typ {
 	case NotificationRecipientTypeDiscordChannel:
 		return "DiscordChannel"
 	case NotificationRecipientTypeDiscordUser:
 		return "DiscordUser"
+	case NotificationRecipientTypeWebhook:
+		return "Webhook"
 	default:
 		return "invalid"
 	}
@@ -129,27 +139,24 @@ func (n *Notification) String() string {
 		fmt.Fprintf(&buf, ", %d file(s)", len)
 	}
 
-	// HACK: Ensure its on one line (and safe to print)
-	content, _ := json.Marshal(n.body.String())
-	fmt.Fprintf(&buf, ", contents: %s", string(content))
+	payload, _ := json.Marshal(n.Payload)
+	fmt.Fprintf(&buf, ", payload: %s", string(payload))
 
 	return buf.String()
 }
 
-func (b *Back) sendOddKickNotification(player Player) {
-	notif := Notification{
+func (b *Back) sendOddKickNotification(tx *sqlx.Tx, player Player) error {
+	payload := &MatchSessionOddKickPayload{Player: player.Name}
+	if err := queueNotification(tx, Notification{
 		RecipientType: NotificationRecipientTypeDiscordUser,
 		Recipient:     player.DiscordID.String,
 		Type:          NotificationTypeMatchSessionOddKick,
+		Payload:       payload,
+	}); err != nil {
+		return err
 	}
 
-	notif.Printf(
-		"Sorry %s, but there was an odd number of players and you were the last person to join.\n"+
-			"You have been kicked out of the race, don't worry this won't affect your ranking.\n",
-		player.Name,
-	)
-
-	b.notifications <- notif
+	return queueWebhookEvent(tx, NotificationTypeMatchSessionOddKick, payload)
 }
 
 func (b *Back) sendMatchSessionEmptyNotification(
@@ -162,18 +169,18 @@ func (b *Back) sendMatchSessionEmptyNotification(
 		return err
 	}
 
-	notif := Notification{
+	payload := &MatchSessionEmptyPayload{League: league.ShortCode}
+	if err := queueNotification(tx, Notification{
 		RecipientType: NotificationRecipientTypeDiscordChannel,
 		Recipient:     league.AnnounceDiscordChannelID.String,
 		Type:          NotificationTypeMatchSessionEmpty,
+		Payload:       payload,
+	}); err != nil {
+		return err
+	}
+	if err := queueWebhookEvent(tx, NotificationTypeMatchSessionEmpty, payload); err != nil {
+		return err
 	}
-
-	notif.Printf(
-		"The race for league `%s` is closed, you can no longer join.\n"+
-			"There was not enough players to start the race.\n",
-		league.ShortCode,
-	)
-	b.notifications <- notif
 
 	for _, v := range playerIDs {
 		var e []error
@@ -199,22 +206,14 @@ func (b *Back) sendMatchSessionEmptyPlayerNotification(
 		return err
 	}
 
-	notif := Notification{
+	return queueNotification(tx, Notification{
 		RecipientType: NotificationRecipientTypeDiscordUser,
 		Recipient:     player.DiscordID.String,
 		Type:          NotificationTypeMatchSessionEmpty,
-	}
-
-	notif.Printf(
-		"The race for league `%s` has closed, there was not enough players to start the race.",
-		shortcode,
-	)
-
-	b.notifications <- notif
-	return nil
+		Payload:       &MatchSessionEmptyPayload{League: shortcode, ToPlayer: true},
+	})
 }
 
-// nolint:funlen
 func (b *Back) sendMatchEndNotification(
 	tx *sqlx.Tx,
 	selfEntry MatchEntry,
@@ -226,84 +225,49 @@ func (b *Back) sendMatchEndNotification(
 		return err
 	}
 
-	notif := Notification{
-		RecipientType: NotificationRecipientTypeDiscordUser,
-		Recipient:     player.DiscordID.String,
-		Type:          NotificationTypeMatchEnd,
-	}
-
-	notif.Printf("%s, your race against %s has ended.\n", player.Name, opponent.Name)
-
-	if selfEntry.HasEnded() { // nolint:nestif
-		start, end := selfEntry.StartedAt.Time.Time(), selfEntry.EndedAt.Time.Time()
-		if !start.IsZero() {
-			delta := end.Sub(start).Round(time.Second)
-			if selfEntry.Status == MatchEntryStatusForfeit {
-				notif.Printf("You forfeited your race after %s.\n", delta)
-			} else if selfEntry.Status == MatchEntryStatusFinished {
-				notif.Printf("You completed your race in %s.\n", delta)
-			}
-		} else if selfEntry.Status == MatchEntryStatusForfeit {
-			notif.Print("You forfeited before the race started.\n")
-		}
-	} else {
+	if !selfEntry.HasEnded() {
 		log.Print("error: unreachable self entry not ended")
-		notif.Printf("You are still running and should _never_ see this message.")
 	}
 
-	if opponentEntry.HasEnded() { // nolint:nestif
-		start, end := opponentEntry.StartedAt.Time.Time(), opponentEntry.EndedAt.Time.Time()
-		if !start.IsZero() {
-			delta := end.Sub(start).Round(time.Second)
-			if opponentEntry.Status == MatchEntryStatusForfeit {
-				notif.Printf("%s forfeited after %s.\n", opponent.Name, delta)
-			} else if opponentEntry.Status == MatchEntryStatusFinished {
-				notif.Printf("%s completed their race in %s.\n", opponent.Name, delta)
-			}
-		} else if opponentEntry.Status == MatchEntryStatusForfeit {
-			notif.Printf("%s forfeited before the race started.\n", opponent.Name)
-		}
-	} else {
-		notif.Printf("%s is still running.\n", opponent.Name)
-	}
-
-	if selfEntry.Outcome == MatchEntryOutcomeWin { // nolint:gocritic
-		notif.Print("**You won!**\n")
-	} else if opponentEntry.HasEnded() {
-		if selfEntry.Outcome == MatchEntryOutcomeDraw {
-			notif.Print("**The race is a draw.**\n")
-		} else if selfEntry.Outcome == MatchEntryOutcomeLoss {
-			notif.Printf("**%s wins.**\n", opponent.Name)
-		}
-	} else {
-		// If we're here it means we forfeited and the opponent is still running.
-		notif.Printf("You can only hope your opponent forfeits now.")
-	}
-
-	if opponent.StreamURL != "" {
-		notif.Printf("Your opponent stream: <%s>\n", opponent.StreamURL)
-	}
-
-	b.notifications <- notif
-	return nil
+	return queueNotification(tx, Notification{
+		RecipientType: NotificationRecipientTypeDiscordUser,
+		Recipient:     player.DiscordID.String,
+		Type:          NotificationTypeMatchEnd,
+		Payload: &MatchEndPayload{
+			Self:              newNotificationEntry(selfEntry, player.Name),
+			Opponent:          newNotificationEntry(opponentEntry, opponent.Name),
+			OpponentStreamURL: opponent.StreamURL,
+		},
+	})
 }
 
 func (b *Back) sendMatchSeedNotification(
+	tx *sqlx.Tx,
 	session MatchSession,
 	url string,
 	out generator.Output,
 	p1, p2 Player,
-) {
+) error {
 	name := fmt.Sprintf(
 		"seed_%s.zpf",
 		session.StartDate.Time().Format("2006-01-02_15h04"),
 	)
 
-	send := func(player Player) {
+	payload := &MatchSeedPayload{
+		SeedURL:   url,
+		Hash:      hashFromSpoilerLog(out.SpoilerLog),
+		StartDate: session.StartDate.Time(),
+	}
+	if err := setSettingsPatch(payload, out.State); err != nil {
+		log.Printf("warning: unable to send settings info: %v", err)
+	}
+
+	send := func(player Player) error {
 		notif := Notification{
 			RecipientType: NotificationRecipientTypeDiscordUser,
 			Recipient:     player.DiscordID.String,
 			Type:          NotificationTypeMatchSeed,
+			Payload:       payload,
 		}
 
 		if url == "" {
@@ -312,40 +276,21 @@ func (b *Back) sendMatchSeedNotification(
 				ContentType: "application/zlib",
 				Reader:      bytes.NewReader(out.SeedPatch),
 			}}
-
-			notif.Print(
-				"Here is your seed in _Patch_ format. " +
-					"You can use <https://ootrandomizer.com/generator> to patch your ROM.\n",
-			)
-		} else {
-			notif.Printf("Here is your seed: <%s>\n", url)
-		}
-
-		if hash := hashFromSpoilerLog(out.SpoilerLog); hash != "" {
-			notif.Print("Your seed hash is: **", hash, "**\n")
-		}
-
-		if !session.StartDate.Time().IsZero() {
-			notif.Printf(
-				"Your race starts in %s, **do not explore the seed before the match starts**.\n",
-				time.Until(session.StartDate.Time()).Round(time.Second),
-			)
 		}
 
-		if err := maybeWriteSettingsPatchInfo(&notif, out.State); err != nil {
-			log.Printf("warning: unable to send settings info: %v", err)
-		}
+		return queueNotification(tx, notif)
+	}
 
-		b.notifications <- notif
+	if err := send(p1); err != nil {
+		return err
 	}
 
-	send(p1)
-	send(p2)
+	return send(p2)
 }
 
-// maybeWriteSettingsPatchInfo sends OOTR-specific documentation about the
-// settings used to generate the seed (used in shuffled settings).
-func maybeWriteSettingsPatchInfo(w io.Writer, stateJSON []byte) error {
+// setSettingsPatch copies the OOTR-specific settings used to generate the
+// seed (used in shuffled settings) to the payload.
+func setSettingsPatch(payload *MatchSeedPayload, stateJSON []byte) error {
 	if stateJSON == nil {
 		log.Printf("debug: got a nil state, not sending settings patch info")
 		return nil
@@ -355,32 +300,7 @@ func maybeWriteSettingsPatchInfo(w io.Writer, stateJSON []byte) error {
 	if err := json.Unmarshal(stateJSON, &state); err != nil {
 		return fmt.Errorf("unable to unmarshal state: %w", err)
 	}
-	if len(state.SettingsPatch) == 0 {
-		return nil
-	}
-
-	doc, err := LoadSettingsDocumentation("en") // HARDCODED every bot message is in English.
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprint(w, "Seed settings:\n")
-
-	for k, v := range state.SettingsPatch {
-		setting := doc[k]
-		value := setting.GetValueEntry(v)
-
-		if setting.Title == "" {
-			log.Printf("warning: no title for setting %s", k)
-			continue
-		}
-		if value.Title == "" {
-			log.Printf("warning: no title for value %s = %v", k, v)
-			continue
-		}
-
-		fmt.Fprintf(w, "  - %s: %s\n", setting.Title, value.Title)
-	}
+	payload.SettingsPatch = state.SettingsPatch
 
 	return nil
 }
@@ -391,53 +311,35 @@ func (b *Back) sendSessionStatusUpdateNotification(tx *sqlx.Tx, session MatchSes
 		return err
 	}
 
-	notif := Notification{
+	payload := &MatchSessionStatusUpdatePayload{
+		League:    league.ShortCode,
+		Status:    session.Status,
+		StartDate: session.StartDate.Time(),
+		Settings:  session.SettingsOrDefault(league),
+	}
+
+	switch session.Status { // nolint:exhaustive
+	case MatchSessionStatusJoinable:
+		payload.VoteOptions = league.SettingsVoteOptionsList()
+	case MatchSessionStatusPreparing:
+		payload.Contestants = len(session.PlayerIDs) - (len(session.PlayerIDs) % 2)
+		if league.HasSettingsVote() {
+			if payload.VoteResults, err = getSettingsVoteResults(tx, session, league); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := queueNotification(tx, Notification{
 		RecipientType: NotificationRecipientTypeDiscordChannel,
 		Recipient:     league.AnnounceDiscordChannelID.String,
 		Type:          NotificationTypeMatchSessionStatusUpdate,
+		Payload:       payload,
+	}); err != nil {
+		return err
 	}
 
-	switch session.Status {
-	case MatchSessionStatusWaiting:
-		notif.Printf(
-			"The next race for league `%s` has been scheduled for %s (in %s)",
-			league.ShortCode,
-			util.Datetime(session.StartDate),
-			time.Until(session.StartDate.Time()).Round(time.Second),
-		)
-	case MatchSessionStatusJoinable:
-		notif.Printf(
-			"The race for league `%s` can now be joined! The race starts at %s (in %s).\n"+
-				"You can join using `!join %s`.",
-			league.ShortCode,
-			util.Datetime(session.StartDate),
-			time.Until(session.StartDate.Time()).Round(time.Second),
-			league.ShortCode,
-		)
-	case MatchSessionStatusPreparing:
-		notif.Printf(
-			"The race for league `%s` has begun preparations, you can no longer join. "+
-				"Seeds will soon be sent to the %d contestants.\n"+
-				"The race starts at %s (in %s). Watch this channel for the official go.",
-			league.ShortCode,
-			len(session.PlayerIDs)-(len(session.PlayerIDs)%2),
-			util.Datetime(session.StartDate),
-			time.Until(session.StartDate.Time()).Round(time.Second),
-		)
-	case MatchSessionStatusInProgress:
-		notif.Printf(
-			"The race for league `%s` **starts now**. Good luck and have fun!",
-			league.ShortCode,
-		)
-	case MatchSessionStatusClosed:
-		notif.Printf(
-			"All players have finished their last `%s` race, rankings have been updated.",
-			league.ShortCode,
-		)
-	}
-
-	b.notifications <- notif
-	return nil
+	return queueWebhookEvent(tx, NotificationTypeMatchSessionStatusUpdate, payload)
 }
 
 func (b *Back) sendSessionCountdownNotification(tx *sqlx.Tx, session MatchSession) error {
@@ -446,20 +348,20 @@ func (b *Back) sendSessionCountdownNotification(tx *sqlx.Tx, session MatchSessio
 		return err
 	}
 
-	notif := Notification{
+	payload := &MatchSessionCountdownPayload{
+		League:    league.ShortCode,
+		StartDate: session.StartDate.Time(),
+	}
+	if err := queueNotification(tx, Notification{
 		RecipientType: NotificationRecipientTypeDiscordChannel,
 		Recipient:     league.AnnounceDiscordChannelID.String,
-		Type:          NotificationTypeMatchSessionStatusUpdate,
+		Type:          NotificationTypeMatchSessionCountdown,
+		Payload:       payload,
+	}); err != nil {
+		return err
 	}
 
-	notif.Printf(
-		"The next race for league `%s` starts in %s.",
-		league.ShortCode,
-		time.Until(session.StartDate.Time()).Round(time.Second),
-	)
-
-	b.notifications <- notif
-	return nil
+	return queueWebhookEvent(tx, NotificationTypeMatchSessionCountdown, payload)
 }
 
 func (b *Back) sendLeaderboardUpdateNotification(
@@ -471,12 +373,6 @@ func (b *Back) sendLeaderboardUpdateNotification(
 		return err
 	}
 
-	notif := Notification{
-		RecipientType: NotificationRecipientTypeDiscordChannel,
-		Recipient:     league.AnnounceDiscordChannelID.String,
-		Type:          NotificationTypeLeagueLeaderboardUpdate,
-	}
-
 	top, err := b.getLeaderboardForShortcode(tx, league.ShortCode, DeviationThreshold)
 	if err != nil {
 		return err
@@ -489,14 +385,21 @@ func (b *Back) sendLeaderboardUpdateNotification(
 		return nil
 	}
 
-	notif.Printf("Top players for league `%s`:\n```\n", league.ShortCode)
+	payload := &LeagueLeaderboardUpdatePayload{League: league.ShortCode}
 	for i := range top {
-		notif.Printf(" %2.d. %s\n", i+1, top[i].PlayerName)
+		payload.Top = append(payload.Top, top[i].PlayerName)
 	}
-	notif.Print("```\n")
 
-	b.notifications <- notif
-	return nil
+	if err := queueNotification(tx, Notification{
+		RecipientType: NotificationRecipientTypeDiscordChannel,
+		Recipient:     league.AnnounceDiscordChannelID.String,
+		Type:          NotificationTypeLeagueLeaderboardUpdate,
+		Payload:       payload,
+	}); err != nil {
+		return err
+	}
+
+	return queueWebhookEvent(tx, NotificationTypeLeagueLeaderboardUpdate, payload)
 }
 
 type RecapScope int
@@ -528,35 +431,31 @@ func (b *Back) sendSessionRecapNotification(
 		notif.SetDiscordUserRecipient(*toDiscordUserID)
 	}
 
-	notif.Printf("Results for `%s` race started at %s:\n```\n", league.ShortCode, util.Datetime(session.StartDate))
-	known, unknown := writeResultsTable(tx, &notif, matches, scope)
-	notif.Print("```\n")
+	payload := &MatchSessionRecapPayload{
+		League:    league.ShortCode,
+		StartDate: session.StartDate.Time(),
+	}
+	payload.Rows, payload.Unknown = getRecapRows(tx, matches, scope)
+	if payload.Unknown == 0 {
+		payload.URL = fmt.Sprintf("%s/en/sessions/%s", b.config.BaseURL(), session.ID)
+	}
+	notif.Payload = payload
 
-	if known == 0 {
-		notif.Reset()
+	if err := queueNotification(tx, notif); err != nil {
+		return err
 	}
 
-	if unknown > 0 {
-		notif.Printf("There are still %d race(s) in progress.\n", unknown)
-		notif.Printf("You can get an up to date recap with `!recap %s`.", league.ShortCode)
-	} else {
-		notif.Printf("Get the seeds and spoiler logs on <%s/en/sessions/%s>", b.config.BaseURL(), session.ID)
+	// Only the public recap is an event, private recaps are sent on demand.
+	if scope != RecapScopePublic || toDiscordUserID != nil {
+		return nil
 	}
 
-	b.notifications <- notif
-	return nil
+	return queueWebhookEvent(tx, NotificationTypeMatchSessionRecap, payload)
 }
 
-// writeResultsTable is an helper for sendSessionRecapNotification.
-func writeResultsTable(
-	tx *sqlx.Tx,
-	w io.Writer,
-	matches []Match,
-	scope RecapScope,
-) (known, unknown int) {
-	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(table, "Player 1\t\tvs\tPlayer 2\t")
-
+// getRecapRows is an helper for sendSessionRecapNotification, it returns the
+// matches visible in the given scope and the number of hidden ones.
+func getRecapRows(tx *sqlx.Tx, matches []Match, scope RecapScope) (rows []RecapRow, unknown int) {
 	for _, match := range matches {
 		if scope != RecapScopeAdmin {
 			if !match.Entries[0].HasEnded() && !match.Entries[1].HasEnded() {
@@ -570,48 +469,16 @@ func writeResultsTable(
 			}
 		}
 
-		wrap0, name0, duration0 := entryDetails(tx, match.Entries[0])
-		wrap1, name1, duration1 := entryDetails(tx, match.Entries[1])
-		fmt.Fprint(
-			table,
-			wrap0, name0, wrap0, "\t", duration0, "\t\t",
-			wrap1, name1, wrap1, "\t", duration1, "\n",
-		)
-		known++
-	}
-
-	table.Flush()
-	return known, unknown
-}
-
-// entryDetails is a formatting helper for sendSessionRecapNotification.
-func entryDetails(tx *sqlx.Tx, entry MatchEntry) (wrap string, name string, duration string) {
-	if entry.HasWon() {
-		wrap = "*"
-	}
-
-	player, _ := getPlayerByID(tx, entry.PlayerID)
-	name = player.Name
-
-	switch entry.Status {
-	case MatchEntryStatusWaiting:
-		duration = "not started"
-	case MatchEntryStatusInProgress:
-		duration = "in progress"
-	case MatchEntryStatusForfeit:
-		if entry.StartedAt.Time.Time().IsZero() {
-			duration = "forfeit (before start)"
-			break
+		var row RecapRow
+		for k := range row.Entries {
+			player, _ := getPlayerByID(tx, match.Entries[k].PlayerID)
+			row.Entries[k] = newNotificationEntry(match.Entries[k], player.Name)
 		}
-
-		delta := entry.EndedAt.Time.Time().Sub(entry.StartedAt.Time.Time()).Round(time.Second)
-		duration = "forfeit (" + delta.String() + ")"
-	case MatchEntryStatusFinished:
-		delta := entry.EndedAt.Time.Time().Sub(entry.StartedAt.Time.Time()).Round(time.Second)
-		duration = delta.String()
+		row.Difficulty = match.Difficulty
+		rows = append(rows, row)
 	}
 
-	return
+	return rows, unknown
 }
 
 func (b *Back) sendRawSpoilerLogNotification(player Player, seed string, spoilerLog util.ZLIBBlob) {
@@ -619,31 +486,92 @@ func (b *Back) sendRawSpoilerLogNotification(player Player, seed string, spoiler
 		RecipientType: NotificationRecipientTypeDiscordUser,
 		Recipient:     player.DiscordID.String,
 		Type:          NotificationTypeSpoilerLog,
+		Payload: &SpoilerLogPayload{
+			Seed:      seed,
+			Available: len(spoilerLog) > 0,
+		},
 	}
 
-	if len(spoilerLog) == 0 {
-		notif.Printf("There is no spoiler log available for seed `%s`.", seed)
-	} else {
+	if len(spoilerLog) > 0 {
 		notif.Files = []NotificationFile{{
 			Name:        fmt.Sprintf("%s.spoilers.json", seed),
 			ContentType: "application/json",
 			Reader:      spoilerLog.Uncompressed(),
 		}}
-		notif.Printf("Here is the spoiler log for seed `%s`.", seed)
 	}
 
-	b.notifications <- notif
+	b.queueNotificationNow(notif)
 }
 
-func (b *Back) sendSpoilerLogNotification(player Player, matchID util.UUIDAsBlob) {
-	notif := Notification{
+func (b *Back) sendSpoilerLogNotification(tx *sqlx.Tx, player Player, matchID util.UUIDAsBlob) error {
+	return queueNotification(tx, Notification{
 		RecipientType: NotificationRecipientTypeDiscordUser,
 		Recipient:     player.DiscordID.String,
 		Type:          NotificationTypeSpoilerLog,
-	}
+		Payload: &SpoilerLogPayload{
+			URL: fmt.Sprintf("%s/en/matches/%s/spoilers", b.config.BaseURL(), matchID),
+		},
+	})
+}
+
+// sendSeedgenFallbackNotification tells the players and the admins that
+// the seed of a Match was not generated using the League main generator.
+func (b *Back) sendSeedgenFallbackNotification(match Match, used string, p1, p2 Player) {
+	for _, v := range []Player{p1, p2} {
+		b.queueNotificationNow(Notification{
+			RecipientType: NotificationRecipientTypeDiscordUser,
+			Recipient:     v.DiscordID.String,
+			Type:          NotificationTypeMatchSeedFallback,
+			Payload:       &MatchSeedFallbackPayload{},
+		})
+	}
+
+	b.notifyAdmins(NotificationTypeMatchSeedFallback, &MatchSeedFallbackPayload{
+		ForAdmin:      true,
+		MatchID:       match.ID.String(),
+		Generator:     match.Generator,
+		UsedGenerator: used,
+	})
+}
 
-	url := fmt.Sprintf("%s/en/matches/%s/spoilers", b.config.BaseURL(), matchID)
-	notif.Printf("Here is the spoiler log for your seed: <%s>", url)
+// sendSeedgenFailureNotification tells the players and the admins that
+// no seed could be generated for a Match.
+func (b *Back) sendSeedgenFailureNotification(match Match, p1, p2 Player, err error) {
+	for _, v := range []Player{p1, p2} {
+		b.queueNotificationNow(Notification{
+			RecipientType: NotificationRecipientTypeDiscordUser,
+			Recipient:     v.DiscordID.String,
+			Type:          NotificationTypeMatchSeedFailure,
+			Payload:       &MatchSeedFailurePayload{},
+		})
+	}
+
+	b.notifyAdmins(NotificationTypeMatchSeedFailure, &MatchSeedFailurePayload{
+		ForAdmin: true,
+		MatchID:  match.ID.String(),
+		Player1:  p1.Name,
+		Player2:  p2.Name,
+		Error:    err.Error(),
+	})
+}
 
-	b.notifications <- notif
+func (b *Back) sendDevSeedFailureNotification(player Player, seed string) {
+	b.queueNotificationNow(Notification{
+		RecipientType: NotificationRecipientTypeDiscordUser,
+		Recipient:     player.DiscordID.String,
+		Type:          NotificationTypeMatchSeedFailure,
+		Payload:       &MatchSeedFailurePayload{Seed: seed},
+	})
+}
+
+// notifyAdmins sends the same private notification to every admin.
+func (b *Back) notifyAdmins(typ NotificationType, payload interface{}) {
+	for _, id := range b.config.Discord.AdminUserIDs {
+		b.queueNotificationNow(Notification{
+			RecipientType: NotificationRecipientTypeDiscordUser,
+			Recipient:     id,
+			Type:          typ,
+			Payload:       payload,
+		})
+	}
 }
@@ -0,0 +1,368 @@
+package back
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"kaepora/internal/util"
+	"log"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxStatus int
+
+const (
+	OutboxStatusPending OutboxStatus = 0
+	OutboxStatusDead    OutboxStatus = 1 // failed too many times, kept for the admins
+)
+
+const (
+	// outboxMaxAttempts is the number of failed sends after which a
+	// notification is dead-lettered, with the delays below this is roughly
+	// an hour of retries.
+	outboxMaxAttempts = 10
+	outboxBaseDelay   = 10 * time.Second
+	outboxMaxDelay    = 30 * time.Minute
+	outboxBatchSize   = 64
+)
+
+// OutboxNotification is a Notification persisted until it is successfully
+// sent, it is queued in the same transaction as the state change it
+// notifies about so a crash or an unreachable Discord loses nothing.
+type OutboxNotification struct {
+	ID            util.UUIDAsBlob
+	CreatedAt     util.TimeAsTimestamp
+	Type          NotificationType
+	RecipientType NotificationRecipientType
+	Recipient     string
+	Payload       string        // JSON, see NewNotificationPayload
+	Files         util.ZLIBBlob // JSON []outboxFile
+
+	Status        OutboxStatus
+	Attempts      int
+	NextAttemptAt util.TimeAsTimestamp
+	LastError     string
+}
+
+// outboxFile is the persisted form of a NotificationFile.
+type outboxFile struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+func newOutboxNotification(notif Notification) (OutboxNotification, error) {
+	files := make([]outboxFile, 0, len(notif.Files))
+	for _, v := range notif.Files {
+		content, err := ioutil.ReadAll(v.Reader)
+		if err != nil {
+			return OutboxNotification{}, fmt.Errorf("unable to read file %s: %w", v.Name, err)
+		}
+
+		files = append(files, outboxFile{
+			Name:        v.Name,
+			ContentType: v.ContentType,
+			Content:     content,
+		})
+	}
+
+	payload, err := json.Marshal(notif.Payload)
+	if err != nil {
+		return OutboxNotification{}, err
+	}
+
+	encoded, err := json.Marshal(files)
+	if err != nil {
+		return OutboxNotification{}, err
+	}
+	blob, err := util.NewZLIBBlob(encoded)
+	if err != nil {
+		return OutboxNotification{}, err
+	}
+
+	now := util.TimeAsTimestamp(time.Now())
+	return OutboxNotification{
+		ID:            util.NewUUIDAsBlob(),
+		CreatedAt:     now,
+		Type:          notif.Type,
+		RecipientType: notif.RecipientType,
+		Recipient:     notif.Recipient,
+		Payload:       string(payload),
+		Files:         blob,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// Notification rebuilds the Notification to send.
+func (n *OutboxNotification) Notification() (Notification, error) {
+	var files []outboxFile
+	if err := json.NewDecoder(n.Files.Uncompressed()).Decode(&files); err != nil {
+		return Notification{}, fmt.Errorf("unable to decode files: %w", err)
+	}
+
+	payload, err := decodeNotificationPayload(n.Type, []byte(n.Payload))
+	if err != nil {
+		return Notification{}, err
+	}
+
+	ret := Notification{
+		RecipientType: n.RecipientType,
+		Recipient:     n.Recipient,
+		Type:          n.Type,
+		Payload:       payload,
+	}
+	for _, v := range files {
+		ret.Files = append(ret.Files, NotificationFile{
+			Name:        v.Name,
+			ContentType: v.ContentType,
+			Reader:      bytes.NewReader(v.Content),
+		})
+	}
+
+	return ret, nil
+}
+
+func (n OutboxNotification) TypeName() string {
+	return NotificationTypeName(n.Type)
+}
+
+func (n OutboxNotification) RecipientTypeName() string {
+	return NotificationRecipientTypeName(n.RecipientType)
+}
+
+func (n OutboxNotification) IsDead() bool {
+	return n.Status == OutboxStatusDead
+}
+
+// recipientKey identifies the queue of a recipient, notifications for the
+// same recipient are sent in order.
+func (n *OutboxNotification) recipientKey() string {
+	return fmt.Sprintf("%d:%s", n.RecipientType, n.Recipient)
+}
+
+func (n *OutboxNotification) insert(tx *sqlx.Tx) error {
+	query, args, err := squirrel.Insert("NotificationOutbox").SetMap(squirrel.Eq{
+		"ID":            n.ID,
+		"CreatedAt":     n.CreatedAt,
+		"Type":          n.Type,
+		"RecipientType": n.RecipientType,
+		"Recipient":     n.Recipient,
+		"Payload":       n.Payload,
+		"Files":         n.Files,
+
+		"Status":        n.Status,
+		"Attempts":      n.Attempts,
+		"NextAttemptAt": n.NextAttemptAt,
+		"LastError":     n.LastError,
+	}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(query, args...)
+	return err
+}
+
+func (n *OutboxNotification) update(tx *sqlx.Tx) error {
+	query, args, err := squirrel.Update("NotificationOutbox").SetMap(squirrel.Eq{
+		"Status":        n.Status,
+		"Attempts":      n.Attempts,
+		"NextAttemptAt": n.NextAttemptAt,
+		"LastError":     n.LastError,
+	}).Where("ID = ?", n.ID).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(query, args...)
+	return err
+}
+
+// fail records a failed send and schedules the next attempt with an
+// exponential backoff, or dead-letters the notification.
+func (n *OutboxNotification) fail(tx *sqlx.Tx, sendErr error, now time.Time) error {
+	n.Attempts++
+	n.LastError = sendErr.Error()
+	if n.Attempts >= outboxMaxAttempts {
+		n.Status = OutboxStatusDead
+	} else {
+		n.NextAttemptAt = util.TimeAsTimestamp(now.Add(outboxRetryDelay(n.Attempts)))
+	}
+
+	return n.update(tx)
+}
+
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxBaseDelay
+	for i := 1; i < attempts && delay < outboxMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > outboxMaxDelay {
+		return outboxMaxDelay
+	}
+
+	return delay
+}
+
+func deleteOutboxNotification(tx *sqlx.Tx, id util.UUIDAsBlob) error {
+	_, err := tx.Exec(`DELETE FROM NotificationOutbox WHERE ID = ?`, id)
+	return err
+}
+
+// queueNotification persists a notification to be sent by
+// DispatchNotifications once the transaction is committed. Webhooks are not
+// notified, see queueWebhookEvent.
+func queueNotification(tx *sqlx.Tx, notif Notification) error {
+	n, err := newOutboxNotification(notif)
+	if err != nil {
+		return err
+	}
+
+	return n.insert(tx)
+}
+
+// queueNotificationNow queues a notification that is not tied to any state
+// change in its own transaction.
+func (b *Back) queueNotificationNow(notif Notification) {
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		return queueNotification(tx, notif)
+	}); err != nil {
+		log.Printf("error: unable to queue notification: %s", err)
+	}
+}
+
+// getDueNotifications returns the pending notifications that can be sent
+// now, oldest first. A notification waiting behind one that is waiting for a
+// retry is not due to keep the order of the messages sent to a recipient.
+// Webhook notifications are only returned if webhooks is true, and only them.
+func getDueNotifications(tx *sqlx.Tx, now time.Time, webhooks bool) ([]OutboxNotification, error) {
+	recipientCond := "n.RecipientType != ?"
+	if webhooks {
+		recipientCond = "n.RecipientType = ?"
+	}
+
+	var ret []OutboxNotification
+	if err := tx.Select(&ret, `
+        SELECT n.* FROM NotificationOutbox n
+        WHERE n.Status = ? AND n.NextAttemptAt <= ? AND `+recipientCond+`
+          AND NOT EXISTS (
+            SELECT 1 FROM NotificationOutbox o
+            WHERE o.Status = ? AND o.NextAttemptAt > ?
+              AND o.RecipientType = n.RecipientType AND o.Recipient = n.Recipient
+              AND o.rowid < n.rowid
+          )
+        ORDER BY n.rowid ASC
+        LIMIT ?`,
+		OutboxStatusPending, util.TimeAsTimestamp(now), NotificationRecipientTypeWebhook,
+		OutboxStatusPending, util.TimeAsTimestamp(now),
+		outboxBatchSize,
+	); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// DispatchNotifications sends the due notifications of the outbox using the
+// given function and returns how many were sent, webhooks are sent by the
+// Back itself.
+// Delivery is at-least-once: a notification sent right before a crash will
+// be sent again.
+func (b *Back) DispatchNotifications(send func(Notification) error) (int, error) {
+	return b.dispatchOutbox(false, func(n OutboxNotification) error {
+		notif, err := n.Notification()
+		if err != nil {
+			return err
+		}
+
+		return send(notif)
+	})
+}
+
+func (b *Back) dispatchOutbox(webhooks bool, send func(OutboxNotification) error) (int, error) {
+	var due []OutboxNotification
+	if err := b.transaction(func(tx *sqlx.Tx) (err error) {
+		due, err = getDueNotifications(tx, time.Now(), webhooks)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	var sent int
+	failed := map[string]struct{}{}
+	for k := range due {
+		key := due[k].recipientKey()
+		if _, ok := failed[key]; ok {
+			continue // keep the order, wait for the failed one to be retried
+		}
+
+		sendErr := send(due[k])
+		if err := b.transaction(func(tx *sqlx.Tx) error {
+			if sendErr == nil {
+				return deleteOutboxNotification(tx, due[k].ID)
+			}
+
+			return due[k].fail(tx, sendErr, time.Now())
+		}); err != nil {
+			return sent, err
+		}
+
+		if sendErr != nil {
+			failed[key] = struct{}{}
+			log.Printf(
+				"warning: unable to send notification %s (attempt %d/%d): %s",
+				due[k].ID, due[k].Attempts, outboxMaxAttempts, sendErr,
+			)
+			continue
+		}
+
+		sent++
+	}
+
+	return sent, nil
+}
+
+// GetFailedNotifications returns the notifications that failed to be sent
+// at least once, most recent first.
+func (b *Back) GetFailedNotifications() (ret []OutboxNotification, _ error) {
+	if err := b.transaction(func(tx *sqlx.Tx) error {
+		return tx.Select(&ret, `
+            SELECT * FROM NotificationOutbox
+            WHERE Attempts > 0
+            ORDER BY rowid DESC
+            LIMIT 200`,
+		)
+	}); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// RetryNotification puts a failed notification back in the queue for
+// immediate sending with a fresh set of attempts.
+func (b *Back) RetryNotification(id util.UUIDAsBlob) error {
+	return b.transaction(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`
+            UPDATE NotificationOutbox
+            SET Status = ?, Attempts = 0, NextAttemptAt = ?
+            WHERE ID = ?`,
+			OutboxStatusPending, util.TimeAsTimestamp(time.Now()), id,
+		)
+		return err
+	})
+}
+
+// DiscardNotification removes a notification from the outbox without
+// sending it.
+func (b *Back) DiscardNotification(id util.UUIDAsBlob) error {
+	return b.transaction(func(tx *sqlx.Tx) error {
+		return deleteOutboxNotification(tx, id)
+	})
+}
@@ -0,0 +1,209 @@
+package back // nolint:testpackage
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// dispatchAllNotifications sends every due notification of the outbox and
+// returns them in the order they were sent.
+func dispatchAllNotifications(t *testing.T, back *Back) (ret []Notification) {
+	t.Helper()
+
+	for {
+		sent, err := back.DispatchNotifications(func(notif Notification) error {
+			ret = append(ret, notif)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sent == 0 {
+			return ret
+		}
+	}
+}
+
+func queueTestNotification(t *testing.T, back *Back, recipient, body string) {
+	t.Helper()
+
+	notif := Notification{
+		Recipient: recipient,
+		Type:      NotificationTypeMatchSessionOddKick,
+		Payload:   &MatchSessionOddKickPayload{Player: body},
+	}
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		return queueNotification(tx, notif)
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// makeNotificationsDue skips the retry delays.
+func makeNotificationsDue(t *testing.T, back *Back) {
+	t.Helper()
+
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`UPDATE NotificationOutbox SET NextAttemptAt = 0`)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func body(notif Notification) string {
+	return notif.Payload.(*MatchSessionOddKickPayload).Player
+}
+
+func bodies(notifs []Notification) (ret []string) {
+	for k := range notifs {
+		ret = append(ret, body(notifs[k]))
+	}
+	return ret
+}
+
+func TestNotificationOutboxOrderAndRetry(t *testing.T) {
+	back := createFixturedTestBack(t)
+	queueTestNotification(t, back, "a", "a1")
+	queueTestNotification(t, back, "a", "a2")
+	queueTestNotification(t, back, "b", "b1")
+
+	var got []Notification
+	sent, err := back.DispatchNotifications(func(notif Notification) error {
+		if body(notif) == "a1" {
+			return errors.New("discord is down")
+		}
+		got = append(got, notif)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || len(got) != 1 || body(got[0]) != "b1" {
+		t.Fatalf("expected only b1 to be sent, got %v", bodies(got))
+	}
+
+	// a1 waits for its retry and a2 must wait behind it.
+	if notifs := dispatchAllNotifications(t, back); len(notifs) != 0 {
+		t.Fatalf("expected nothing to be due, got %v", bodies(notifs))
+	}
+
+	failed, err := back.GetFailedNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].Payload != `{"Player":"a1"}` || failed[0].LastError != "discord is down" || failed[0].IsDead() {
+		t.Fatalf("expected a1 to be pending a retry, got %#v", failed)
+	}
+
+	makeNotificationsDue(t, back)
+	notifs := dispatchAllNotifications(t, back)
+	if b := bodies(notifs); len(b) != 2 || b[0] != "a1" || b[1] != "a2" {
+		t.Fatalf("expected a1 then a2, got %v", b)
+	}
+}
+
+func TestNotificationOutboxDeadLetter(t *testing.T) {
+	back := createFixturedTestBack(t)
+	queueTestNotification(t, back, "a", "a1")
+
+	fail := func(Notification) error { return errors.New("nope") }
+	for i := 0; i < outboxMaxAttempts; i++ {
+		makeNotificationsDue(t, back)
+		if _, err := back.DispatchNotifications(fail); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Dead notifications no longer block their recipient.
+	queueTestNotification(t, back, "a", "a2")
+	makeNotificationsDue(t, back)
+	if b := bodies(dispatchAllNotifications(t, back)); len(b) != 1 || b[0] != "a2" {
+		t.Fatalf("expected only a2 to be sent, got %v", b)
+	}
+
+	failed, err := back.GetFailedNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || !failed[0].IsDead() || failed[0].Attempts != outboxMaxAttempts {
+		t.Fatalf("expected a1 to be dead, got %#v", failed)
+	}
+
+	if err := back.RetryNotification(failed[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if b := bodies(dispatchAllNotifications(t, back)); len(b) != 1 || b[0] != "a1" {
+		t.Fatalf("expected a1 to be sent after a retry, got %v", b)
+	}
+
+	queueTestNotification(t, back, "a", "a3")
+	if _, err := back.DispatchNotifications(fail); err != nil {
+		t.Fatal(err)
+	}
+	failed, _ = back.GetFailedNotifications()
+	if len(failed) != 1 {
+		t.Fatalf("expected one failed notification, got %d", len(failed))
+	}
+	if err := back.DiscardNotification(failed[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	makeNotificationsDue(t, back)
+	if notifs := dispatchAllNotifications(t, back); len(notifs) != 0 {
+		t.Fatalf("expected discarded notification not to be sent, got %v", bodies(notifs))
+	}
+}
+
+func TestNotificationOutboxFiles(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	notif := Notification{
+		Recipient: "a",
+		Type:      NotificationTypeSpoilerLog,
+		Payload:   &SpoilerLogPayload{Seed: "seed", Available: true},
+		Files: []NotificationFile{{
+			Name:        "seed.zpf",
+			ContentType: "application/zlib",
+			Reader:      bytes.NewReader([]byte("patch")),
+		}},
+	}
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		return queueNotification(tx, notif)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	notifs := dispatchAllNotifications(t, back)
+	if len(notifs) != 1 || len(notifs[0].Files) != 1 {
+		t.Fatalf("expected one notification with one file, got %#v", notifs)
+	}
+	file := notifs[0].Files[0]
+	content, err := ioutil.ReadAll(file.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name != "seed.zpf" || file.ContentType != "application/zlib" || string(content) != "patch" {
+		t.Errorf("file did not survive the outbox: %s %s %q", file.Name, file.ContentType, content)
+	}
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  outboxBaseDelay,
+		2:  2 * outboxBaseDelay,
+		4:  8 * outboxBaseDelay,
+		9:  outboxMaxDelay,
+		64: outboxMaxDelay,
+	}
+
+	for attempts, expected := range cases {
+		if actual := outboxRetryDelay(attempts); actual != expected {
+			t.Errorf("attempt %d: expected %s, got %s", attempts, expected, actual)
+		}
+	}
+}
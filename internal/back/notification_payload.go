@@ -0,0 +1,178 @@
+package back
+
+import (
+	"encoding/json"
+	"fmt"
+	"kaepora/internal/generator/oot"
+	"time"
+)
+
+// Each NotificationType has its own payload holding the raw data of the
+// event, front-ends render them using their own templates.
+
+// MatchSessionStatusUpdatePayload is sent to the League announce channel
+// every time a MatchSession changes status.
+type MatchSessionStatusUpdatePayload struct {
+	League    string // ShortCode
+	Status    MatchSessionStatus
+	StartDate time.Time
+	Settings  string
+
+	VoteOptions []string             // MatchSessionStatusJoinable only
+	VoteResults []SettingsVoteResult // MatchSessionStatusPreparing only, nil without a vote
+	Contestants int                  // MatchSessionStatusPreparing only
+}
+
+type MatchSessionCountdownPayload struct {
+	League    string
+	StartDate time.Time
+}
+
+// MatchSessionEmptyPayload is sent to the announce channel and to the
+// lonely player when a session is closed without enough players.
+type MatchSessionEmptyPayload struct {
+	League   string
+	ToPlayer bool
+}
+
+type MatchSessionOddKickPayload struct {
+	Player string
+}
+
+type MatchSeedPayload struct {
+	SeedURL       string                 // empty if the patch is attached instead
+	Hash          string                 // comma-separated items, can be empty
+	StartDate     time.Time              // zero for practice seeds
+	SettingsPatch map[string]interface{} // raw settings picked by shuffled settings, see SettingsDocumentation
+}
+
+// NotificationEntry is the state of a runner in a Match.
+type NotificationEntry struct {
+	Player   string
+	Status   MatchEntryStatus
+	Outcome  MatchEntryOutcome
+	Started  bool
+	Duration time.Duration // set if Started and the entry has ended
+}
+
+func newNotificationEntry(entry MatchEntry, name string) NotificationEntry {
+	ret := NotificationEntry{
+		Player:  name,
+		Status:  entry.Status,
+		Outcome: entry.Outcome,
+		Started: !entry.StartedAt.Time.Time().IsZero(),
+	}
+	if ret.Started && entry.HasEnded() {
+		ret.Duration = entry.EndedAt.Time.Time().Sub(entry.StartedAt.Time.Time()).Round(time.Second)
+	}
+
+	return ret
+}
+
+func (e NotificationEntry) HasEnded() bool {
+	return e.Status == MatchEntryStatusFinished || e.Status == MatchEntryStatusForfeit
+}
+
+func (e NotificationEntry) HasForfeit() bool {
+	return e.Status == MatchEntryStatusForfeit
+}
+
+func (e NotificationEntry) HasWon() bool {
+	return e.Outcome == MatchEntryOutcomeWin
+}
+
+func (e NotificationEntry) HasLost() bool {
+	return e.Outcome == MatchEntryOutcomeLoss
+}
+
+type MatchEndPayload struct {
+	Self, Opponent    NotificationEntry
+	OpponentStreamURL string
+}
+
+type MatchSessionRecapPayload struct {
+	League    string
+	StartDate time.Time
+	Rows      []RecapRow
+	Unknown   int    // races hidden from the recap as they are still in progress
+	URL       string // session page, set when all races are known
+}
+
+type RecapRow struct {
+	Entries    [2]NotificationEntry
+	Difficulty oot.SeedDifficulty
+}
+
+type SpoilerLogPayload struct {
+	URL       string // set for matches, the spoiler log is attached for practice seeds
+	Seed      string // practice seeds only
+	Available bool   // practice seeds only
+}
+
+type LeagueLeaderboardUpdatePayload struct {
+	League string
+	Top    []string // player names, best first
+}
+
+// MatchSeedFallbackPayload is sent to the players, and with ForAdmin and
+// the details to the admins.
+type MatchSeedFallbackPayload struct {
+	ForAdmin      bool
+	MatchID       string
+	Generator     string
+	UsedGenerator string
+}
+
+// MatchSeedFailurePayload is sent to the players, and with ForAdmin and
+// the details to the admins. Seed is only set for failed practice seeds.
+type MatchSeedFailurePayload struct {
+	ForAdmin         bool
+	MatchID          string
+	Player1, Player2 string
+	Error            string
+	Seed             string
+}
+
+// NewNotificationPayload returns a pointer to an empty payload of the
+// given type.
+func NewNotificationPayload(typ NotificationType) (interface{}, error) {
+	switch typ {
+	case NotificationTypeMatchSessionStatusUpdate:
+		return &MatchSessionStatusUpdatePayload{}, nil
+	case NotificationTypeMatchEnd:
+		return &MatchEndPayload{}, nil
+	case NotificationTypeMatchSeed:
+		return &MatchSeedPayload{}, nil
+	case NotificationTypeMatchSessionCountdown:
+		return &MatchSessionCountdownPayload{}, nil
+	case NotificationTypeMatchSessionEmpty:
+		return &MatchSessionEmptyPayload{}, nil
+	case NotificationTypeMatchSessionOddKick:
+		return &MatchSessionOddKickPayload{}, nil
+	case NotificationTypeMatchSessionRecap:
+		return &MatchSessionRecapPayload{}, nil
+	case NotificationTypeSpoilerLog:
+		return &SpoilerLogPayload{}, nil
+	case NotificationTypeLeagueLeaderboardUpdate:
+		return &LeagueLeaderboardUpdatePayload{}, nil
+	case NotificationTypeMatchSeedFallback:
+		return &MatchSeedFallbackPayload{}, nil
+	case NotificationTypeMatchSeedFailure:
+		return &MatchSeedFailurePayload{}, nil
+	default:
+		return nil, fmt.Errorf("no payload for notification type %d", typ)
+	}
+}
+
+func decodeNotificationPayload(typ NotificationType, raw []byte) (interface{}, error) {
+	payload, err := NewNotificationPayload(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return nil, fmt.Errorf("unable to decode %s payload: %w", NotificationTypeName(typ), err)
+	}
+
+	return payload, nil
+}
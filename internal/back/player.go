@@ -18,6 +18,7 @@ type Player struct {
 	Name      string
 	DiscordID null.String
 	StreamURL string
+	Locale    string // lowercase ISO 639-1 (eg. "fr"), empty for the default
 
 	Rating PlayerRating `db:"-"`
 }
@@ -55,6 +56,7 @@ func (p *Player) insert(tx *sqlx.Tx) error {
 		"Name":      p.Name,
 		"DiscordID": p.DiscordID,
 		"StreamURL": p.StreamURL,
+		"Locale":    p.Locale,
 	}).ToSql()
 	if err != nil {
 		return err
@@ -72,6 +74,7 @@ func (p *Player) Update(tx *sqlx.Tx) error {
 		"Name":      p.Name,
 		"DiscordID": p.DiscordID,
 		"StreamURL": p.StreamURL,
+		"Locale":    p.Locale,
 	}).Where("Player.ID = ?", p.ID).ToSql()
 	if err != nil {
 		return err
@@ -179,6 +182,34 @@ func (b *Back) UpdateDiscordPlayerStreamURL(discordID, streamURL string) (string
 	return normalizedURL, nil
 }
 
+// SetDiscordPlayerLocale sets the language of the bot replies and
+// notifications sent to a player, an empty locale resets it to the default.
+// The locale is not validated, this is up to the front-ends.
+func (b *Back) SetDiscordPlayerLocale(discordID, locale string) error {
+	return b.transaction(func(tx *sqlx.Tx) error {
+		player, err := getPlayerByDiscordID(tx, discordID)
+		if err != nil {
+			return err
+		}
+
+		player.Locale = locale
+		return player.Update(tx)
+	})
+}
+
+// SetPlayerLocale is SetDiscordPlayerLocale using the player ID.
+func (b *Back) SetPlayerLocale(playerID util.UUIDAsBlob, locale string) error {
+	return b.transaction(func(tx *sqlx.Tx) error {
+		player, err := getPlayerByID(tx, playerID)
+		if err != nil {
+			return err
+		}
+
+		player.Locale = locale
+		return player.Update(tx)
+	})
+}
+
 func getPlayersByMatches(tx *sqlx.Tx, matches []Match) (map[util.UUIDAsBlob]Player, error) {
 	ids := make([]util.UUIDAsBlob, 0, len(matches)*2)
 	for k := range matches {
@@ -0,0 +1,39 @@
+package back // nolint:testpackage
+
+import (
+	"testing"
+)
+
+func TestSetPlayerLocale(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	player, err := back.GetPlayerByName("Saria")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Locale != "" {
+		t.Fatalf("expected no locale, got %q", player.Locale)
+	}
+
+	if err := back.SetDiscordPlayerLocale(player.DiscordID.String, "fr"); err != nil {
+		t.Fatal(err)
+	}
+	player, err = back.GetPlayerByDiscordID(player.DiscordID.String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Locale != "fr" {
+		t.Fatalf("expected locale %q, got %q", "fr", player.Locale)
+	}
+
+	if err := back.SetPlayerLocale(player.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	player, err = back.GetPlayerByID(player.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Locale != "" {
+		t.Fatalf("expected the locale to be reset, got %q", player.Locale)
+	}
+}
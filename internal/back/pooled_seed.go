@@ -0,0 +1,236 @@
+package back
+
+import (
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"kaepora/internal/generator"
+	"kaepora/internal/util"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// A PooledSeed is a seed generated ahead of time for a League so matches can
+// be handed a seed without waiting for the generator.
+// A PooledSeed is only valid as long as its Generator matches the one of its
+// League, its Settings the ones the next League session will use, and the
+// content of its settings did not change.
+type PooledSeed struct {
+	ID        util.UUIDAsBlob
+	LeagueID  util.UUIDAsBlob
+	MatchID   util.NullUUIDAsBlob
+	CreatedAt util.TimeAsTimestamp
+
+	Generator    string
+	Settings     string // see nextSessionSettings
+	SettingsHash string // see settingsHash
+	Seed         string
+
+	SpoilerLog     util.ZLIBBlob
+	GeneratorState []byte
+	SeedPatch      []byte
+
+	// When a blob store is configured SpoilerLog and SeedPatch are stored
+	// there under these keys and are empty in the DB, see loadPooledSeedBlobs.
+	SpoilerLogKey string
+	SeedPatchKey  string
+}
+
+func NewPooledSeed(
+	league League, settings, settingsHash, seed string, out generator.Output,
+) (PooledSeed, error) {
+	spoilerLog, err := util.NewZLIBBlob(out.SpoilerLog)
+	if err != nil {
+		return PooledSeed{}, err
+	}
+
+	ret := PooledSeed{
+		ID:        util.NewUUIDAsBlob(),
+		LeagueID:  league.ID,
+		CreatedAt: util.TimeAsTimestamp(time.Now()),
+
+		Generator:    league.Generator,
+		Settings:     settings,
+		SettingsHash: settingsHash,
+		Seed:         seed,
+
+		SpoilerLog:     spoilerLog,
+		GeneratorState: out.State,
+		SeedPatch:      out.SeedPatch,
+	}
+	ret.ensureNotNULL()
+
+	return ret, nil
+}
+
+// Output returns the generator output the PooledSeed was created from, its
+// blobs must have been loaded.
+func (s *PooledSeed) Output() (generator.Output, error) {
+	spoilerLog, err := ioutil.ReadAll(s.SpoilerLog.Uncompressed())
+	if err != nil {
+		return generator.Output{}, err
+	}
+
+	return generator.Output{
+		State:      s.GeneratorState,
+		SeedPatch:  s.SeedPatch,
+		SpoilerLog: spoilerLog,
+	}, nil
+}
+
+func (s *PooledSeed) ensureNotNULL() {
+	if s.SeedPatch == nil {
+		s.SeedPatch = []byte{}
+	}
+	if s.SpoilerLog == nil {
+		s.SpoilerLog = []byte{}
+	}
+	if s.GeneratorState == nil {
+		s.GeneratorState = []byte{}
+	}
+}
+
+func (s *PooledSeed) insert(tx *sqlx.Tx) error {
+	s.ensureNotNULL()
+
+	query, args, err := squirrel.Insert("PooledSeed").SetMap(squirrel.Eq{
+		"ID":        s.ID,
+		"LeagueID":  s.LeagueID,
+		"MatchID":   s.MatchID,
+		"CreatedAt": s.CreatedAt,
+
+		"Generator":    s.Generator,
+		"Settings":     s.Settings,
+		"SettingsHash": s.SettingsHash,
+		"Seed":         s.Seed,
+
+		"SpoilerLog":     s.SpoilerLog,
+		"GeneratorState": s.GeneratorState,
+		"SeedPatch":      s.SeedPatch,
+		"SpoilerLogKey":  s.SpoilerLogKey,
+		"SeedPatchKey":   s.SeedPatchKey,
+	}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// updateBlobs saves the blobs and their keys, the rest of a PooledSeed is
+// immutable.
+func (s *PooledSeed) updateBlobs(tx *sqlx.Tx) error {
+	s.ensureNotNULL()
+
+	_, err := tx.Exec(`
+        UPDATE PooledSeed
+        SET SpoilerLog = ?, SeedPatch = ?, SpoilerLogKey = ?, SeedPatchKey = ?
+        WHERE ID = ?`,
+		s.SpoilerLog, s.SeedPatch, s.SpoilerLogKey, s.SeedPatchKey, s.ID,
+	)
+	return err
+}
+
+// assignToMatch reserves the PooledSeed for the given Match, the Match
+// inherits the seed so it can be regenerated the usual way if needed.
+func (s *PooledSeed) assignToMatch(tx *sqlx.Tx, match *Match) error {
+	s.MatchID = util.NullUUIDAsBlob{UUID: match.ID, Valid: true}
+	match.Seed = s.Seed
+
+	_, err := tx.Exec(`UPDATE PooledSeed SET MatchID = ? WHERE ID = ?`, s.MatchID, s.ID)
+	return err
+}
+
+// takePooledSeed returns the oldest unassigned PooledSeed of the Match League
+// that was generated using the Match Generator and Settings with the given
+// settings content hash, the second return value is false if there is none.
+func takePooledSeed(tx *sqlx.Tx, match Match, settingsHash string) (PooledSeed, bool, error) {
+	var ret PooledSeed
+	query := `
+        SELECT * FROM PooledSeed
+        WHERE LeagueID = ? AND MatchID IS NULL AND Generator = ? AND Settings = ?
+          AND SettingsHash = ?
+        ORDER BY CreatedAt ASC
+        LIMIT 1`
+	if err := tx.Get(&ret, query, match.LeagueID, match.Generator, match.Settings, settingsHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PooledSeed{}, false, nil
+		}
+		return PooledSeed{}, false, err
+	}
+
+	return ret, true, nil
+}
+
+func getPooledSeedByID(tx *sqlx.Tx, id util.UUIDAsBlob) (PooledSeed, error) {
+	var ret PooledSeed
+	if err := tx.Get(&ret, `SELECT * FROM PooledSeed WHERE ID = ? LIMIT 1`, id); err != nil {
+		return PooledSeed{}, err
+	}
+
+	return ret, nil
+}
+
+func getPooledSeedByMatchID(tx *sqlx.Tx, matchID util.UUIDAsBlob) (PooledSeed, error) {
+	var ret PooledSeed
+	query := `SELECT * FROM PooledSeed WHERE MatchID = ? LIMIT 1`
+	if err := tx.Get(&ret, query, matchID); err != nil {
+		return PooledSeed{}, err
+	}
+
+	return ret, nil
+}
+
+func countAvailablePooledSeeds(tx *sqlx.Tx, league League, settings, settingsHash string) (int, error) {
+	var ret int
+	query := `
+        SELECT COUNT(*) FROM PooledSeed
+        WHERE LeagueID = ? AND MatchID IS NULL AND Generator = ? AND Settings = ?
+          AND SettingsHash = ?`
+	if err := tx.Get(&ret, query, league.ID, league.Generator, settings, settingsHash); err != nil {
+		return 0, err
+	}
+
+	return ret, nil
+}
+
+// invalidateSeedPool removes all unassigned seeds of a League that were not
+// generated using the current League Generator and the settings of its next
+// session.
+func invalidateSeedPool(tx *sqlx.Tx, league League) error {
+	settings, err := nextSessionSettings(tx, league)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+        DELETE FROM PooledSeed
+        WHERE LeagueID = ? AND MatchID IS NULL AND (Generator != ? OR Settings != ?)`,
+		league.ID, league.Generator, settings,
+	)
+
+	return err
+}
+
+// invalidateSeedPoolContent removes all unassigned seeds of a League that
+// were generated before the content of its settings changed.
+func invalidateSeedPoolContent(tx *sqlx.Tx, league League, settingsHash string) error {
+	_, err := tx.Exec(`
+        DELETE FROM PooledSeed
+        WHERE LeagueID = ? AND MatchID IS NULL AND SettingsHash != ?`,
+		league.ID, settingsHash,
+	)
+
+	return err
+}
+
+func deletePooledSeed(tx *sqlx.Tx, id util.UUIDAsBlob) error {
+	_, err := tx.Exec(`DELETE FROM PooledSeed WHERE ID = ?`, id)
+	return err
+}
@@ -0,0 +1,63 @@
+package rotation
+
+import (
+	"fmt"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// A DateRange applies Settings to the sessions starting between two UTC dates,
+// both inclusive, in the YYYY-MM-DD format.
+type DateRange struct {
+	From, To string
+	Settings string
+}
+
+// A DateRangeRotation uses the Settings of the first DateRange containing the
+// session StartDate, sessions outside of any range use the League Settings.
+type DateRangeRotation struct {
+	Ranges []DateRange
+}
+
+func (r *DateRangeRotation) Pick(startDate time.Time, _ int) string {
+	date := startDate.UTC().Format(dateLayout)
+	for _, v := range r.Ranges {
+		// Same-length ISO dates compare lexicographically.
+		if date >= v.From && date <= v.To {
+			return v.Settings
+		}
+	}
+
+	return ""
+}
+
+func (r *DateRangeRotation) All() []string {
+	ret := make([]string, 0, len(r.Ranges))
+	for _, v := range r.Ranges {
+		ret = append(ret, v.Settings)
+	}
+
+	return ret
+}
+
+func (r *DateRangeRotation) validate() error {
+	for k, v := range r.Ranges {
+		from, err := time.Parse(dateLayout, v.From)
+		if err != nil {
+			return fmt.Errorf("range #%d: invalid From date: %w", k, err)
+		}
+		to, err := time.Parse(dateLayout, v.To)
+		if err != nil {
+			return fmt.Errorf("range #%d: invalid To date: %w", k, err)
+		}
+		if to.Before(from) {
+			return fmt.Errorf("range #%d: To is before From", k)
+		}
+		if v.Settings == "" {
+			return fmt.Errorf("range #%d: empty Settings", k)
+		}
+	}
+
+	return nil
+}
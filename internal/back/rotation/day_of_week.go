@@ -0,0 +1,66 @@
+package rotation
+
+import (
+	"fmt"
+	"time"
+)
+
+// A DayOfWeekRotation uses different settings depending on the weekday a
+// session starts on, in the given Location (defaults to UTC). Weekdays
+// without settings use the League Settings.
+type DayOfWeekRotation struct {
+	Location string
+
+	Mon string
+	Tue string
+	Wed string
+	Thu string
+	Fri string
+	Sat string
+	Sun string
+}
+
+func (r *DayOfWeekRotation) Pick(startDate time.Time, _ int) string {
+	loc, err := time.LoadLocation(r.Location)
+	if err != nil { // checked by validate
+		loc = time.UTC
+	}
+
+	switch startDate.In(loc).Weekday() {
+	case time.Monday:
+		return r.Mon
+	case time.Tuesday:
+		return r.Tue
+	case time.Wednesday:
+		return r.Wed
+	case time.Thursday:
+		return r.Thu
+	case time.Friday:
+		return r.Fri
+	case time.Saturday:
+		return r.Sat
+	case time.Sunday:
+		return r.Sun
+	}
+
+	return ""
+}
+
+func (r *DayOfWeekRotation) All() []string {
+	var ret []string
+	for _, v := range []string{r.Mon, r.Tue, r.Wed, r.Thu, r.Fri, r.Sat, r.Sun} {
+		if v != "" {
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
+
+func (r *DayOfWeekRotation) validate() error {
+	if _, err := time.LoadLocation(r.Location); err != nil {
+		return fmt.Errorf("invalid Location: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package rotation picks the settings of a League MatchSession among a set
+// of settings (usually presets) that change from one session to another.
+package rotation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Rotation interface {
+	// Pick returns the settings of the index-th session (0-based, in
+	// StartDate order) of a League starting at startDate, or an empty string
+	// if the League Settings should be used.
+	Pick(startDate time.Time, index int) string
+
+	// All returns all the settings the rotation can pick.
+	All() []string
+}
+
+func New(conf Config) (Rotation, error) {
+	switch conf.Type {
+	case TypeNone:
+		return &VoidRotation{}, nil
+
+	case TypeDateRange:
+		var r DateRangeRotation
+		if err := json.Unmarshal(conf.Payload, &r); err != nil {
+			return &VoidRotation{}, err
+		}
+		if err := r.validate(); err != nil {
+			return &VoidRotation{}, err
+		}
+		return &r, nil
+
+	case TypeDayOfWeek:
+		var r DayOfWeekRotation
+		if err := json.Unmarshal(conf.Payload, &r); err != nil {
+			return &VoidRotation{}, err
+		}
+		if err := r.validate(); err != nil {
+			return &VoidRotation{}, err
+		}
+		return &r, nil
+
+	case TypeRoundRobin:
+		var r RoundRobinRotation
+		if err := json.Unmarshal(conf.Payload, &r); err != nil {
+			return &VoidRotation{}, err
+		}
+		return &r, nil
+	}
+
+	return &VoidRotation{}, fmt.Errorf("invalid rotation type '%s'", conf.Type)
+}
+
+// VoidRotation always uses the League Settings.
+type VoidRotation struct{}
+
+func (r *VoidRotation) Pick(time.Time, int) string {
+	return ""
+}
+
+func (r *VoidRotation) All() []string {
+	return nil
+}
@@ -0,0 +1,90 @@
+package rotation_test
+
+import (
+	"kaepora/internal/back/rotation"
+	"testing"
+	"time"
+)
+
+func TestRotation(t *testing.T) {
+	monday := time.Date(2020, 10, 5, 20, 0, 0, 0, time.UTC)
+	tuesday := monday.Add(24 * time.Hour)
+
+	cases := []struct {
+		name     string
+		conf     rotation.Config
+		date     time.Time
+		index    int
+		expected string
+	}{
+		{"none", rotation.Config{}, monday, 0, ""},
+		{
+			"round-robin",
+			rotation.Config{
+				Type:    rotation.TypeRoundRobin,
+				Payload: []byte(`{"Settings": ["a@1", "b@1", "c@1"]}`),
+			},
+			monday, 4, "b@1",
+		},
+		{
+			"day-of-week",
+			rotation.Config{
+				Type:    rotation.TypeDayOfWeek,
+				Payload: []byte(`{"Mon": "a@1", "Tue": "b@1"}`),
+			},
+			tuesday, 0, "b@1",
+		},
+		{
+			"day-of-week in location",
+			rotation.Config{
+				Type:    rotation.TypeDayOfWeek,
+				Payload: []byte(`{"Location": "Asia/Tokyo", "Mon": "a@1", "Tue": "b@1"}`),
+			},
+			monday, 0, "b@1", // 20:00 UTC is Tuesday in Tokyo
+		},
+		{
+			"date-range",
+			rotation.Config{
+				Type: rotation.TypeDateRange,
+				Payload: []byte(`{"Ranges": [
+					{"From": "2020-09-28", "To": "2020-10-04", "Settings": "a@1"},
+					{"From": "2020-10-05", "To": "2020-10-05", "Settings": "b@1"}
+				]}`),
+			},
+			monday, 0, "b@1",
+		},
+		{
+			"date-range outside of ranges",
+			rotation.Config{
+				Type:    rotation.TypeDateRange,
+				Payload: []byte(`{"Ranges": [{"From": "2020-09-28", "To": "2020-10-04", "Settings": "a@1"}]}`),
+			},
+			tuesday, 0, "",
+		},
+	}
+
+	for _, v := range cases {
+		r, err := rotation.New(v.conf)
+		if err != nil {
+			t.Errorf("%s: %s", v.name, err)
+			continue
+		}
+
+		if actual := r.Pick(v.date, v.index); actual != v.expected {
+			t.Errorf("%s: expected %q, got %q", v.name, v.expected, actual)
+		}
+	}
+}
+
+func TestInvalidRotation(t *testing.T) {
+	for _, v := range []rotation.Config{
+		{Type: "nope"},
+		{Type: rotation.TypeDayOfWeek, Payload: []byte(`{"Location": "Nowhere/Void"}`)},
+		{Type: rotation.TypeDateRange, Payload: []byte(`{"Ranges": [{"From": "2020-10-05", "To": "2020-10-04", "Settings": "a"}]}`)},
+		{Type: rotation.TypeDateRange, Payload: []byte(`{"Ranges": [{"From": "05/10/2020", "To": "2020-10-04", "Settings": "a"}]}`)},
+	} {
+		if _, err := rotation.New(v); err == nil {
+			t.Errorf("expected an error for %s %s", v.Type, v.Payload)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package rotation
+
+import "time"
+
+// A RoundRobinRotation cycles through its Settings, one session after the
+// other.
+type RoundRobinRotation struct {
+	Settings []string
+}
+
+func (r *RoundRobinRotation) Pick(_ time.Time, index int) string {
+	if len(r.Settings) == 0 || index < 0 {
+		return ""
+	}
+
+	return r.Settings[index%len(r.Settings)]
+}
+
+func (r *RoundRobinRotation) All() []string {
+	return r.Settings
+}
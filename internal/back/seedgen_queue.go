@@ -0,0 +1,238 @@
+package back
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SeedgenPriority orders the jobs of the seedgen queue, higher runs first.
+type SeedgenPriority int
+
+const (
+	SeedgenPriorityPractice   SeedgenPriority = 0 // !seed requests and seed pools
+	SeedgenPriorityTournament SeedgenPriority = 1 // !seed requests from admins
+	SeedgenPriorityRanked     SeedgenPriority = 2 // ranked League matches
+)
+
+func (p SeedgenPriority) String() string {
+	switch p {
+	case SeedgenPriorityPractice:
+		return "practice"
+	case SeedgenPriorityTournament:
+		return "tournament"
+	case SeedgenPriorityRanked:
+		return "ranked"
+	default:
+		return "invalid"
+	}
+}
+
+// SeedgenJob is a read-only snapshot of a job in the seedgen queue.
+type SeedgenJob struct {
+	ID        uuid.UUID
+	Priority  SeedgenPriority
+	Generator string
+	Label     string
+	// OwnerDiscordID is the Discord ID of the player who requested the seed,
+	// empty for match seeds.
+	OwnerDiscordID string
+
+	QueuedAt  time.Time
+	StartedAt time.Time // zero if the job is still pending
+
+	// Position is the 1-based position of a pending job in the queue, 0 if
+	// the job is running.
+	Position int
+}
+
+func (j SeedgenJob) IsRunning() bool {
+	return !j.StartedAt.IsZero()
+}
+
+type seedgenJob struct {
+	SeedgenJob
+	seq   uint64
+	limit int // concurrency limit of the job generator
+	run   func()
+}
+
+// seedgenQueue is a priority queue of seed generation jobs consumed by a
+// fixed pool of workers. A job is only started if its generator has not
+// reached its concurrency limit, lower priority jobs for other generators may
+// run in the meantime.
+type seedgenQueue struct {
+	limit func(generatorID string) int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	seq     uint64
+	pending []*seedgenJob // ordered by priority DESC then seq ASC
+	running []*seedgenJob
+	// number of running jobs per generator name (without version)
+	runningPerGenerator map[string]int
+}
+
+func newSeedgenQueue(workers int, limit func(generatorID string) int) *seedgenQueue {
+	q := &seedgenQueue{
+		limit:               limit,
+		runningPerGenerator: map[string]int{},
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// push queues a job and returns its 1-based position in the queue.
+func (q *seedgenQueue) push(
+	priority SeedgenPriority,
+	generatorID, label, ownerDiscordID string,
+	run func(),
+) int {
+	// Resolving the limit may instantiate a generator, keep it out of the lock.
+	limit := q.limit(generatorID)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.seq++
+	job := &seedgenJob{
+		SeedgenJob: SeedgenJob{
+			ID:             uuid.New(),
+			Priority:       priority,
+			Generator:      generatorID,
+			Label:          label,
+			OwnerDiscordID: ownerDiscordID,
+			QueuedAt:       time.Now(),
+		},
+		seq:   q.seq,
+		limit: limit,
+		run:   run,
+	}
+
+	pos := sort.Search(len(q.pending), func(i int) bool {
+		return q.pending[i].Priority < priority
+	})
+	q.pending = append(q.pending, nil)
+	copy(q.pending[pos+1:], q.pending[pos:])
+	q.pending[pos] = job
+
+	q.cond.Broadcast()
+
+	return pos + 1
+}
+
+// wait queues a job and blocks until it has run. The worker slot is only held
+// while run executes.
+func (q *seedgenQueue) wait(
+	priority SeedgenPriority,
+	generatorID, label, ownerDiscordID string,
+	run func(),
+) {
+	done := make(chan struct{})
+	q.push(priority, generatorID, label, ownerDiscordID, func() {
+		defer close(done)
+		run()
+	})
+	<-done
+}
+
+// snapshot returns the running jobs followed by the pending jobs in the order
+// they will be considered.
+func (q *seedgenQueue) snapshot() []SeedgenJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ret := make([]SeedgenJob, 0, len(q.running)+len(q.pending))
+	for _, v := range q.running {
+		ret = append(ret, v.SeedgenJob)
+	}
+	for k, v := range q.pending {
+		job := v.SeedgenJob
+		job.Position = k + 1
+		ret = append(ret, job)
+	}
+
+	return ret
+}
+
+func (q *seedgenQueue) work() {
+	for {
+		job := q.next()
+		job.run()
+		q.done(job)
+	}
+}
+
+// next blocks until a job can be run and marks it as running.
+func (q *seedgenQueue) next() *seedgenJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		for k, job := range q.pending {
+			name := generatorName(job.Generator)
+			if q.runningPerGenerator[name] >= job.limit {
+				continue
+			}
+
+			q.pending = append(q.pending[:k], q.pending[k+1:]...)
+			q.runningPerGenerator[name]++
+			job.StartedAt = time.Now()
+			q.running = append(q.running, job)
+
+			return job
+		}
+
+		q.cond.Wait()
+	}
+}
+
+func (q *seedgenQueue) done(job *seedgenJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.runningPerGenerator[generatorName(job.Generator)]--
+	for k := range q.running {
+		if q.running[k] == job {
+			q.running = append(q.running[:k], q.running[k+1:]...)
+			break
+		}
+	}
+
+	q.cond.Broadcast()
+}
+
+// generatorName strips the version from a "name:version" generator ID.
+func generatorName(generatorID string) string {
+	return strings.SplitN(generatorID, ":", 2)[0]
+}
+
+// seedgenConcurrencyLimit returns the maximum number of seeds that can be
+// generated at the same time using the given generator.
+func (b *Back) seedgenConcurrencyLimit(generatorID string) int {
+	if v := b.config.Seedgen.Concurrency[generatorName(generatorID)]; v > 0 {
+		return v
+	}
+
+	// HACK, arbitrary rate limit for external services, let the API client do
+	// the rate-limiting.
+	if gen, err := b.generatorFactory.NewGenerator(generatorID); err == nil && gen.IsExternal() {
+		return 10
+	}
+
+	return runtime.NumCPU()
+}
+
+// GetSeedgenQueue returns the running and pending seed generation jobs.
+func (b *Back) GetSeedgenQueue() []SeedgenJob {
+	return b.seedgen.snapshot()
+}
@@ -0,0 +1,85 @@
+package back // nolint:testpackage
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSeedgenQueuePriority(t *testing.T) {
+	block := make(chan struct{})
+	q := newSeedgenQueue(1, func(string) int { return 1 })
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	push := func(priority SeedgenPriority, label string) int {
+		wg.Add(1)
+		return q.push(priority, "test:v0", label, "", func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, label)
+			mu.Unlock()
+		})
+	}
+
+	// Occupy the only worker so the next jobs stay pending.
+	started := make(chan struct{})
+	wg.Add(1)
+	q.push(SeedgenPriorityPractice, "test:v0", "blocker", "", func() {
+		defer wg.Done()
+		close(started)
+		<-block
+	})
+	<-started
+
+	push(SeedgenPriorityPractice, "practice 1")
+	push(SeedgenPriorityRanked, "ranked 1")
+	if pos := push(SeedgenPriorityRanked, "ranked 2"); pos != 2 {
+		t.Errorf("expected ranked 2 to be queued at position 2, got %d", pos)
+	}
+	push(SeedgenPriorityPractice, "practice 2")
+	if pos := push(SeedgenPriorityTournament, "tournament 1"); pos != 3 {
+		t.Errorf("expected tournament 1 to be queued at position 3, got %d", pos)
+	}
+
+	close(block)
+	wg.Wait()
+
+	expected := []string{"ranked 1", "ranked 2", "tournament 1", "practice 1", "practice 2"}
+	if !reflect.DeepEqual(expected, order) {
+		t.Errorf("unexpected order\nexpected: %v\nactual  : %v", expected, order)
+	}
+}
+
+func TestSeedgenQueueGeneratorLimit(t *testing.T) {
+	block := make(chan struct{})
+	q := newSeedgenQueue(2, func(string) int { return 1 })
+
+	var wg sync.WaitGroup
+	started := make(chan struct{})
+	wg.Add(2)
+	q.push(SeedgenPriorityRanked, "a:v1", "a1", "", func() {
+		defer wg.Done()
+		close(started)
+		<-block
+	})
+	<-started
+	q.push(SeedgenPriorityRanked, "a:v2", "a2", "", func() { wg.Done() })
+
+	// b is not limited by a and can use the second worker.
+	bDone := make(chan struct{})
+	q.push(SeedgenPriorityPractice, "b:v1", "b1", "", func() { close(bDone) })
+	<-bDone
+
+	for _, v := range q.snapshot() {
+		if v.Label == "a2" && (v.IsRunning() || v.Position != 1) {
+			t.Errorf("expected a2 to be pending at position 1, got %#v", v)
+		}
+	}
+
+	close(block)
+	wg.Wait()
+}
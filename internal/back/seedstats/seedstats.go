@@ -0,0 +1,231 @@
+// Package seedstats accumulates statistics over the spoiler logs of a league.
+// Stats are plain counters so they can be stored and updated one seed at a
+// time instead of decoding every spoiler log again.
+package seedstats
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"kaepora/internal/generator/oot"
+	"strings"
+)
+
+// Stats holds the counters of all the seeds added to it, percentages are
+// computed from Seeds when displaying them.
+type Stats struct {
+	Seeds int
+
+	WOTHLocations map[string]int
+	WOTHItems     map[string]int
+	BarrenRegions map[string]int
+	Settings      map[string]map[string]int // name => value => count
+	Locations     map[string]map[oot.SpoilerLogItemCategory]int
+	SphereSum     map[string]int // total sphere sum by item
+	SphereCount   map[string]int // item occurrence count
+
+	SphereDepthSum, WOTHCountSum, HardProgressionSum int
+	RequiredDungeons                                 map[string]int
+
+	Hints        int                          // hints seen
+	HintTypes    [oot.GossipHintTypeCount]int // hints per type
+	HintTypeWOTH [oot.GossipHintTypeCount]int // hints per type pointing at the WotH
+	HintedPlaces map[string]int               // seeds hinting at a place
+
+	Entrances map[string]map[string]int // exit => destination => count
+}
+
+// New returns empty Stats.
+func New() *Stats {
+	return &Stats{
+		WOTHLocations:    map[string]int{},
+		WOTHItems:        map[string]int{},
+		BarrenRegions:    map[string]int{},
+		Settings:         map[string]map[string]int{},
+		Locations:        map[string]map[oot.SpoilerLogItemCategory]int{},
+		SphereSum:        map[string]int{},
+		SphereCount:      map[string]int{},
+		RequiredDungeons: map[string]int{},
+		HintedPlaces:     map[string]int{},
+		Entrances:        map[string]map[string]int{},
+	}
+}
+
+// Add counts a single seed.
+func (s *Stats) Add(l oot.SpoilerLog) {
+	s.Seeds++
+
+	for _, name := range l.BarrenRegions {
+		s.BarrenRegions[name]++
+	}
+
+	s.addWOTH(l)
+	s.addLocations(l)
+	s.addSettings(l)
+	s.addSpheres(l)
+	s.addDifficulty(l)
+	s.addHints(l)
+	s.addEntrances(l)
+}
+
+func (s *Stats) addWOTH(l oot.SpoilerLog) {
+	progressive := map[string]int{}
+	for location, item := range l.WOTHLocations {
+		s.WOTHLocations[string(location)]++
+
+		if strings.HasPrefix(string(item), "Progressive") {
+			s.WOTHItems[ProgressiveItemName(progressive, string(item))]++
+		} else {
+			s.WOTHItems[string(item)]++
+		}
+	}
+}
+
+func (s *Stats) addLocations(l oot.SpoilerLog) {
+	for name, item := range l.Locations {
+		if _, ok := s.Locations[string(name)]; !ok {
+			s.Locations[string(name)] = make(
+				map[oot.SpoilerLogItemCategory]int,
+				oot.SpoilerLogItemCategoryCount,
+			)
+		}
+
+		s.Locations[string(name)][item.GetCategory()]++
+	}
+}
+
+func (s *Stats) addSettings(l oot.SpoilerLog) {
+	for name, value := range l.Settings {
+		// HACK, ugly array we don't care about.
+		if name == "allowed_tricks" {
+			continue
+		}
+
+		if _, ok := s.Settings[name]; !ok {
+			s.Settings[name] = map[string]int{}
+		}
+
+		s.Settings[name][fmt.Sprintf("%v", value)]++
+	}
+}
+
+func (s *Stats) addSpheres(l oot.SpoilerLog) {
+	progressive := map[string]int{}
+
+	for k, sphere := range l.Spheres() {
+		for _, item := range sphere {
+			itemName := ProgressiveItemName(progressive, string(item))
+			switch item.GetCategory() { // nolint:exhaustive
+			case oot.SpoilerLogItemCategorySmallKey, oot.SpoilerLogItemCategoryBossKey:
+				continue
+			}
+
+			s.SphereSum[itemName] += k
+			s.SphereCount[itemName]++
+		}
+	}
+}
+
+func (s *Stats) addDifficulty(l oot.SpoilerLog) {
+	difficulty := l.Difficulty()
+	s.SphereDepthSum += difficulty.SphereDepth
+	s.WOTHCountSum += difficulty.WOTHCount
+	s.HardProgressionSum += difficulty.HardProgression
+
+	for _, name := range difficulty.RequiredDungeons {
+		s.RequiredDungeons[name]++
+	}
+}
+
+func (s *Stats) addHints(l oot.SpoilerLog) {
+	places := map[string]struct{}{}
+	for _, gossip := range l.GossipStones {
+		typ := gossip.HintType()
+		s.Hints++
+		s.HintTypes[typ]++
+		if gossip.PointsAtAnyOf(l.WOTHLocations) {
+			s.HintTypeWOTH[typ]++
+		}
+
+		if place := gossip.HintedPlace(); place != "" {
+			places[place] = struct{}{}
+		}
+	}
+
+	// Duplicated hints count once per seed.
+	for k := range places {
+		s.HintedPlaces[k]++
+	}
+}
+
+func (s *Stats) addEntrances(l oot.SpoilerLog) {
+	for exit, destination := range l.Entrances {
+		if _, ok := s.Entrances[string(exit)]; !ok {
+			s.Entrances[string(exit)] = map[string]int{}
+		}
+
+		s.Entrances[string(exit)][oot.EntranceDestination(destination)]++
+	}
+}
+
+// ProgressiveItemName returns the actual name of the n-th progressive item
+// found, cache holds the number of items already found.
+func ProgressiveItemName(cache map[string]int, item string) string {
+	cache[item]++
+	switch item {
+	case "Progressive Strength Upgrade":
+		switch cache[item] {
+		case 1:
+			return "Goron's Bracelet"
+		case 2:
+			return "Silver Gauntlets"
+		case 3:
+			return "Golden Gauntlets"
+		}
+	case "Progressive Hookshot":
+		switch cache[item] {
+		case 1:
+			return "Hookshot"
+		case 2:
+			return "Longshot"
+		}
+	case "Progressive Scale":
+		switch cache[item] {
+		case 1:
+			return "Silver Scale"
+		case 2:
+			return "Golden Scale"
+		}
+	case "Progressive Wallet":
+		switch cache[item] {
+		case 1:
+			return "Adult's Wallet"
+		case 2:
+			return "Giant's Wallet"
+		}
+	}
+
+	return item
+}
+
+func (s *Stats) Scan(src interface{}) error {
+	*s = *New()
+
+	switch src := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(src), s)
+	case []byte:
+		return json.Unmarshal(src, s)
+	default:
+		return fmt.Errorf("expected []byte or string, got %T", src)
+	}
+}
+
+func (s Stats) Value() (driver.Value, error) {
+	str, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.Value(str), nil
+}
@@ -0,0 +1,59 @@
+package seedstats_test
+
+import (
+	"encoding/json"
+	"kaepora/internal/back/seedstats"
+	"kaepora/internal/generator/oot"
+	"reflect"
+	"testing"
+)
+
+func TestAddAndRoundTrip(t *testing.T) {
+	logs := []string{
+		`{"locations": {"Song from Impa": "Progressive Hookshot", "KF Midos Top Left Chest": "Bow"}}`,
+		`{"locations": {"Song from Impa": "Zeldas Lullaby"}}`,
+	}
+
+	stats := seedstats.New()
+	for _, v := range logs {
+		var l oot.SpoilerLog
+		if err := json.Unmarshal([]byte(v), &l); err != nil {
+			t.Fatal(err)
+		}
+		stats.Add(l)
+	}
+
+	if stats.Seeds != len(logs) {
+		t.Errorf("expected %d seeds, got %d", len(logs), stats.Seeds)
+	}
+	if n := len(stats.Locations); n != 2 {
+		t.Errorf("expected 2 locations, got %d", n)
+	}
+
+	value, err := stats.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var scanned seedstats.Stats
+	if err := scanned.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*stats, scanned) {
+		t.Errorf("round trip mismatch:\n%#v\n%#v", *stats, scanned)
+	}
+
+	if err := scanned.Scan(42); err == nil {
+		t.Error("expected an error when scanning a non-JSON value")
+	}
+}
+
+func TestProgressiveItemName(t *testing.T) {
+	cache := map[string]int{}
+	expected := []string{"Hookshot", "Longshot", "Progressive Hookshot"}
+	for _, v := range expected {
+		if name := seedstats.ProgressiveItemName(cache, "Progressive Hookshot"); name != v {
+			t.Errorf("expected %q, got %q", v, name)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package back
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"kaepora/internal/util"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// SettingsPreset is an immutable version of a generator settings file.
+// Leagues and matches reference a preset version as "Name@Version" in place
+// of a settings file name, editing a preset means creating a new version so
+// the settings a Match was generated with never change.
+type SettingsPreset struct {
+	ID        util.UUIDAsBlob
+	CreatedAt util.TimeAsTimestamp
+	Name      string
+	Version   int
+	Content   string // JSON, as it would be written in a settings file
+	Changelog string // what changed since the previous version
+}
+
+var settingsPresetNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// Ref returns the value to use in League.Settings to reference this version.
+func (p *SettingsPreset) Ref() string {
+	return fmt.Sprintf("%s@%d", p.Name, p.Version)
+}
+
+// FileName returns the name under which the preset is written on disk when
+// handed to a generator. The name includes a hash of the content as the same
+// ref can hold another content in another DB.
+func (p *SettingsPreset) FileName() string {
+	sum := sha256.Sum256([]byte(p.Content))
+	return fmt.Sprintf("%s-%s.json", p.Ref(), hex.EncodeToString(sum[:8]))
+}
+
+func (p *SettingsPreset) validate() error {
+	if !settingsPresetNameRegexp.MatchString(p.Name) {
+		return util.ErrPublic("preset names can only contain letters, digits, '_', '.', and '-'")
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal([]byte(p.Content), &content); err != nil {
+		return util.ErrPublic(fmt.Sprintf("preset content must be a JSON object: %s", err))
+	}
+
+	return nil
+}
+
+func (p *SettingsPreset) insert(tx *sqlx.Tx) error {
+	query, args, err := squirrel.Insert("SettingsPreset").SetMap(squirrel.Eq{
+		"ID":        p.ID,
+		"CreatedAt": p.CreatedAt,
+		"Name":      p.Name,
+		"Version":   p.Version,
+		"Content":   p.Content,
+		"Changelog": p.Changelog,
+	}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// newSettingsPresetVersion creates the next version of the named preset.
+func newSettingsPresetVersion(tx *sqlx.Tx, name, content, changelog string) (SettingsPreset, error) {
+	var last int
+	if err := tx.Get(
+		&last,
+		`SELECT COALESCE(MAX(Version), 0) FROM SettingsPreset WHERE Name = ?`,
+		name,
+	); err != nil {
+		return SettingsPreset{}, err
+	}
+
+	preset := SettingsPreset{
+		ID:        util.NewUUIDAsBlob(),
+		CreatedAt: util.TimeAsTimestamp(time.Now()),
+		Name:      name,
+		Version:   last + 1,
+		Content:   content,
+		Changelog: changelog,
+	}
+
+	if err := preset.validate(); err != nil {
+		return SettingsPreset{}, err
+	}
+
+	if err := preset.insert(tx); err != nil {
+		return SettingsPreset{}, err
+	}
+
+	return preset, nil
+}
+
+func getSettingsPresetByID(tx *sqlx.Tx, id util.UUIDAsBlob) (SettingsPreset, error) {
+	var ret SettingsPreset
+	if err := tx.Get(&ret, `SELECT * FROM SettingsPreset WHERE ID = ? LIMIT 1`, id); err != nil {
+		return SettingsPreset{}, err
+	}
+
+	return ret, nil
+}
+
+func getSettingsPresetByRef(tx *sqlx.Tx, ref string) (SettingsPreset, error) {
+	name, version, err := parseSettingsPresetRef(ref)
+	if err != nil {
+		return SettingsPreset{}, err
+	}
+
+	var ret SettingsPreset
+	if err := tx.Get(
+		&ret,
+		`SELECT * FROM SettingsPreset WHERE Name = ? AND Version = ? LIMIT 1`,
+		name, version,
+	); err != nil {
+		return SettingsPreset{}, fmt.Errorf("could not find settings preset %s: %w", ref, err)
+	}
+
+	return ret, nil
+}
+
+// getSettingsPresets returns all versions of all presets, most recent first.
+func getSettingsPresets(tx *sqlx.Tx) ([]SettingsPreset, error) {
+	var ret []SettingsPreset
+	if err := tx.Select(
+		&ret,
+		`SELECT * FROM SettingsPreset ORDER BY Name ASC, Version DESC`,
+	); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func getSettingsPresetVersions(tx *sqlx.Tx, name string) ([]SettingsPreset, error) {
+	var ret []SettingsPreset
+	if err := tx.Select(
+		&ret,
+		`SELECT * FROM SettingsPreset WHERE Name = ? ORDER BY Version DESC`,
+		name,
+	); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// isSettingsPresetRef returns true if a part of a settings value references a
+// preset instead of a file.
+func isSettingsPresetRef(part string) bool {
+	return strings.Contains(part, "@")
+}
+
+func parseSettingsPresetRef(ref string) (string, int, error) {
+	parts := strings.SplitN(ref, "@", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("expected a preset reference in the form 'name@version', got '%s'", ref)
+	}
+
+	version, err := strconv.Atoi(parts[1])
+	if err != nil || version < 1 {
+		return "", 0, fmt.Errorf("invalid version in preset reference '%s'", ref)
+	}
+
+	return parts[0], version, nil
+}
+
+// splitSettings returns the individual files or preset references in a
+// League.Settings value, eg. "s4@2:s4-shuffled.json" for shuffled settings.
+func splitSettings(settings string) []string {
+	return strings.Split(settings, ":")
+}
+
+// rollSettingsOntoPreset replaces any reference to another version of the
+// preset in a settings value by a reference to the given version.
+func rollSettingsOntoPreset(settings string, preset SettingsPreset) (string, error) {
+	parts := splitSettings(settings)
+	var found bool
+	for k, v := range parts {
+		if !isSettingsPresetRef(v) {
+			continue
+		}
+
+		name, _, err := parseSettingsPresetRef(v)
+		if err != nil {
+			return "", err
+		}
+		if name == preset.Name {
+			parts[k] = preset.Ref()
+			found = true
+		}
+	}
+
+	if !found {
+		return "", util.ErrPublic(fmt.Sprintf("settings '%s' do not use preset %s", settings, preset.Name))
+	}
+
+	return strings.Join(parts, ":"), nil
+}
+
+// validateSettingsPresetRefs ensures all presets referenced in a settings
+// value exist.
+func validateSettingsPresetRefs(tx *sqlx.Tx, settings string) error {
+	var errs []error
+	for _, v := range splitSettings(settings) {
+		if !isSettingsPresetRef(v) {
+			continue
+		}
+
+		if _, err := getSettingsPresetByRef(tx, v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return util.ConcatErrors(errs)
+}
@@ -0,0 +1,99 @@
+package back // nolint:testpackage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSettingsPreset(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	v1, err := back.CreateSettingsPresetVersion("s3", `{"a": 1}`, "initial version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := back.CreateSettingsPresetVersion("s3", `{"a": 2}`, "a is now 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v1.Ref() != "s3@1" || v2.Ref() != "s3@2" {
+		t.Fatalf("unexpected versions %s and %s", v1.Ref(), v2.Ref())
+	}
+
+	if _, err := back.CreateSettingsPresetVersion("s3", `[]`, ""); err == nil {
+		t.Error("expected non-object content to be rejected")
+	}
+	if _, err := back.CreateSettingsPresetVersion("s3:shuffled", `{}`, ""); err == nil {
+		t.Error("expected invalid name to be rejected")
+	}
+
+	league, err := back.GetLeagueByShortcode("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	league.Settings = "s3@9"
+	if err := back.UpdateLeague(league); err == nil {
+		t.Error("expected unknown preset version to be rejected")
+	}
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		created := NewLeague("The C League", "testc", league.GameID, "test:v0", "s4@1")
+		return created.insert(tx)
+	}); err == nil {
+		t.Error("expected a new league referencing an unknown preset to be rejected")
+	}
+
+	league.Settings = "s3@1:shuffled.json"
+	if err := back.UpdateLeague(league); err != nil {
+		t.Fatal(err)
+	}
+	if err := back.RollLeagueOntoSettingsPreset(league.ID, v2.ID); err != nil {
+		t.Fatal(err)
+	}
+	league, err = back.GetLeague(league.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if league.Settings != "s3@2:shuffled.json" {
+		t.Errorf("expected league to be rolled onto s3@2, got %s", league.Settings)
+	}
+
+	dir, err := ioutil.TempDir("", "kaepora-presets-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path, err := writeSettingsPreset(dir, v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, v2.FileName()) {
+		t.Errorf("unexpected preset path: %s", path)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != v2.Content {
+		t.Errorf("unexpected preset file content: %s", content)
+	}
+
+	// The same ref with another content, eg. from another DB, gets its own file.
+	other := v2
+	other.Content = `{"other": true}`
+	otherPath, err := writeSettingsPreset(dir, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherPath == path {
+		t.Error("expected presets with different contents to use different files")
+	}
+	if content, _ := ioutil.ReadFile(otherPath); string(content) != other.Content {
+		t.Errorf("unexpected preset file content: %s", content)
+	}
+}
@@ -0,0 +1,144 @@
+package back
+
+import (
+	"fmt"
+	"kaepora/internal/util"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// A SettingsVote is the settings a runner wants the MatchSession they joined
+// to use, each runner has at most one vote per session.
+type SettingsVote struct {
+	MatchSessionID util.UUIDAsBlob
+	PlayerID       util.UUIDAsBlob
+	CreatedAt      util.TimeAsTimestamp
+	Settings       string
+}
+
+// SettingsVoteResult is the number of votes an option received.
+type SettingsVoteResult struct {
+	Settings string
+	Votes    int
+}
+
+func NewSettingsVote(sessionID, playerID util.UUIDAsBlob, settings string) SettingsVote {
+	return SettingsVote{
+		MatchSessionID: sessionID,
+		PlayerID:       playerID,
+		CreatedAt:      util.TimeAsTimestamp(time.Now()),
+		Settings:       settings,
+	}
+}
+
+// upsert stores the vote, replacing the previous vote of the player.
+func (v *SettingsVote) upsert(tx *sqlx.Tx) error {
+	_, err := tx.Exec(`
+        INSERT OR REPLACE INTO SettingsVote (MatchSessionID, PlayerID, CreatedAt, Settings)
+        VALUES (?, ?, ?, ?)`,
+		v.MatchSessionID, v.PlayerID, v.CreatedAt, v.Settings,
+	)
+
+	return err
+}
+
+func getSettingsVotes(tx *sqlx.Tx, sessionID util.UUIDAsBlob) ([]SettingsVote, error) {
+	var ret []SettingsVote
+	if err := tx.Select(
+		&ret,
+		`SELECT * FROM SettingsVote WHERE MatchSessionID = ? ORDER BY CreatedAt ASC`,
+		sessionID,
+	); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// getSettingsVoteResults counts the votes of a session for each option of its
+// League, in the League order. Votes of runners that are no longer in the
+// session and votes for removed options are ignored.
+func getSettingsVoteResults(tx *sqlx.Tx, session MatchSession, league League) ([]SettingsVoteResult, error) {
+	votes, err := getSettingsVotes(tx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := league.SettingsVoteOptionsList()
+	ret := make([]SettingsVoteResult, len(options))
+	for k := range options {
+		ret[k].Settings = options[k]
+	}
+
+	for _, vote := range votes {
+		if !session.HasPlayerID(vote.PlayerID.UUID()) {
+			continue
+		}
+
+		for k := range ret {
+			if ret[k].Settings == vote.Settings {
+				ret[k].Votes++
+				break
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// winningSettingsVoteResult returns the most voted option, ties go to the
+// first option in League order. The second return value is false if no one
+// voted.
+func winningSettingsVoteResult(results []SettingsVoteResult) (SettingsVoteResult, bool) {
+	var winner SettingsVoteResult
+	for _, v := range results {
+		if v.Votes > winner.Votes {
+			winner = v
+		}
+	}
+
+	return winner, winner.Votes > 0
+}
+
+// parseSettingsVoteOption returns the option designated by the user either
+// by its 1-based position or its value.
+func parseSettingsVoteOption(options []string, str string) (string, error) {
+	str = strings.TrimSpace(str)
+	if i, err := strconv.Atoi(str); err == nil && i >= 1 && i <= len(options) {
+		return options[i-1], nil
+	}
+
+	for _, v := range options {
+		if v == str {
+			return v, nil
+		}
+	}
+
+	return "", util.ErrPublic(fmt.Sprintf(
+		"`%s` is not an option, pick a number between 1 and %d", str, len(options),
+	))
+}
+
+// canVoteForSettings returns nil if the player can vote for the settings of
+// the session, voting closes when the session starts its preparations.
+func (s *MatchSession) canVoteForSettings(league League, playerID util.UUIDAsBlob) error {
+	if !league.HasSettingsVote() {
+		return util.ErrPublic(fmt.Sprintf("there is no settings vote in the %s league", league.Name))
+	}
+
+	if s.Status != MatchSessionStatusJoinable ||
+		time.Now().After(s.StartDate.Time().Add(MatchSessionPreparationOffset)) {
+		return util.ErrPublic("the settings vote is closed")
+	}
+
+	if !s.HasPlayerID(playerID.UUID()) {
+		return util.ErrPublic(fmt.Sprintf(
+			"you need to `!join %s` the race before voting", league.ShortCode,
+		))
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package back // nolint:testpackage
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSettingsVote(t *testing.T) {
+	back := createFixturedTestBack(t)
+
+	league, err := back.GetLeagueByShortcode("testa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	league.SettingsVoteOptions = "a.json, b.json"
+	if err := back.UpdateLeague(league); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := createSessionAndJoin(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vote := func(name, option string) error {
+		player, err := back.GetPlayerByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = back.VoteForSettingsByShortcode(player, "testa", option)
+		return err
+	}
+
+	for name, option := range map[string]string{
+		"Darunia": "1",
+		"Nabooru": "b.json",
+		"Rauru":   "1",
+		"Ruto":    "2",
+	} {
+		if err := vote(name, option); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+	}
+	// Changing a vote replaces the previous one.
+	if err := vote("Rauru", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := vote("Saria", "3"); err == nil {
+		t.Error("expected an out of range option to be rejected")
+	}
+	if err := vote("Our Lord and Savior ZFG", "1"); err == nil {
+		t.Error("expected a player outside of the session to be rejected")
+	}
+
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		results, err := getSettingsVoteResults(tx, session, league)
+		if err != nil {
+			return err
+		}
+		if results[0].Votes != 1 || results[1].Votes != 3 {
+			t.Errorf("unexpected results %v", results)
+		}
+
+		if err := tallySettingsVote(tx, &session, league); err != nil {
+			return err
+		}
+		if session.Settings != "b.json" {
+			t.Errorf("expected b.json to win the vote, got %q", session.Settings)
+		}
+
+		match, err := NewMatch(tx, session, "seed")
+		if err != nil {
+			return err
+		}
+		if match.Settings != "b.json" {
+			t.Errorf("expected match to use the voted settings, got %q", match.Settings)
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,217 @@
+package back
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"kaepora/internal/util"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// Headers sent along the webhook payloads.
+const (
+	// WebhookSignatureHeader holds "sha256=" followed by the hex HMAC-SHA256
+	// of the request body keyed with the Webhook Secret.
+	WebhookSignatureHeader = "X-Kaepora-Signature"
+	// WebhookEventHeader holds the NotificationType name of the payload.
+	WebhookEventHeader = "X-Kaepora-Event"
+	// WebhookDeliveryHeader holds the WebhookEvent ID, it does not change
+	// when a delivery is retried and can be used to discard duplicates.
+	WebhookDeliveryHeader = "X-Kaepora-Delivery"
+)
+
+// Webhook is an HTTP endpoint of a community tool (eg. a restream overlay)
+// that receives the public events of the types it subscribed to as signed
+// JSON POST requests, see WebhookEvent and WebhookEventTypes.
+type Webhook struct {
+	ID        util.UUIDAsBlob
+	CreatedAt util.TimeAsTimestamp
+	URL       string
+	Secret    string // hex, see WebhookSignatureHeader
+	Types     string // comma-separated NotificationType names
+}
+
+// WebhookEvent is the body POSTed to a Webhook.
+type WebhookEvent struct {
+	ID        string    // unique per event and webhook
+	Type      string    // NotificationType name
+	CreatedAt time.Time // when the event was queued
+	Payload   json.RawMessage
+}
+
+// NewWebhook creates a webhook with a random secret, types are
+// NotificationType names.
+func NewWebhook(rawURL string, types []string) (Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, util.ErrPublic("the webhook URL must be an absolute http(s) URL")
+	}
+
+	if len(types) == 0 {
+		return Webhook{}, util.ErrPublic("the webhook must subscribe to at least one notification type")
+	}
+	for _, v := range types {
+		typ, err := ParseNotificationType(v)
+		if err != nil {
+			return Webhook{}, err
+		}
+		if !isWebhookEventType(typ) {
+			return Webhook{}, util.ErrPublic(fmt.Sprintf("webhooks can't subscribe to %s notifications", v))
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return Webhook{}, err
+	}
+
+	return Webhook{
+		ID:        util.NewUUIDAsBlob(),
+		CreatedAt: util.TimeAsTimestamp(time.Now()),
+		URL:       u.String(),
+		Secret:    hex.EncodeToString(secret),
+		Types:     strings.Join(types, ","),
+	}, nil
+}
+
+// TypesList returns the names of the NotificationType the webhook subscribed to.
+func (w *Webhook) TypesList() []string {
+	return strings.Split(w.Types, ",")
+}
+
+func (w *Webhook) subscribesTo(typ NotificationType) bool {
+	name := NotificationTypeName(typ)
+	for _, v := range w.TypesList() {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sign returns the value of the WebhookSignatureHeader for the given body.
+func (w *Webhook) sign(body []byte) (string, error) {
+	key, err := hex.DecodeString(w.Secret)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook secret: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func (w *Webhook) insert(tx *sqlx.Tx) error {
+	query, args, err := squirrel.Insert("Webhook").SetMap(squirrel.Eq{
+		"ID":        w.ID,
+		"CreatedAt": w.CreatedAt,
+		"URL":       w.URL,
+		"Secret":    w.Secret,
+		"Types":     w.Types,
+	}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(query, args...)
+	return err
+}
+
+func getWebhooks(tx *sqlx.Tx) (ret []Webhook, _ error) {
+	if err := tx.Select(&ret, `SELECT * FROM Webhook ORDER BY CreatedAt ASC`); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func getWebhookByID(tx *sqlx.Tx, id util.UUIDAsBlob) (ret Webhook, _ error) {
+	if err := tx.Get(&ret, `SELECT * FROM Webhook WHERE ID = ? LIMIT 1`, id); err != nil {
+		return Webhook{}, err
+	}
+
+	return ret, nil
+}
+
+// deleteWebhook removes a webhook and its pending deliveries.
+func deleteWebhook(tx *sqlx.Tx, id util.UUIDAsBlob) error {
+	if _, err := tx.Exec(
+		`DELETE FROM NotificationOutbox WHERE RecipientType = ? AND Recipient = ?`,
+		NotificationRecipientTypeWebhook, id.String(),
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`DELETE FROM Webhook WHERE ID = ?`, id)
+	return err
+}
+
+// WebhookEventTypes returns the NotificationType a Webhook can subscribe to.
+// Webhooks only receive public events, never direct messages or admin
+// notifications.
+func WebhookEventTypes() []NotificationType {
+	return []NotificationType{
+		NotificationTypeMatchSessionStatusUpdate,
+		NotificationTypeMatchSessionCountdown,
+		NotificationTypeMatchSessionEmpty,
+		NotificationTypeMatchSessionOddKick,
+		NotificationTypeMatchEnd,
+		NotificationTypeMatchSessionRecap,
+		NotificationTypeLeagueLeaderboardUpdate,
+	}
+}
+
+func isWebhookEventType(typ NotificationType) bool {
+	for _, v := range WebhookEventTypes() {
+		if v == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queueWebhookEvent queues a single event for each webhook subscribed to its
+// type. It must be called once per domain event with a payload that is safe
+// to publish, Discord notifications are never copied to webhooks.
+func queueWebhookEvent(tx *sqlx.Tx, typ NotificationType, payload interface{}) error {
+	if !isWebhookEventType(typ) {
+		return fmt.Errorf("%s is not a webhook event type", NotificationTypeName(typ))
+	}
+
+	webhooks, err := getWebhooks(tx)
+	if err != nil {
+		return err
+	}
+
+	for k := range webhooks {
+		if !webhooks[k].subscribesTo(typ) {
+			continue
+		}
+
+		n, err := newOutboxNotification(Notification{
+			RecipientType: NotificationRecipientTypeWebhook,
+			Recipient:     webhooks[k].ID.String(),
+			Type:          typ,
+			Payload:       payload,
+		})
+		if err != nil {
+			return err
+		}
+		if err := n.insert(tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
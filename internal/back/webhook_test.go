@@ -0,0 +1,279 @@
+package back // nolint:testpackage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+)
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookStandIn records the requests it receives and answers them with
+// the given status.
+type webhookStandIn struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []webhookRequest
+}
+
+func newWebhookStandIn(t *testing.T) *webhookStandIn {
+	s := &webhookStandIn{status: http.StatusNoContent}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, webhookRequest{r.Header, body})
+		w.WriteHeader(s.status)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *webhookStandIn) setStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *webhookStandIn) received() []webhookRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]webhookRequest(nil), s.requests...)
+}
+
+func createTestWebhook(t *testing.T, back *Back, url string, types ...string) Webhook {
+	t.Helper()
+
+	webhook, err := back.CreateWebhook(url, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return webhook
+}
+
+// queueTestOddKick kicks a fake player, queuing both its Discord notification
+// and the webhook event.
+func queueTestOddKick(t *testing.T, back *Back, name string) {
+	t.Helper()
+
+	player := Player{Name: name, DiscordID: null.StringFrom("a")}
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		return back.sendOddKickNotification(tx, player)
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	back := createFixturedTestBack(t)
+	server := newWebhookStandIn(t)
+	webhook := createTestWebhook(t, back, server.URL, "MatchSessionOddKick")
+	createTestWebhook(t, back, server.URL+"/other", "MatchEnd")
+
+	queueTestOddKick(t, back, "a1")
+
+	if got := bodies(dispatchAllNotifications(t, back)); len(got) != 1 || got[0] != "a1" {
+		t.Fatalf("expected only the Discord notification to be sent by DispatchNotifications, got %v", got)
+	}
+
+	sent, err := back.dispatchWebhookNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 {
+		t.Fatalf("expected 1 webhook notification, got %d", sent)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	req := requests[0]
+
+	key, _ := hex.DecodeString(webhook.Secret)
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(req.body)
+	if expected := "sha256=" + hex.EncodeToString(mac.Sum(nil)); req.header.Get(WebhookSignatureHeader) != expected {
+		t.Errorf("invalid signature %q, expected %q", req.header.Get(WebhookSignatureHeader), expected)
+	}
+	if req.header.Get(WebhookEventHeader) != "MatchSessionOddKick" {
+		t.Errorf("unexpected event header %q", req.header.Get(WebhookEventHeader))
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(req.body, &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.ID != req.header.Get(WebhookDeliveryHeader) || event.Type != "MatchSessionOddKick" {
+		t.Errorf("unexpected event %#v", event)
+	}
+
+	var payload MatchSessionOddKickPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Player != "a1" {
+		t.Errorf("unexpected payload %#v", payload)
+	}
+}
+
+func TestWebhookRetry(t *testing.T) {
+	back := createFixturedTestBack(t)
+	server := newWebhookStandIn(t)
+	createTestWebhook(t, back, server.URL, "MatchSessionOddKick")
+	server.setStatus(http.StatusInternalServerError)
+
+	queueTestOddKick(t, back, "a1")
+	queueTestOddKick(t, back, "a2")
+
+	sent, err := back.dispatchWebhookNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 || len(server.received()) != 1 {
+		t.Fatalf("expected a single failed delivery, got %d sent and %d requests", sent, len(server.received()))
+	}
+
+	failed, err := back.GetFailedNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].RecipientType != NotificationRecipientTypeWebhook || failed[0].LastError == "" {
+		t.Fatalf("expected the failed delivery to be recorded, got %#v", failed)
+	}
+
+	server.setStatus(http.StatusOK)
+	makeNotificationsDue(t, back)
+	sent, err = back.dispatchWebhookNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Fatalf("expected 2 webhook notifications, got %d", sent)
+	}
+
+	requests := server.received()
+	if requests[0].header.Get(WebhookDeliveryHeader) != requests[1].header.Get(WebhookDeliveryHeader) {
+		t.Error("expected retries to keep the same delivery ID")
+	}
+	if requests[2].header.Get(WebhookDeliveryHeader) == requests[1].header.Get(WebhookDeliveryHeader) {
+		t.Error("expected a new delivery ID for the second notification")
+	}
+}
+
+func TestWebhookSkipsDirectMessages(t *testing.T) {
+	back := createFixturedTestBack(t)
+	server := newWebhookStandIn(t)
+	createTestWebhook(t, back, server.URL, "MatchSessionOddKick", "MatchSessionRecap")
+
+	queueTestNotification(t, back, "a", "a1")
+	if err := back.transaction(func(tx *sqlx.Tx) error {
+		league, err := getLeagueByShortCode(tx, "testa")
+		if err != nil {
+			return err
+		}
+		session := NewMatchSession(league.ID, time.Now())
+		if err := session.insert(tx); err != nil {
+			return err
+		}
+
+		toUserID := "a"
+		if err := back.sendSessionRecapNotification(tx, session, nil, RecapScopeAdmin, &toUserID); err != nil {
+			return err
+		}
+		if err := back.sendSessionRecapNotification(tx, session, nil, RecapScopeRunner, &toUserID); err != nil {
+			return err
+		}
+
+		return back.sendSessionRecapNotification(tx, session, nil, RecapScopePublic, nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := back.dispatchWebhookNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 {
+		t.Fatalf("expected only the public recap to reach the webhook, got %d deliveries", sent)
+	}
+	if typ := server.received()[0].header.Get(WebhookEventHeader); typ != "MatchSessionRecap" {
+		t.Errorf("unexpected event %q", typ)
+	}
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	back := createFixturedTestBack(t)
+	server := newWebhookStandIn(t)
+	webhook := createTestWebhook(t, back, server.URL, "MatchSessionOddKick")
+
+	queueTestOddKick(t, back, "a1")
+	if err := back.DeleteWebhook(webhook.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := back.dispatchWebhookNotifications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 0 || len(server.received()) != 0 {
+		t.Errorf("expected no delivery to a deleted webhook, got %d", sent)
+	}
+
+	webhooks, err := back.GetWebhooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 0 {
+		t.Errorf("expected no webhooks, got %d", len(webhooks))
+	}
+}
+
+func TestNewWebhook(t *testing.T) {
+	cases := []struct {
+		url   string
+		types []string
+		ok    bool
+	}{
+		{"https://example.com/hook", []string{"MatchEnd", "MatchSessionRecap"}, true},
+		{"http://localhost:8080", []string{"MatchEnd"}, true},
+		{"example.com/hook", []string{"MatchEnd"}, false},
+		{"ftp://example.com", []string{"MatchEnd"}, false},
+		{"https://example.com/hook", nil, false},
+		{"https://example.com/hook", []string{"NotAType"}, false},
+		{"https://example.com/hook", []string{"MatchSeed"}, false},
+		{"https://example.com/hook", []string{"MatchSeedFailure"}, false},
+	}
+
+	for _, v := range cases {
+		webhook, err := NewWebhook(v.url, v.types)
+		if (err == nil) != v.ok {
+			t.Errorf("%s %v: unexpected error: %v", v.url, v.types, err)
+			continue
+		}
+		if v.ok && len(webhook.Secret) != 64 {
+			t.Errorf("%s: unexpected secret %q", v.url, webhook.Secret)
+		}
+	}
+}
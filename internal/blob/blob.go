@@ -0,0 +1,157 @@
+// Package blob provides a content-addressed storage for large binary values
+// that do not belong in the database (spoiler logs, seed patches).
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// ErrCorrupted is returned when the content of a blob does not match its key.
+var ErrCorrupted = errors.New("blob content does not match its key")
+
+// Store saves blobs under a key derived from their content, storing the same
+// content twice yields the same key and does not use more space.
+type Store interface {
+	// Put stores the data and returns its key.
+	Put(data []byte) (string, error)
+
+	// Get returns the data stored under the key, or ErrCorrupted if the data
+	// no longer matches the key.
+	Get(key string) ([]byte, error)
+
+	// Delete removes the data stored under the key, deleting a missing key is
+	// not an error.
+	Delete(key string) error
+
+	// Walk calls fn for each stored key along with the last time it was Put.
+	Walk(fn func(key string, putAt time.Time) error) error
+}
+
+// Key returns the key under which the given data is stored.
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+var keyRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// FS is a Store writing blobs to a local directory, blobs are sharded in
+// subdirectories named after the first bytes of their key.
+type FS struct {
+	dir string
+}
+
+func NewFS(dir string) (*FS, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &FS{dir: dir}, nil
+}
+
+func (s *FS) path(key string) (string, error) {
+	if !keyRegexp.MatchString(key) {
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+
+	return filepath.Join(s.dir, key[0:2], key[2:4], key), nil
+}
+
+func (s *FS) Put(data []byte) (string, error) {
+	key := Key(data)
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		// Refresh the time so a collection does not sweep a blob that is
+		// about to be referenced again.
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			return "", err
+		}
+
+		return key, nil
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	// Write then rename so a blob is either complete or absent.
+	f, err := ioutil.TempFile(dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (s *FS) Get(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if Key(data) != key {
+		return nil, fmt.Errorf("%s: %w", key, ErrCorrupted)
+	}
+
+	return data, nil
+}
+
+func (s *FS) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *FS) Walk(fn func(key string, putAt time.Time) error) error {
+	return filepath.Walk(s.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip directories and the temporary files of in-progress Puts.
+		if info.IsDir() || !keyRegexp.MatchString(info.Name()) {
+			return nil
+		}
+
+		return fn(info.Name(), info.ModTime())
+	})
+}
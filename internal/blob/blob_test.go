@@ -0,0 +1,100 @@
+package blob_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"kaepora/internal/blob"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kaepora-blob-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := blob.NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := store.Put([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != blob.Key([]byte("hello")) {
+		t.Errorf("unexpected key %s", key)
+	}
+
+	again, err := store.Put([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != key {
+		t.Errorf("same content stored under two keys: %s and %s", key, again)
+	}
+
+	data, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	path := filepath.Join(dir, key[0:2], key[2:4], key)
+	if err := ioutil.WriteFile(path, []byte("tampered"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(key); !errors.Is(err, blob.ErrCorrupted) {
+		t.Errorf("expected ErrCorrupted, got %v", err)
+	}
+
+	if _, err := store.Get("../../etc/passwd"); err == nil {
+		t.Error("expected invalid keys to be rejected")
+	}
+}
+
+func TestFSWalkAndDelete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kaepora-blob-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := blob.NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := store.Put([]byte("a"))
+	b, _ := store.Put([]byte("b"))
+
+	keys := map[string]bool{}
+	if err := store.Walk(func(key string, _ time.Time) error {
+		keys[key] = true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || !keys[a] || !keys[b] {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	if err := store.Delete(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(a); err != nil {
+		t.Errorf("expected deleting a missing blob to succeed, got %v", err)
+	}
+	if _, err := store.Get(a); err == nil {
+		t.Error("expected the deleted blob to be gone")
+	}
+	if _, err := store.Get(b); err != nil {
+		t.Error(err)
+	}
+}
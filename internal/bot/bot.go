@@ -6,14 +6,15 @@ package bot
 import (
 	"errors"
 	"fmt"
-	"io"
 	"kaepora/internal/back"
 	"kaepora/internal/config"
+	"kaepora/internal/notification"
 	"kaepora/internal/util"
 	"log"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -21,22 +22,26 @@ import (
 	"golang.org/x/time/rate"
 )
 
-type commandHandler func(m *discordgo.Message, args []string, w io.Writer) error
+type commandHandler func(m *discordgo.Message, args []string, w *channelWriter) error
 
 // Bot is a Discord bot that acts as a CLI front-end for the Back.
 type Bot struct {
-	back   *back.Back
-	config *config.Config
+	back     *back.Back
+	config   *config.Config
+	renderer *notification.Renderer
 
 	manualSeedgenLimiter *rate.Limiter
 
 	startedAt time.Time
 	dg        *discordgo.Session
 
-	handlers      map[string]commandHandler
-	notifications <-chan back.Notification
+	handlers map[string]commandHandler
 }
 
+// notificationDispatchInterval is how often the notification outbox is
+// polled, it must be short enough for the race countdown to be sent on time.
+const notificationDispatchInterval = 500 * time.Millisecond
+
 // New creates a new Discord bot ready to be launched with Serve.
 func New(back *back.Back, config *config.Config) (*Bot, error) {
 	dg, err := discordgo.New("Bot " + config.Discord.Token)
@@ -44,12 +49,17 @@ func New(back *back.Back, config *config.Config) (*Bot, error) {
 		return nil, err
 	}
 
+	renderer, err := notification.NewRenderer("discord")
+	if err != nil {
+		return nil, err
+	}
+
 	bot := &Bot{
 		back:                 back,
 		config:               config,
+		renderer:             renderer,
 		dg:                   dg,
 		startedAt:            time.Now(),
-		notifications:        back.GetNotificationsChan(),
 		manualSeedgenLimiter: rate.NewLimiter(4.0/60.0, 1), // allow four seeds / minute
 	}
 
@@ -59,6 +69,7 @@ func New(back *back.Back, config *config.Config) (*Bot, error) {
 		"!dev": bot.cmdDev,
 
 		"!help":         bot.cmdHelp,
+		"!lang":         bot.cmdLang,
 		"!leaderboard":  bot.cmdLeaderboards,
 		"!leaderboards": bot.cmdLeaderboards,
 		"!leagues":      bot.cmdLeagues,
@@ -67,6 +78,7 @@ func New(back *back.Back, config *config.Config) (*Bot, error) {
 		"!register":     bot.cmdRegister,
 		"!rename":       bot.cmdRename,
 		"!seed":         bot.cmdSendSeed,
+		"!seedstatus":   bot.cmdSeedStatus,
 		"!setstream":    bot.cmdSetStream,
 		"!yes":          bot.cmdAllRight,
 
@@ -76,6 +88,7 @@ func New(back *back.Back, config *config.Config) (*Bot, error) {
 		"!done":     bot.cmdComplete,
 		"!forfeit":  bot.cmdForfeit,
 		"!join":     bot.cmdJoin,
+		"!vote":     bot.cmdVote,
 	}
 
 	return bot, nil
@@ -97,17 +110,7 @@ func (bot *Bot) Serve(wg *sync.WaitGroup, done <-chan struct{}) {
 		log.Panic(err)
 	}
 
-loop:
-	for {
-		select {
-		case notif := <-bot.notifications:
-			if err := bot.sendNotification(notif); err != nil {
-				log.Printf("unable to send notification: %s", err)
-			}
-		case <-done:
-			break loop
-		}
-	}
+	bot.dispatchNotifications(done, bot.sendNotification)
 
 	if err := bot.dg.Close(); err != nil {
 		log.Printf("error: could not close Discord bot: %s", err)
@@ -118,15 +121,10 @@ loop:
 // idle does nothing until done is closed.
 // It consumes notifications and log them.
 func (bot *Bot) idle(done <-chan struct{}) {
-loop:
-	for {
-		select {
-		case notif := <-bot.notifications:
-			log.Printf("info: not sent: %s", notif.String())
-		case <-done:
-			break loop
-		}
-	}
+	bot.dispatchNotifications(done, func(notif back.Notification) error {
+		log.Printf("info: not sent: %s", notif.String())
+		return nil
+	})
 }
 
 // isListeningOn returns true if the bot should listen to commands sent on the given channel ID.
@@ -188,6 +186,9 @@ func (bot *Bot) createWriterAndDispatch(s *discordgo.Session, m *discordgo.Messa
 	if err != nil {
 		log.Printf("error: could not create channel writer: %s", err)
 	}
+	if out != nil {
+		out.locale = bot.renderer.Catalog(bot.getPlayerLocale(recipientID))
+	}
 	defer func() {
 		if err := out.Flush(); err != nil {
 			log.Printf("error: could not send message: %s", err)
@@ -196,10 +197,11 @@ func (bot *Bot) createWriterAndDispatch(s *discordgo.Session, m *discordgo.Messa
 
 	if err := bot.dispatch(m, out); err != nil {
 		out.Reset()
-		fmt.Fprintln(out, "There was an error processing your command.")
+		out.Printf("There was an error processing your command.\n")
 
 		if errors.Is(err, util.ErrPublic("")) || bot.config.IsDiscordIDAdmin(recipientID) {
-			fmt.Fprintf(out, "```%s\n```\nIf you need help, send `!help`.", err)
+			fmt.Fprintf(out, "```%s\n```\n", err)
+			out.Printf("If you need help, send `!help`.")
 		}
 
 		log.Printf("error: failed to process command: %s", err)
@@ -212,7 +214,7 @@ func (bot *Bot) dispatch(m *discordgo.Message, w *channelWriter) error {
 		r := recover()
 		if r != nil {
 			w.Reset()
-			fmt.Fprint(w, "Someting went very wrong.")
+			w.Printf("Something went very wrong.")
 			log.Print("panic: ", r)
 			log.Printf("%s", debug.Stack())
 		}
@@ -246,51 +248,68 @@ func parseCommand(cmd string) (string, []string) {
 	}
 }
 
-func (bot *Bot) cmdHelp(m *discordgo.Message, _ []string, w io.Writer) error {
-	fmt.Fprintf(w, "Hoo hoot! %s… Look up here!\n"+
-		"It appears that the time has finally come for you to start your adventure!\n"+
-		"You will encounter many hardships ahead… That is your fate.\n"+
-		"Don't feel discouraged, even during the toughest times!\n\n",
-		m.Author.Mention(),
-	)
+func (bot *Bot) cmdHelp(m *discordgo.Message, _ []string, w *channelWriter) error {
+	w.Printf("Hoo hoot! %s… Look up here!\n", m.Author.Mention())
+	w.Printf("It appears that the time has finally come for you to start your adventure!\n")
+	w.Printf("You will encounter many hardships ahead… That is your fate.\n")
+	w.Printf("Don't feel discouraged, even during the toughest times!\n\n")
+
+	joinable := util.FormatDuration(back.MatchSessionJoinableAfterOffset)
+	preparation := util.FormatDuration(back.MatchSessionPreparationOffset)
+	sections := []struct {
+		title    string
+		commands [][2]string // usage, description
+	}{
+		{w.T("Management"), [][2]string{
+			{"!help", w.T("display this help message")},
+			{"!lang [LOCALE]", w.T("set the language I talk to you in")},
+			{"!leaderboard SHORTCODE", w.T("show leaderboards for the given league")},
+			{"!leagues", w.T("list leagues")},
+			{"!recap [SHORTCODE]", w.T("show the 1v1 results for the current session")},
+			{"!register [NAME]", w.T("create your account and link it to your Discord account")},
+			{"!rename NAME", w.T("set your display name to NAME")},
+			{"!setstream URL", w.T("set your stream URL")},
+			{"!seed SHORTCODE [VERSION] [SEED]", w.T("generate a seed valid for the given league")},
+			{"", w.T("VERSION must be a valid OOTR version number")},
+			{"!seedstatus", w.T("show the seed generation queue")},
+		}},
+		{w.T("Racing"), [][2]string{
+			{"!cancel", w.T("cancel joining the next race without penalty until T%s", preparation)},
+			{"!done", w.T("stop your race timer and register your final time")},
+			{"!forfeit", w.T("forfeit (and thus lose) the current race")},
+			{"!join SHORTCODE", w.T("join the next race of the given league (see !leagues)")},
+			{"!vote SHORTCODE N", w.T("vote for the settings of the next race you joined")},
+		}},
+	}
 
-	// nolint:lll
-	fmt.Fprintf(w, `**Available commands**:
-
-Brackets indicate optional arguments.
-
-%[1]s
-# Management
-!help                   # display this help message
-!leaderboard SHORTCODE  # show leaderboards for the given league
-!leagues                # list leagues
-!recap [SHORTCODE]      # show the 1v1 results for the current session
-!register [NAME]        # create your account and link it to your Discord account
-!rename NAME            # set your display name to NAME
-!setstream URL          # set your stream URL
-
-!seed SHORTCODE [VERSION] [SEED]  # generate a seed valid for the given league
-                                  # VERSION must be a valid OOTR version number
-
-# Racing
-!cancel            # cancel joining the next race without penalty until T%[3]s
-!done              # stop your race timer and register your final time
-!forfeit           # forfeit (and thus lose) the current race
-!join SHORTCODE    # join the next race of the given league (see !leagues)
-%[1]s
-
-**Racing**:
-You can freely join a race and cancel without consequences between T%[2]s and T%[3]s.
-When the race reaches its preparation phase at T%[3]s you can no longer cancel and must either complete or forfeit the race.
-You can't join a race that is in progress or has begun its preparation phase (T%[3]s).
-If you are caught cheating, using an alt, or breaking a league's rules **you will be banned**.
-
-Did you get all that?
-`,
-		"```",
-		util.FormatDuration(back.MatchSessionJoinableAfterOffset),
-		util.FormatDuration(back.MatchSessionPreparationOffset),
-	)
+	w.Printf("**Available commands**:\n\n")
+	w.Printf("Brackets indicate optional arguments.\n\n")
+	fmt.Fprint(w, "```\n")
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for k, section := range sections {
+		// Lines without cells split the columns, each section is aligned on its own.
+		if k > 0 {
+			fmt.Fprintln(table)
+		}
+		fmt.Fprintf(table, "# %s\n", section.title)
+		for _, v := range section.commands {
+			fmt.Fprintf(table, "%s\t# %s\n", v[0], v[1])
+		}
+	}
+	table.Flush()
+	fmt.Fprint(w, "```\n")
+
+	// Split in two messages to stay below the Discord length limit.
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	w.Printf("**Racing**:\n")
+	w.Printf("You can freely join a race and cancel without consequences between T%s and T%s.\n", joinable, preparation)
+	w.Printf("When the race reaches its preparation phase at T%s you can no longer cancel and must either complete or forfeit the race.\n", preparation) // nolint:lll
+	w.Printf("You can't join a race that is in progress or has begun its preparation phase (T%s).\n", preparation)
+	w.Printf("If you are caught cheating, using an alt, or breaking a league's rules **you will be banned**.\n\n")
+	w.Printf("Did you get all that?\n")
 
 	return nil
 }
@@ -299,18 +318,18 @@ func argsAsName(args []string) string {
 	return strings.Trim(strings.Join(args, " "), "  \t\n")
 }
 
-func (bot *Bot) cmdAllRight(m *discordgo.Message, _ []string, w io.Writer) error {
-	fmt.Fprintf(w, "All right then, I'll see you around!\nHoot hoot hoot ho!")
+func (bot *Bot) cmdAllRight(m *discordgo.Message, _ []string, w *channelWriter) error {
+	w.Printf("All right then, I'll see you around!\nHoot hoot hoot ho!")
 	return nil
 }
 
-func (bot *Bot) cmdSendSeed(m *discordgo.Message, args []string, w io.Writer) error {
+func (bot *Bot) cmdSendSeed(m *discordgo.Message, args []string, w *channelWriter) error {
 	if len(args) < 1 || len(args) > 3 {
 		return util.ErrPublic("expected 1 to 3 arguments: SHORTCODE [VERSION] [SEED]")
 	}
 
 	if !bot.manualSeedgenLimiter.Allow() {
-		fmt.Fprintf(w, "Too many seeds are being generated right now\nTry again in 20 seconds.")
+		w.Printf("Too many seeds are being generated right now\nTry again in 20 seconds.")
 		return nil
 	}
 
@@ -324,5 +343,42 @@ func (bot *Bot) cmdSendSeed(m *discordgo.Message, args []string, w io.Writer) er
 		seed = args[2]
 	}
 
-	return bot.back.SendDevSeed(m.Author.ID, args[0], seed, version)
+	pos, err := bot.back.SendDevSeed(m.Author.ID, args[0], seed, version)
+	if err != nil {
+		return err
+	}
+
+	w.Printf("Your seed is #%d in the queue, I will send it to you once it is generated.\n", pos)
+	return nil
+}
+
+// cmdSeedStatus writes the queue depth and the position of the seeds
+// requested by the author.
+func (bot *Bot) cmdSeedStatus(m *discordgo.Message, _ []string, w *channelWriter) error {
+	discordID := m.Author.ID
+	var running, pending int
+	var own []back.SeedgenJob
+	for _, v := range bot.back.GetSeedgenQueue() {
+		if v.IsRunning() {
+			running++
+		} else {
+			pending++
+		}
+
+		if v.OwnerDiscordID == discordID {
+			own = append(own, v)
+		}
+	}
+
+	w.Printf("%d seed(s) being generated, %d seed(s) waiting in the queue.\n", running, pending)
+	for _, v := range own {
+		if v.IsRunning() {
+			w.Printf("Your `%s` seed is being generated.\n", v.Label)
+			continue
+		}
+
+		w.Printf("Your `%s` seed is #%d in the queue.\n", v.Label, v.Position)
+	}
+
+	return nil
 }
@@ -2,7 +2,6 @@ package bot
 
 import (
 	"fmt"
-	"io"
 	"kaepora/internal/util"
 	"log"
 	"strings"
@@ -12,7 +11,7 @@ import (
 )
 
 // nolint:funlen
-func (bot *Bot) cmdDev(m *discordgo.Message, args []string, out io.Writer) error {
+func (bot *Bot) cmdDev(m *discordgo.Message, args []string, out *channelWriter) error {
 	if !bot.config.IsDiscordIDAdmin(m.Author.ID) {
 		return fmt.Errorf("!dev command ran by a non-admin: %v", args)
 	}
@@ -80,7 +79,7 @@ func (bot *Bot) cmdDev(m *discordgo.Message, args []string, out io.Writer) error
 	return nil
 }
 
-func (bot *Bot) cmdDevRemoveListen(m *discordgo.Message, _ []string, _ io.Writer) (err error) {
+func (bot *Bot) cmdDevRemoveListen(m *discordgo.Message, _ []string, _ *channelWriter) (err error) {
 	i := -1
 	for k, v := range bot.config.Discord.ListenIDs {
 		if v == m.ChannelID {
@@ -100,7 +99,7 @@ func (bot *Bot) cmdDevRemoveListen(m *discordgo.Message, _ []string, _ io.Writer
 	return bot.config.Write()
 }
 
-func (bot *Bot) cmdDevAddListen(m *discordgo.Message, _ []string, _ io.Writer) (err error) {
+func (bot *Bot) cmdDevAddListen(m *discordgo.Message, _ []string, _ *channelWriter) (err error) {
 	for _, v := range bot.config.Discord.ListenIDs {
 		if v == m.ChannelID {
 			return util.ErrPublic("channel is already being listened on")
@@ -111,12 +110,12 @@ func (bot *Bot) cmdDevAddListen(m *discordgo.Message, _ []string, _ io.Writer) (
 	return bot.config.Write()
 }
 
-func (bot *Bot) cmdDevRerank(_ *discordgo.Message, args []string, _ io.Writer) error {
+func (bot *Bot) cmdDevRerank(_ *discordgo.Message, args []string, _ *channelWriter) error {
 	shortcode := argsAsName(args[1:])
 	return bot.back.Rerank(shortcode)
 }
 
-func (bot *Bot) cmdDevAs(m *discordgo.Message, args []string, _ io.Writer) error {
+func (bot *Bot) cmdDevAs(m *discordgo.Message, args []string, _ *channelWriter) error {
 	if len(args) < 3 {
 		return util.ErrPublic("expected a name and a command")
 	}
@@ -134,7 +133,7 @@ func (bot *Bot) cmdDevAs(m *discordgo.Message, args []string, _ io.Writer) error
 	return nil
 }
 
-func (bot *Bot) cmdDevStartRace(m *discordgo.Message, args []string, _ io.Writer) error {
+func (bot *Bot) cmdDevStartRace(m *discordgo.Message, args []string, _ *channelWriter) error {
 	if len(args) < 2 {
 		return util.ErrPublic("expected a shortcode")
 	}
@@ -142,7 +141,7 @@ func (bot *Bot) cmdDevStartRace(m *discordgo.Message, args []string, _ io.Writer
 	return bot.back.StartDevRace(args[1], m.Author.ID)
 }
 
-func (bot *Bot) cmdDevTo(_ *discordgo.Message, args []string, _ io.Writer) error {
+func (bot *Bot) cmdDevTo(_ *discordgo.Message, args []string, _ *channelWriter) error {
 	if len(args) < 3 {
 		return util.ErrPublic("expected a name and a message")
 	}
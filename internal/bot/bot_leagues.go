@@ -2,7 +2,6 @@ package bot
 
 import (
 	"fmt"
-	"io"
 	"kaepora/internal/back"
 	"strings"
 	"text/tabwriter"
@@ -11,23 +10,23 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (bot *Bot) cmdLeagues(_ *discordgo.Message, _ []string, out io.Writer) error {
+func (bot *Bot) cmdLeagues(_ *discordgo.Message, _ []string, out *channelWriter) error {
 	return bot.displayLeagues(out)
 }
 
-func (bot *Bot) displayLeagues(out io.Writer) error {
+func (bot *Bot) displayLeagues(out *channelWriter) error {
 	games, leagues, times, err := bot.back.GetGamesLeaguesAndTheirNextSessionStartDate()
 	if err != nil {
 		return err
 	}
 
 	for k, game := range games {
-		fmt.Fprintf(out, "%d. Leagues for _%s_:\n", k+1, game.Name)
+		out.Printf("%d. Leagues for _%s_:\n", k+1, game.Name)
 		fmt.Fprint(out, "```\n")
 
 		table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
 
-		fmt.Fprintln(table, "shortcode\tname\tnext race\t")
+		fmt.Fprintf(table, "%s\t%s\t%s\t\n", out.T("shortcode"), out.T("name"), out.T("next race"))
 		fmt.Fprintln(table, "\t\t\t")
 		now := time.Now()
 
@@ -40,9 +39,9 @@ func (bot *Bot) displayLeagues(out io.Writer) error {
 			if next, ok := times[league.ID]; ok {
 				nextStr = next.Format("2006-01-02 15:04 MST")
 				delta := next.Sub(now).Truncate(time.Minute)
-				nextDeltaStr = "(in " + strings.TrimSuffix(delta.String(), "0s") + ")"
+				nextDeltaStr = out.T("(in %s)", strings.TrimSuffix(delta.String(), "0s"))
 			} else {
-				nextStr = "no race planned"
+				nextStr = out.T("no race planned")
 			}
 
 			fmt.Fprintf(
@@ -59,7 +58,7 @@ func (bot *Bot) displayLeagues(out io.Writer) error {
 	return nil
 }
 
-func (bot *Bot) cmdRecap(m *discordgo.Message, args []string, out io.Writer) error {
+func (bot *Bot) cmdRecap(m *discordgo.Message, args []string, out *channelWriter) error {
 	shortcode := argsAsName(args)
 	scope := back.RecapScopePublic
 	if bot.config.IsDiscordIDAdmin(m.Author.ID) {
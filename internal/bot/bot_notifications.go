@@ -4,9 +4,29 @@ import (
 	"fmt"
 	"io"
 	"kaepora/internal/back"
+	"kaepora/internal/notification"
 	"log"
+	"time"
 )
 
+// dispatchNotifications sends the notifications of the Back outbox until
+// done is closed, failed notifications are retried by the Back.
+func (bot *Bot) dispatchNotifications(done <-chan struct{}, send func(back.Notification) error) {
+	ticker := time.NewTicker(notificationDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := bot.back.DispatchNotifications(send); err != nil {
+				log.Printf("error: unable to dispatch notifications: %s", err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
 func (bot *Bot) sendNotification(notif back.Notification) error {
 	w, err := bot.getWriterForNotification(notif)
 	if err != nil {
@@ -23,8 +43,18 @@ func (bot *Bot) sendNotification(notif back.Notification) error {
 		}
 	}
 
-	if _, err := io.Copy(w, &notif); err != nil {
-		return fmt.Errorf("error: unable to copy notification body buffer: %w", err)
+	locale := notification.DefaultLocale
+	if notif.RecipientType == back.NotificationRecipientTypeDiscordUser {
+		locale = bot.getPlayerLocale(notif.Recipient)
+	}
+
+	text, err := bot.renderer.Render(locale, notif)
+	if err != nil {
+		return fmt.Errorf("unable to render notification: %w", err)
+	}
+
+	if _, err := io.WriteString(w, text); err != nil {
+		return fmt.Errorf("unable to write notification: %w", err)
 	}
 
 	return w.Flush()
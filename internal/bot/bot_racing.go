@@ -1,8 +1,6 @@
 package bot
 
 import (
-	"fmt"
-	"io"
 	"kaepora/internal/back"
 	"kaepora/internal/util"
 	"time"
@@ -10,7 +8,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func (bot *Bot) cmdJoin(m *discordgo.Message, args []string, w io.Writer) error {
+func (bot *Bot) cmdJoin(m *discordgo.Message, args []string, w *channelWriter) error {
 	player, err := bot.back.GetPlayerByDiscordID(m.Author.ID)
 	if err != nil {
 		return err
@@ -29,19 +27,18 @@ func (bot *Bot) cmdJoin(m *discordgo.Message, args []string, w io.Writer) error
 		return err
 	}
 
-	fmt.Fprintf(w, "You have been registered for the next race in the %s league.\n", league.Name)
-	fmt.Fprint(w, "Please ensure you have read the rules before the race: <https://ootrladder.com/en/rules>\n")
+	w.Printf("You have been registered for the next race in the %s league.\n", league.Name)
+	w.Printf("Please ensure you have read the rules before the race: <https://ootrladder.com/en/rules>\n")
 
 	cancelDelta := time.Until(session.StartDate.Time().Add(back.MatchSessionPreparationOffset))
 	if cancelDelta > 0 {
-		fmt.Fprintf(
-			w,
+		w.Printf(
 			"If you wish to `!cancel` you have %s to do so, after that you will have to `!forfeit`.",
 			cancelDelta.Truncate(time.Second),
 		)
 	} else { // maybe unreachable, maybe not.
 		raceDelta := time.Until(session.StartDate.Time())
-		fmt.Fprintf(w,
+		w.Printf(
 			"The race begins in %s, you will soon receive your _seed_ details.",
 			raceDelta.Truncate(time.Second),
 		)
@@ -50,7 +47,30 @@ func (bot *Bot) cmdJoin(m *discordgo.Message, args []string, w io.Writer) error
 	return nil
 }
 
-func (bot *Bot) cmdCancel(m *discordgo.Message, _ []string, w io.Writer) error {
+func (bot *Bot) cmdVote(m *discordgo.Message, args []string, w *channelWriter) error {
+	if len(args) < 2 {
+		return util.ErrPublic("expected 2 arguments: SHORTCODE OPTION")
+	}
+
+	player, err := bot.back.GetPlayerByDiscordID(m.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	league, settings, err := bot.back.VoteForSettingsByShortcode(player, args[0], argsAsName(args[1:]))
+	if err != nil {
+		return err
+	}
+
+	w.Printf(
+		"Your vote for `%s` in the next %s race has been registered, you can change it until T%s.",
+		settings, league.Name, util.FormatDuration(back.MatchSessionPreparationOffset),
+	)
+
+	return nil
+}
+
+func (bot *Bot) cmdCancel(m *discordgo.Message, _ []string, w *channelWriter) error {
 	player, err := bot.back.GetPlayerByDiscordID(m.Author.ID)
 	if err != nil {
 		return err
@@ -60,13 +80,12 @@ func (bot *Bot) cmdCancel(m *discordgo.Message, _ []string, w io.Writer) error {
 		return err
 	}
 
-	fmt.Fprint(w, `You have cancelled your participation for the next race.
-This _will not_ count as a loss and won't affect your rankings.`)
+	w.Printf("You have cancelled your participation for the next race.\nThis _will not_ count as a loss and won't affect your rankings.") // nolint:lll
 
 	return nil
 }
 
-func (bot *Bot) cmdComplete(m *discordgo.Message, _ []string, w io.Writer) error {
+func (bot *Bot) cmdComplete(m *discordgo.Message, _ []string, w *channelWriter) error {
 	player, err := bot.back.GetPlayerByDiscordID(m.Author.ID)
 	if err != nil {
 		return err
@@ -79,7 +98,7 @@ func (bot *Bot) cmdComplete(m *discordgo.Message, _ []string, w io.Writer) error
 	return nil
 }
 
-func (bot *Bot) cmdForfeit(m *discordgo.Message, _ []string, w io.Writer) error {
+func (bot *Bot) cmdForfeit(m *discordgo.Message, _ []string, w *channelWriter) error {
 	player, err := bot.back.GetPlayerByDiscordID(m.Author.ID)
 	if err != nil {
 		return err
@@ -2,13 +2,14 @@ package bot
 
 import (
 	"fmt"
-	"io"
+	"kaepora/internal/notification"
 	"kaepora/internal/util"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func (bot *Bot) cmdRename(m *discordgo.Message, args []string, out io.Writer) error {
+func (bot *Bot) cmdRename(m *discordgo.Message, args []string, out *channelWriter) error {
 	if len(args) < 1 {
 		return util.ErrPublic("your forgot to tell me your desired name")
 	}
@@ -18,11 +19,11 @@ func (bot *Bot) cmdRename(m *discordgo.Message, args []string, out io.Writer) er
 		return err
 	}
 
-	fmt.Fprintf(out, "You'll be henceforth known as `%s` on the leaderboards.", name)
+	out.Printf("You'll be henceforth known as `%s` on the leaderboards.", name)
 	return nil
 }
 
-func (bot *Bot) cmdSetStream(m *discordgo.Message, args []string, out io.Writer) error {
+func (bot *Bot) cmdSetStream(m *discordgo.Message, args []string, out *channelWriter) error {
 	if len(args) < 1 {
 		return util.ErrPublic("your forgot to tell me your stream URL")
 	}
@@ -32,11 +33,11 @@ func (bot *Bot) cmdSetStream(m *discordgo.Message, args []string, out io.Writer)
 		return err
 	}
 
-	fmt.Fprintf(out, "Your stream URL has been set to %s", url)
+	out.Printf("Your stream URL has been set to %s", url)
 	return nil
 }
 
-func (bot *Bot) cmdRegister(m *discordgo.Message, args []string, out io.Writer) error {
+func (bot *Bot) cmdRegister(m *discordgo.Message, args []string, out *channelWriter) error {
 	name := argsAsName(args)
 	if name == "" {
 		name = m.Author.Username
@@ -46,11 +47,11 @@ func (bot *Bot) cmdRegister(m *discordgo.Message, args []string, out io.Writer)
 		return err
 	}
 
-	fmt.Fprintf(out, "You have been registered as `%s`, see you on the leaderboards.", name)
+	out.Printf("You have been registered as `%s`, see you on the leaderboards.", name)
 	return nil
 }
 
-func (bot *Bot) cmdLeaderboards(m *discordgo.Message, args []string, w io.Writer) error {
+func (bot *Bot) cmdLeaderboards(m *discordgo.Message, args []string, w *channelWriter) error {
 	shortcode := argsAsName(args)
 	top, around, err := bot.back.GetLeaderboardsForDiscordUser(m.Author.ID, shortcode)
 	if err != nil {
@@ -58,18 +59,18 @@ func (bot *Bot) cmdLeaderboards(m *discordgo.Message, args []string, w io.Writer
 	}
 
 	if len(top) == 0 && len(around) == 0 {
-		fmt.Fprintf(w, "The leaderboard for league `%s` is empty, join the next race!", shortcode)
+		w.Printf("The leaderboard for league `%s` is empty, join the next race!", shortcode)
 		return nil
 	}
 
-	fmt.Fprintf(w, "Top players for league `%s`:\n```\n", shortcode)
+	w.Printf("Top players for league `%s`:\n```\n", shortcode)
 	for i := range top {
 		fmt.Fprintf(w, " %2.d. %s\n", i+1, top[i].PlayerName)
 	}
 	fmt.Fprint(w, "```\n")
 
 	if len(around) > 0 {
-		fmt.Fprint(w, "Players around you:\n```\n")
+		w.Printf("Players around you:\n```\n")
 		for i := range around {
 			fmt.Fprintf(w, "  - %s\n", around[i].PlayerName)
 		}
@@ -78,3 +79,38 @@ func (bot *Bot) cmdLeaderboards(m *discordgo.Message, args []string, w io.Writer
 
 	return nil
 }
+
+func (bot *Bot) cmdLang(m *discordgo.Message, args []string, out *channelWriter) error {
+	if len(args) < 1 {
+		out.Printf("I talk to you in `%s`.\n", bot.getPlayerLocale(m.Author.ID))
+		out.Printf("Available languages: `%s`", strings.Join(notification.Locales, "`, `"))
+		return nil
+	}
+
+	locale := strings.ToLower(argsAsName(args))
+	if !notification.HasLocale(locale) {
+		return util.ErrPublic(fmt.Sprintf(
+			"unknown language, available languages are: %s",
+			strings.Join(notification.Locales, ", "),
+		))
+	}
+
+	if err := bot.back.SetDiscordPlayerLocale(m.Author.ID, locale); err != nil {
+		return err
+	}
+
+	out.locale = bot.renderer.Catalog(locale)
+	out.Printf("I will now talk to you in English.")
+	return nil
+}
+
+// getPlayerLocale returns the locale of the player with the given Discord
+// ID, or the default locale if the player has none or is not registered.
+func (bot *Bot) getPlayerLocale(discordID string) string {
+	player, err := bot.back.GetPlayerByDiscordID(discordID)
+	if err != nil || player.Locale == "" {
+		return notification.DefaultLocale
+	}
+
+	return player.Locale
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/leonelquinteros/gotext"
 )
 
 // channelWriter outputs messages to a Discord channel (or private message)
@@ -18,6 +19,7 @@ type channelWriter struct {
 	dg        *discordgo.Session
 	buf       bytes.Buffer
 	files     []*discordgo.File
+	locale    *gotext.Locale // translations for the recipient, nil to write as-is
 
 	debugInfo string
 }
@@ -68,6 +70,20 @@ func (w *channelWriter) Write(p []byte) (int, error) {
 	return w.buf.Write(p)
 }
 
+// T returns the translation of str in the language of the recipient.
+func (w *channelWriter) T(str string, args ...interface{}) string {
+	if w == nil || w.locale == nil {
+		return gotext.Printf(str, args...)
+	}
+
+	return w.locale.Get(str, args...)
+}
+
+// Printf writes a message translated in the language of the recipient.
+func (w *channelWriter) Printf(format string, args ...interface{}) {
+	fmt.Fprint(w, w.T(format, args...))
+}
+
 func (w *channelWriter) Reset() {
 	if w == nil {
 		return
@@ -3,9 +3,13 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"kaepora/internal/generator"
+	"kaepora/internal/generator/oot"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"time"
 
 	"golang.org/x/oauth2"
 )
@@ -20,6 +24,22 @@ type Config struct {
 
 	Domain string
 
+	// SeedPoolSize is the number of seeds to generate ahead of time for each
+	// League, 0 disables the pool and seeds are generated on the fly.
+	SeedPoolSize int
+
+	Seedgen Seedgen
+
+	// BlobStoreDir is the directory where spoiler logs and seed patches are
+	// stored, content-addressed by hash. If empty they are kept in the DB.
+	BlobStoreDir string
+
+	// OOTRunner configures how the local OoT-Randomizer generators are run.
+	OOTRunner oot.RunnerConfig
+
+	// Games holds the per-Game configuration indexed by Game name.
+	Games map[string]Game
+
 	// DevMode weakens security during development and disables HTTPS.
 	DevMode bool
 }
@@ -40,6 +60,90 @@ type Discord struct {
 	BannedUserIDs []string
 }
 
+// Game holds the configuration specific to a single Game.
+type Game struct {
+	// Generators are generic local generators indexed by generator name, they
+	// can be used by the leagues of the Game as "name:version".
+	Generators map[string]generator.ProcessConfig
+}
+
+// ProcessGenerators returns the generic generators of all games indexed by
+// generator name.
+func (c *Config) ProcessGenerators() map[string]generator.ProcessConfig {
+	ret := map[string]generator.ProcessConfig{}
+	for game, conf := range c.Games {
+		for name, v := range conf.Generators {
+			if _, ok := ret[name]; ok {
+				log.Printf("warning: generator %s of game %s is defined twice, ignoring", name, game)
+				continue
+			}
+			ret[name] = v
+		}
+	}
+
+	return ret
+}
+
+// GameOfGenerator returns the name of the Game that defines the given generic
+// generator name, or false if no Game defines it.
+func (c *Config) GameOfGenerator(name string) (string, bool) {
+	for game, conf := range c.Games {
+		if _, ok := conf.Generators[name]; ok {
+			return game, true
+		}
+	}
+
+	return "", false
+}
+
+// Seedgen holds the seed generation queue and retry policy.
+type Seedgen struct {
+	// Workers is the maximum number of seeds generated at the same time
+	// across all generators. Defaults to the number of CPUs, at least 10.
+	Workers int
+
+	// Concurrency is the maximum number of seeds generated at the same time
+	// for a given generator name (without version). Defaults to 10 for
+	// external generators and to the number of CPUs for local ones.
+	Concurrency map[string]int
+
+	// Attempts is the number of times each generator of a League is tried
+	// before falling back to the next one. Defaults to 3.
+	Attempts int
+
+	// RetryDelay is the number of seconds to wait before retrying a failed
+	// generation, it is doubled after each failure. Defaults to 10.
+	RetryDelay int
+}
+
+func (c Seedgen) GetWorkers() int {
+	if c.Workers > 0 {
+		return c.Workers
+	}
+
+	if cpus := runtime.NumCPU(); cpus > 10 {
+		return cpus
+	}
+
+	return 10
+}
+
+func (c Seedgen) GetAttempts() int {
+	if c.Attempts <= 0 {
+		return 3
+	}
+
+	return c.Attempts
+}
+
+func (c Seedgen) GetRetryDelay() time.Duration {
+	if c.RetryDelay <= 0 {
+		return 10 * time.Second
+	}
+
+	return time.Duration(c.RetryDelay) * time.Second
+}
+
 func (c Discord) CanRunBot() bool {
 	return c.Token != ""
 }
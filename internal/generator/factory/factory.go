@@ -12,29 +12,52 @@ import (
 // generators. ie. the rate limiter of the OOTR API requires us to keep a
 // single instance so we have to go all java here.
 type Factory struct {
-	ootrAPI *ootrapi.API
+	ootrAPI   *ootrapi.API
+	ootRunner oot.Runner
+	processes map[string]generator.ProcessConfig
 }
 
-func New(ootrAPI *ootrapi.API) Factory {
+// New creates a Factory, processes are the configured generic generators
+// indexed by name, built-in generators take precedence over them.
+// A nil ootRunner runs the local OoT-Randomizer using Docker.
+func New(
+	ootrAPI *ootrapi.API,
+	ootRunner oot.Runner,
+	processes map[string]generator.ProcessConfig,
+) Factory {
+	if ootRunner == nil {
+		ootRunner, _ = oot.NewRunner(oot.RunnerConfig{})
+	}
+
 	return Factory{
-		ootrAPI: ootrAPI,
+		ootrAPI:   ootrAPI,
+		ootRunner: ootRunner,
+		processes: processes,
 	}
 }
 
 func (f Factory) NewGenerator(id string) (generator.Generator, error) {
 	switch name, version := parseID(id); name {
 	case oot.RandomizerName:
-		return oot.NewRandomizer(version), nil
+		return oot.NewRandomizer(version, f.ootRunner), nil
 	case oot.RandomizerAPIName:
 		return oot.NewRandomizerAPI(version, f.ootrAPI), nil
 	case oot.SettingsRandomizerName:
-		return oot.NewSettingsRandomizer(version), nil
+		return oot.NewSettingsRandomizer(version, f.ootRunner), nil
 	case oot.SettingsRandomizerAPIName:
 		return oot.NewSettingsRandomizerAPI(version, f.ootrAPI), nil
 	case "test":
 		return generator.NewTest(), nil
 	default:
-		return nil, fmt.Errorf("unknown generator: %s", name)
+		conf, ok := f.processes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown generator: %s", name)
+		}
+		if err := conf.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid generator %s: %w", name, err)
+		}
+
+		return generator.NewProcess(version, conf), nil
 	}
 }
 
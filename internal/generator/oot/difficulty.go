@@ -0,0 +1,159 @@
+package oot
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SeedDifficulty is a summary of what a seed requires from runners, it puts
+// race times in context.
+type SeedDifficulty struct {
+	// SphereDepth is the number of spheres in the playthrough.
+	SphereDepth int
+	// WOTHCount is the number of Way of the Hero locations.
+	WOTHCount int
+	// RequiredDungeons are the dungeons holding at least one playthrough item.
+	RequiredDungeons []string
+	// HardProgression is the number of playthrough items, keys excluded,
+	// placed in hard to reach locations.
+	HardProgression int
+}
+
+// dungeonLocationPrefixes maps the prefixes of dungeon locations to the
+// dungeon name, boss rewards are named after the boss.
+var dungeonLocationPrefixes = map[string]string{
+	"Deku Tree":              "Deku Tree",
+	"Queen Gohma":            "Deku Tree",
+	"Dodongos Cavern":        "Dodongos Cavern",
+	"King Dodongo":           "Dodongos Cavern",
+	"Jabu Jabus Belly":       "Jabu Jabus Belly",
+	"Barinade":               "Jabu Jabus Belly",
+	"Forest Temple":          "Forest Temple",
+	"Phantom Ganon":          "Forest Temple",
+	"Fire Temple":            "Fire Temple",
+	"Volvagia":               "Fire Temple",
+	"Water Temple":           "Water Temple",
+	"Morpha":                 "Water Temple",
+	"Shadow Temple":          "Shadow Temple",
+	"Bongo Bongo":            "Shadow Temple",
+	"Spirit Temple":          "Spirit Temple",
+	"Twinrova":               "Spirit Temple",
+	"Bottom of the Well":     "Bottom of the Well",
+	"Ice Cavern":             "Ice Cavern",
+	"Gerudo Training Ground": "Gerudo Training Ground",
+	"Ganons Castle":          "Ganons Castle",
+}
+
+// hardLocationPatterns are substrings of the locations that take long to
+// reach or to check. This is arbitrary.
+var hardLocationPatterns = []string{
+	"Gold Skulltula Reward", "GS ", // tokens and token rewards
+	"Big Poes", "Biggoron", "Bombchu Bowling", "Dampe Race", "Frogs",
+	"HBA", "Horseback Archery", "Treasure Chest Game",
+	"Gerudo Training Ground", "Ganons Castle",
+}
+
+// Difficulty computes the SeedDifficulty of the seed.
+func (s SpoilerLog) Difficulty() SeedDifficulty {
+	ret := SeedDifficulty{
+		SphereDepth: len(s.Playthrough),
+		WOTHCount:   len(s.WOTHLocations),
+	}
+
+	dungeons := map[string]struct{}{}
+	for _, sphere := range s.Playthrough {
+		for location, item := range sphere {
+			if dungeon, ok := location.Dungeon(); ok {
+				dungeons[dungeon] = struct{}{}
+			}
+
+			switch item.GetCategory() { // nolint:exhaustive
+			case SpoilerLogItemCategorySmallKey, SpoilerLogItemCategoryBossKey:
+				continue
+			}
+			if location.IsHardToReach() {
+				ret.HardProgression++
+			}
+		}
+	}
+
+	ret.RequiredDungeons = make([]string, 0, len(dungeons))
+	for k := range dungeons {
+		ret.RequiredDungeons = append(ret.RequiredDungeons, k)
+	}
+	sort.Strings(ret.RequiredDungeons)
+
+	return ret
+}
+
+// Dungeon returns the dungeon the location belongs to, the second return
+// value is false if the location is not in a dungeon.
+func (l SpoilerLogLocation) Dungeon() (string, bool) {
+	for prefix, dungeon := range dungeonLocationPrefixes {
+		if strings.HasPrefix(string(l), prefix) {
+			return dungeon, true
+		}
+	}
+
+	return "", false
+}
+
+// IsHardToReach returns true if the location takes long to reach or check.
+func (l SpoilerLogLocation) IsHardToReach() bool {
+	for _, v := range hardLocationPatterns {
+		if strings.Contains(string(l), v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsZero returns true if the difficulty was not computed, eg. when the seed
+// has no spoiler log.
+func (d SeedDifficulty) IsZero() bool {
+	return d.SphereDepth == 0 && d.WOTHCount == 0
+}
+
+// String returns a short human-readable summary.
+func (d SeedDifficulty) String() string {
+	return fmt.Sprintf(
+		"%d spheres, %d WotH, %d dungeons, %d hard checks",
+		d.SphereDepth, d.WOTHCount, len(d.RequiredDungeons), d.HardProgression,
+	)
+}
+
+func (d *SeedDifficulty) Scan(src interface{}) error {
+	var raw []byte
+	switch src := src.(type) {
+	case string:
+		raw = []byte(src)
+	case []byte:
+		raw = src
+	default:
+		return fmt.Errorf("expected []byte or string, got %T", src)
+	}
+
+	if len(raw) == 0 {
+		*d = SeedDifficulty{}
+		return nil
+	}
+
+	return json.Unmarshal(raw, d)
+}
+
+func (d SeedDifficulty) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return driver.Value(""), nil
+	}
+
+	str, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.Value(str), nil
+}
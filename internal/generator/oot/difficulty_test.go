@@ -0,0 +1,79 @@
+package oot_test
+
+import (
+	"encoding/json"
+	"kaepora/internal/generator/oot"
+	"reflect"
+	"testing"
+)
+
+const difficultySpoilerLog = `{
+    ":woth_locations": {
+        "Forest Temple Bow Chest": "Bow",
+        "Kak 30 Gold Skulltula Reward": "Progressive Hookshot"
+    },
+    ":playthrough": {
+        "1": {
+            "KF Midos Top Left Chest": "Kokiri Sword",
+            "Deku Tree Slingshot Chest": "Slingshot"
+        },
+        "2": {
+            "Kak 30 Gold Skulltula Reward": "Progressive Hookshot",
+            "Forest Temple First Room Chest": "Small Key (Forest Temple)"
+        },
+        "3": {
+            "Phantom Ganon": "Forest Medallion",
+            "GS Forest Temple First Room": "Small Key (Forest Temple)"
+        }
+    }
+}`
+
+func TestSpoilerLogDifficulty(t *testing.T) {
+	var log oot.SpoilerLog
+	if err := json.Unmarshal([]byte(difficultySpoilerLog), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := oot.SeedDifficulty{
+		SphereDepth:      3,
+		WOTHCount:        2,
+		RequiredDungeons: []string{"Deku Tree", "Forest Temple"},
+		HardProgression:  1, // keys are not counted
+	}
+
+	actual := log.Difficulty()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+
+	value, err := actual.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var scanned oot.SeedDifficulty
+	if err := scanned.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, scanned) {
+		t.Fatalf("expected %#v after Scan, got %#v", expected, scanned)
+	}
+}
+
+func TestSeedDifficultyZero(t *testing.T) {
+	value, err := oot.SeedDifficulty{}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "" {
+		t.Fatalf("expected an empty string for the zero value, got %v", value)
+	}
+
+	var scanned oot.SeedDifficulty
+	if err := scanned.Scan(""); err != nil {
+		t.Fatal(err)
+	}
+	if !scanned.IsZero() {
+		t.Fatalf("expected a zero value, got %#v", scanned)
+	}
+}
@@ -0,0 +1,149 @@
+package oot
+
+import (
+	"strings"
+)
+
+// GossipHintType is the kind of hint a Gossip Stone gives.
+type GossipHintType int
+
+// Possible hint types, guessed from the hint text as the spoiler log does not
+// tell them apart.
+const (
+	GossipHintTypeOther GossipHintType = iota // junk and trial hints
+	GossipHintTypeWOTH
+	GossipHintTypeBarren
+	GossipHintTypeItem      // "<region> holds <item>"
+	GossipHintTypeAlways    // location hints for always hinted locations
+	GossipHintTypeSometimes // any other location hint
+
+	GossipHintTypeCount // keep this last
+)
+
+func (t GossipHintType) String() string {
+	switch t { // nolint:exhaustive
+	case GossipHintTypeWOTH:
+		return "Way of the Hero"
+	case GossipHintTypeBarren:
+		return "Barren"
+	case GossipHintTypeItem:
+		return "Item"
+	case GossipHintTypeAlways:
+		return "Always"
+	case GossipHintTypeSometimes:
+		return "Sometimes"
+	default:
+		return "Other"
+	}
+}
+
+// alwaysHintPatterns are lowercase substrings found in the text of the hints
+// for the locations that are always hinted. This is arbitrary.
+var alwaysHintPatterns = []string{
+	"gold skulltula", "big poe", "ocarina of time", "mask of truth", "frogs",
+}
+
+// Highlights returns the highlighted parts of the hint text (between pairs of
+// '#'), usually a location or region followed by an item or hint kind.
+func (g SpoilerLogGossip) Highlights() []string {
+	parts := strings.Split(g.PlainText(), "#")
+
+	ret := make([]string, 0, len(parts)/2)
+	for i := 1; i < len(parts); i += 2 {
+		ret = append(ret, strings.TrimSpace(parts[i]))
+	}
+
+	return ret
+}
+
+// PlainText returns the hint text without line breaks and control characters,
+// highlight markers are kept.
+func (g SpoilerLogGossip) PlainText() string {
+	r := strings.NewReplacer("^", " ", "&", " ", "@", "Link")
+	return strings.Join(strings.Fields(r.Replace(g.Text)), " ")
+}
+
+// HintType guesses the type of the hint from its text.
+func (g SpoilerLogGossip) HintType() GossipHintType {
+	text := strings.ToLower(g.PlainText())
+	highlights := g.Highlights()
+
+	switch {
+	case strings.Contains(text, "way of the hero"):
+		return GossipHintTypeWOTH
+	case strings.Contains(text, "foolish choice"):
+		return GossipHintTypeBarren
+	case len(highlights) != 2:
+		return GossipHintTypeOther
+	case strings.Contains(text, "# holds #"):
+		return GossipHintTypeItem
+	}
+
+	for _, v := range alwaysHintPatterns {
+		if strings.Contains(text, v) {
+			return GossipHintTypeAlways
+		}
+	}
+
+	return GossipHintTypeSometimes
+}
+
+// HintedPlace returns the region or location the hint points at, or an empty
+// string if the hint has none.
+func (g SpoilerLogGossip) HintedPlace() string {
+	// WOTH and Barren hints are guessed from the text alone, they may lack
+	// the highlight markers.
+	highlights := g.Highlights()
+	if len(highlights) == 0 || g.HintType() == GossipHintTypeOther {
+		return ""
+	}
+
+	place := highlights[0]
+	place = strings.TrimPrefix(place, "plundering ")
+
+	return trimArticle(place)
+}
+
+// HintedItem returns the item the hint points at, or an empty string if the
+// hint does not name an item. The name is the one used in hints, not a
+// SpoilerLogItem.
+func (g SpoilerLogGossip) HintedItem() string {
+	switch g.HintType() { // nolint:exhaustive
+	case GossipHintTypeItem, GossipHintTypeAlways, GossipHintTypeSometimes:
+		return trimArticle(g.Highlights()[1])
+	default:
+		return ""
+	}
+}
+
+// PointsAtAnyOf returns true if the hint names a place or an item among the
+// given WotH locations.
+func (g SpoilerLogGossip) PointsAtAnyOf(woth map[SpoilerLogLocation]SpoilerLogItem) bool {
+	if g.HintType() == GossipHintTypeWOTH {
+		return true
+	}
+
+	item := strings.ToLower(g.HintedItem())
+	if item == "" {
+		return false
+	}
+
+	for _, v := range woth {
+		name := strings.ToLower(strings.TrimPrefix(string(v), "Progressive "))
+		if name == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+func trimArticle(str string) string {
+	for _, v := range []string{"a ", "an ", "the ", "some "} {
+		if strings.HasPrefix(strings.ToLower(str), v) {
+			return str[len(v):]
+		}
+	}
+
+	return str
+}
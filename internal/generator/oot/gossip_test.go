@@ -0,0 +1,69 @@
+package oot_test
+
+import (
+	"kaepora/internal/generator/oot"
+	"testing"
+)
+
+func TestGossipHints(t *testing.T) {
+	woth := map[oot.SpoilerLogLocation]oot.SpoilerLogItem{
+		"Kak 30 Gold Skulltula Reward": "Progressive Hookshot",
+	}
+
+	cases := []struct {
+		text       string
+		typ        oot.GossipHintType
+		place      string
+		item       string
+		pointsWOTH bool
+	}{
+		{
+			"They say that #Kakariko Village# is on #the way^of the hero#.",
+			oot.GossipHintTypeWOTH, "Kakariko Village", "", true,
+		},
+		{
+			"They say that #plundering the Lost Woods# is #a foolish choice#.",
+			oot.GossipHintTypeBarren, "Lost Woods", "", false,
+		},
+		{
+			"They say that #Death Mountain Crater# holds #a Hookshot#.",
+			oot.GossipHintTypeItem, "Death Mountain Crater", "Hookshot", true,
+		},
+		{
+			"They say that #slaying 30 Gold Skulltulas# reveals #the Bow#.",
+			oot.GossipHintTypeAlways, "slaying 30 Gold Skulltulas", "Bow", false,
+		},
+		{
+			"They say that #the Bombchu Bowling prize# is #a Hookshot#.",
+			oot.GossipHintTypeSometimes, "Bombchu Bowling prize", "Hookshot", true,
+		},
+		{
+			"They say that Kakariko Village is on the way of the hero.",
+			oot.GossipHintTypeWOTH, "", "", true,
+		},
+		{
+			"They say that plundering the Lost Woods is a foolish choice.",
+			oot.GossipHintTypeBarren, "", "", false,
+		},
+		{
+			"They say that @ likes #cuccos#.",
+			oot.GossipHintTypeOther, "", "", false,
+		},
+	}
+
+	for _, c := range cases {
+		gossip := oot.SpoilerLogGossip{Text: c.text}
+		if typ := gossip.HintType(); typ != c.typ {
+			t.Errorf("%q: expected type %s, got %s", c.text, c.typ, typ)
+		}
+		if place := gossip.HintedPlace(); place != c.place {
+			t.Errorf("%q: expected place %q, got %q", c.text, c.place, place)
+		}
+		if item := gossip.HintedItem(); item != c.item {
+			t.Errorf("%q: expected item %q, got %q", c.text, c.item, item)
+		}
+		if points := gossip.PointsAtAnyOf(woth); points != c.pointsWOTH {
+			t.Errorf("%q: expected PointsAtAnyOf to be %v", c.text, c.pointsWOTH)
+		}
+	}
+}
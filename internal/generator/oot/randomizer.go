@@ -1,15 +1,11 @@
 package oot
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"kaepora/internal/generator"
-	"log"
 	"os"
-	"os/exec"
-	"os/user"
 	"path/filepath"
 )
 
@@ -18,6 +14,7 @@ const RandomizerName = "oot-randomizer"
 // Randomizer is the local OOTR generator.
 type Randomizer struct {
 	version string
+	runner  Runner
 }
 
 // State is the generator-specific state carried along OOT seeds.
@@ -28,9 +25,10 @@ type State struct {
 	SettingsPatch map[string]interface{} `json:",omitempty"`
 }
 
-func NewRandomizer(version string) *Randomizer {
+func NewRandomizer(version string, runner Runner) *Randomizer {
 	return &Randomizer{
 		version: version,
+		runner:  runner,
 	}
 }
 
@@ -45,7 +43,7 @@ func (g *Randomizer) Generate(settingsName, seed string) (generator.Output, erro
 	if err != nil {
 		return generator.Output{}, err
 	}
-	settingsPath := filepath.Join(base, settingsName)
+	settingsPath := generator.SettingsPath(base, settingsName)
 
 	zpf, spoilerLog, err := g.run(outDir, settingsPath, seed)
 	if err != nil {
@@ -68,20 +66,6 @@ func (g *Randomizer) Generate(settingsName, seed string) (generator.Output, erro
 	}, nil
 }
 
-func readFirstGlob(pattern string) ([]byte, error) {
-	names, err := filepath.Glob(pattern)
-	if err != nil || len(names) != 1 {
-		return nil, fmt.Errorf("could not find file with glob `%s`: %w", pattern, err)
-	}
-
-	out, err := ioutil.ReadFile(names[0])
-	if err != nil {
-		return nil, fmt.Errorf("unable to read seed back: %w", err)
-	}
-
-	return out, nil
-}
-
 // GetBaseDir returns the directory where all the resources needed by the
 // generators are stored.
 func GetBaseDir() (string, error) {
@@ -94,46 +78,16 @@ func GetBaseDir() (string, error) {
 }
 
 func (g *Randomizer) run(outDir, settings, seed string) ([]byte, []byte, error) {
-	base, err := GetBaseDir()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	user, err := user.Current()
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// There's no user input, unless the DB has been taken over.
-	// nolint: gosec
-	cmd := exec.Command(
-		"docker", "run", "--rm",
-		"-u", fmt.Sprintf("%s:%s", user.Uid, user.Gid),
-		"-v", base+"/ARCHIVE.bin:/opt/oot-randomizer/ARCHIVE.bin:ro",
-		"-v", base+"/ZOOTDEC.z64:/opt/oot-randomizer/ZOOTDEC.z64:ro",
-		"-v", settings+":/opt/oot-randomizer/settings.json:ro",
-		"-v", outDir+":/opt/oot-randomizer/Output",
-		"lp042/oot-randomizer:"+g.version,
-		"--seed", seed,
-		"--settings", "settings.json",
-	)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		log.Printf("stdout: %s", stdout.String())
-		log.Printf("stderr: %s", stderr.String())
+	if err := g.runner.Run(g.version, outDir, settings, seed); err != nil {
 		return nil, nil, err
 	}
 
-	zpf, err := readFirstGlob(filepath.Join(outDir, "*.zpf"))
+	zpf, err := generator.ReadFirstGlob(filepath.Join(outDir, "*.zpf"))
 	if err != nil {
 		return nil, nil, err
 	}
 
-	spoilerLog, err := readFirstGlob(filepath.Join(outDir, "*_Spoiler.json"))
+	spoilerLog, err := generator.ReadFirstGlob(filepath.Join(outDir, "*_Spoiler.json"))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -8,7 +8,6 @@ import (
 	"kaepora/pkg/ootrapi"
 	"log"
 	"os"
-	"path/filepath"
 	"time"
 )
 
@@ -42,7 +41,7 @@ func (g *RandomizerAPI) Generate(settingsName, seed string) (generator.Output, e
 	if err != nil {
 		return generator.Output{}, err
 	}
-	settingsPath := filepath.Join(base, settingsName)
+	settingsPath := generator.SettingsPath(base, settingsName)
 	rawSettings, err := readSettingsFile(settingsPath)
 	if err != nil {
 		return generator.Output{}, fmt.Errorf("unable to read settings: %w", err)
@@ -123,7 +122,7 @@ func loadSettings(name string) (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	f, err := os.Open(filepath.Join(base, name))
+	f, err := os.Open(generator.SettingsPath(base, name))
 	if err != nil {
 		return nil, err
 	}
@@ -12,7 +12,7 @@ import (
 func TestOOTRandomizer(t *testing.T) {
 	t.Parallel()
 
-	f := factory.New(nil)
+	f := factory.New(nil, nil, nil)
 	g, err := f.NewGenerator(oot.RandomizerName + ":5.2.13")
 	if err != nil {
 		t.Fatal(err)
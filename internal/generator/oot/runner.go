@@ -0,0 +1,169 @@
+package oot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"kaepora/internal/generator"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Runner executes a local OoT-Randomizer of a given version, the patch and
+// spoiler log must be written to outDir.
+type Runner interface {
+	Run(version, outDir, settingsPath, seed string) error
+}
+
+const (
+	RunnerTypeDocker = "docker"
+	RunnerTypePython = "python"
+)
+
+// RunnerConfig selects and configures the Runner used by local generators.
+type RunnerConfig struct {
+	// Type is either RunnerTypeDocker (default) or RunnerTypePython.
+	Type string
+
+	// Python is the interpreter used by the python runner, defaults to
+	// "python3".
+	Python string
+
+	// Paths maps a randomizer version to the path of a checked-out
+	// OoT-Randomizer for the python runner, eg. "5.2.13": "docker/OoT-Randomizer".
+	// As with the Docker image, the checkout must contain ARCHIVE.bin and
+	// ZOOTDEC.z64.
+	Paths map[string]string
+
+	// Timeout is the maximum number of seconds a single run can take,
+	// defaults to 10 minutes.
+	Timeout int
+}
+
+func (c RunnerConfig) GetTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 10 * time.Minute
+	}
+
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// NewRunner creates the Runner described by the configuration.
+func NewRunner(conf RunnerConfig) (Runner, error) {
+	switch conf.Type {
+	case "", RunnerTypeDocker:
+		return &DockerRunner{timeout: conf.GetTimeout()}, nil
+	case RunnerTypePython:
+		python := conf.Python
+		if python == "" {
+			python = "python3"
+		}
+
+		return &PythonRunner{
+			python:  python,
+			paths:   conf.Paths,
+			timeout: conf.GetTimeout(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown OoT-Randomizer runner type: %s", conf.Type)
+	}
+}
+
+// dockerKillTimeout is the maximum time given to Docker to kill a container
+// that timed out.
+const dockerKillTimeout = 30 * time.Second
+
+// DockerRunner runs the randomizer from the lp042/oot-randomizer images.
+type DockerRunner struct {
+	timeout time.Duration
+}
+
+func (r *DockerRunner) Run(version, outDir, settingsPath, seed string) error {
+	base, err := GetBaseDir()
+	if err != nil {
+		return err
+	}
+
+	user, err := user.Current()
+	if err != nil {
+		return err
+	}
+
+	// Killing the docker CLI on timeout leaves the container running, name it
+	// so it can be killed too.
+	name := "kaepora-oot-randomizer-" + uuid.New().String()
+	err = generator.RunCommand([]string{
+		"docker", "run", "--rm", "--name", name,
+		"-u", fmt.Sprintf("%s:%s", user.Uid, user.Gid),
+		"-v", base + "/ARCHIVE.bin:/opt/oot-randomizer/ARCHIVE.bin:ro",
+		"-v", base + "/ZOOTDEC.z64:/opt/oot-randomizer/ZOOTDEC.z64:ro",
+		"-v", settingsPath + ":/opt/oot-randomizer/settings.json:ro",
+		"-v", outDir + ":/opt/oot-randomizer/Output",
+		"lp042/oot-randomizer:" + version,
+		"--seed", seed,
+		"--settings", "settings.json",
+	}, "", r.timeout)
+
+	if errors.Is(err, generator.ErrCommandTimeout) {
+		if killErr := generator.RunCommand(
+			[]string{"docker", "kill", name}, "", dockerKillTimeout,
+		); killErr != nil {
+			log.Printf("error: unable to kill container %s: %s", name, killErr)
+		}
+	}
+
+	return err
+}
+
+// PythonRunner runs a checked-out OoT-Randomizer without Docker.
+type PythonRunner struct {
+	python  string
+	paths   map[string]string
+	timeout time.Duration
+}
+
+func (r *PythonRunner) Run(version, outDir, settingsPath, seed string) error {
+	checkout, ok := r.paths[version]
+	if !ok {
+		return fmt.Errorf("no OoT-Randomizer checkout configured for version %s", version)
+	}
+	checkout, err := filepath.Abs(checkout)
+	if err != nil {
+		return err
+	}
+
+	// Work on a copy of the settings so each run only writes to its own
+	// output directory.
+	sandbox, err := ioutil.TempDir("", "oot-randomizer-sandbox-")
+	if err != nil {
+		return fmt.Errorf("unable to create sandbox directory: %s", err)
+	}
+	defer os.RemoveAll(sandbox)
+
+	settings, err := readSettingsFile(settingsPath)
+	if err != nil {
+		return fmt.Errorf("unable to read settings: %w", err)
+	}
+	settings["output_dir"] = outDir
+
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	sandboxedSettings := filepath.Join(sandbox, "settings.json")
+	if err := ioutil.WriteFile(sandboxedSettings, settingsJSON, 0o600); err != nil {
+		return err
+	}
+
+	return generator.RunCommand([]string{
+		r.python, filepath.Join(checkout, "OoTRandomizer.py"),
+		"--seed", seed,
+		"--settings", sandboxedSettings,
+	}, checkout, r.timeout)
+}
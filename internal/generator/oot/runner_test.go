@@ -0,0 +1,70 @@
+package oot_test
+
+import (
+	"io/ioutil"
+	"kaepora/internal/generator/oot"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// fakeRandomizer mimics the OoT-Randomizer CLI, it writes its outputs to the
+// output_dir found in the given settings.
+const fakeRandomizer = `
+import argparse, json, os
+parser = argparse.ArgumentParser()
+parser.add_argument("--seed")
+parser.add_argument("--settings")
+args = parser.parse_args()
+with open(args.settings) as f:
+    settings = json.load(f)
+out = settings["output_dir"]
+with open(os.path.join(out, "OoT_" + args.seed + ".zpf"), "w") as f:
+    f.write("patch " + args.seed)
+with open(os.path.join(out, "OoT_" + args.seed + "_Spoiler.json"), "w") as f:
+    json.dump({"file_hash": ["a", "b"]}, f)
+`
+
+func TestPythonRunner(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+
+	checkout, err := ioutil.TempDir("", "kaepora-fake-ootr-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(checkout)
+
+	script := filepath.Join(checkout, "OoTRandomizer.py")
+	if err := ioutil.WriteFile(script, []byte(fakeRandomizer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner, err := oot.NewRunner(oot.RunnerConfig{
+		Type:   oot.RunnerTypePython,
+		Python: python,
+		Paths:  map[string]string{"1.0.0": checkout},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := oot.NewRandomizer("1.0.0", runner).Generate("s3.json", "DEADBEEF")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out.SeedPatch) != "patch DEADBEEF" {
+		t.Errorf("unexpected patch: %s", out.SeedPatch)
+	}
+	if len(out.SpoilerLog) == 0 {
+		t.Error("empty spoiler log")
+	}
+
+	if _, err := oot.NewRandomizer("0.0.0", runner).Generate("s3.json", "DEADBEEF"); err == nil {
+		t.Error("expected an error for an unmapped version")
+	}
+}
@@ -2,6 +2,7 @@ package settings
 
 import (
 	"encoding/json"
+	"fmt"
 	"hash/fnv"
 	"log"
 	"math/rand"
@@ -18,20 +19,147 @@ import (
 // The probability is there to ensure some values are scarcely or never used.
 // It is an integer that only has meaning relative to the sum of all
 // probabilities.
-type Settings map[string]Setting // name (json key) => possible values
+// Groups constrain how many settings of a set can be shuffled together, they
+// are stored under the reserved "$groups" key of the settings file.
+// Parameters tune Shuffle, they are stored under the reserved "$parameters"
+// key so leagues sharing a generator can use different budgets.
+type Settings struct {
+	Values     map[string]Setting // name (json key) => possible values
+	Groups     []Group
+	Parameters Parameters
+}
+
+// Reserved keys holding the Groups and Parameters in a settings file.
+const (
+	groupsKey     = "$groups"
+	parametersKey = "$parameters"
+)
+
+// Default Parameters values.
+const (
+	DefaultCostBudget    = 20
+	DefaultMaxIterations = 1000 // arbitrary
+	DefaultToleranceStep = 100
+)
+
+// Parameters control how many settings Shuffle picks and how hard it tries
+// to match the budget, zero values are replaced by the defaults.
+type Parameters struct {
+	// CostBudget is the total cost of the settings to pick.
+	CostBudget int
+	// MaxIterations is the number of passes over all settings after which
+	// Shuffle gives up on matching the budget.
+	MaxIterations int
+	// ToleranceStep is the number of iterations after which the result is
+	// allowed to be one more point away from the budget.
+	ToleranceStep int
+}
+
+func (p Parameters) GetCostBudget() int {
+	if p.CostBudget <= 0 {
+		return DefaultCostBudget
+	}
 
-// Load loads shuffled settings parameters from file.
+	return p.CostBudget
+}
+
+func (p Parameters) GetMaxIterations() int {
+	if p.MaxIterations <= 0 {
+		return DefaultMaxIterations
+	}
+
+	return p.MaxIterations
+}
+
+func (p Parameters) GetToleranceStep() int {
+	if p.ToleranceStep <= 0 {
+		return DefaultToleranceStep
+	}
+
+	return p.ToleranceStep
+}
+
+// A Group bounds the number of its settings that can be picked by Shuffle,
+// settings set through Implies are not counted.
+type Group struct {
+	Name     string
+	Settings []string
+
+	// Exclusive is a shorthand for Max = 1, at most one setting of the group
+	// can be picked.
+	Exclusive bool
+
+	// Min is the minimum number of settings to pick from the group, it can
+	// make Shuffle go over the cost budget.
+	Min int
+	// Max is the maximum number of settings to pick from the group, 0 means
+	// no limit.
+	Max int
+}
+
+func (g Group) max() int {
+	if g.Exclusive {
+		return 1
+	}
+
+	return g.Max
+}
+
+func (g Group) contains(name string) bool {
+	for _, v := range g.Settings {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Load loads shuffled settings parameters from file and validates them.
 func Load(path string) (Settings, error) {
-	var ret Settings
+	ret, err := Read(path)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	if err := ret.Validate(); err != nil {
+		return Settings{}, err
+	}
+
+	return ret, nil
+}
+
+// Read loads shuffled settings parameters from file without validating them.
+func Read(path string) (Settings, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return Settings{}, err
 	}
 	defer f.Close()
 
+	var raw map[string]json.RawMessage
 	dec := json.NewDecoder(f)
-	if err := dec.Decode(&ret); err != nil {
-		return nil, err
+	if err := dec.Decode(&raw); err != nil {
+		return Settings{}, err
+	}
+
+	ret := Settings{Values: make(map[string]Setting, len(raw))}
+	for k, v := range raw {
+		var err error
+		switch k {
+		case groupsKey:
+			err = json.Unmarshal(v, &ret.Groups)
+		case parametersKey:
+			err = json.Unmarshal(v, &ret.Parameters)
+		default:
+			var setting Setting
+			err = json.Unmarshal(v, &setting)
+			ret.Values[k] = setting
+		}
+
+		if err != nil {
+			return Settings{}, fmt.Errorf("invalid value for %s: %w", k, err)
+		}
 	}
 
 	return ret, nil
@@ -39,9 +167,9 @@ func Load(path string) (Settings, error) {
 
 func (s Settings) weightSum() float64 {
 	var weightSum float64
-	for k := range s {
-		for i := range s[k] {
-			weightSum += s[k][i].Weight
+	for k := range s.Values {
+		for i := range s.Values[k] {
+			weightSum += s.Values[k][i].Weight
 		}
 	}
 
@@ -68,23 +196,83 @@ func int64SeedFromString(str string) int64 {
 // unequal probability sampling plan" algorithm.
 // Biometrika Vol. 69, No. 3 (Dec., 1982), pp. 653-656
 // DOI: 10.2307/2336002
-// There is an hardcoded maximum iterations count to avoid inifite loops, and
-// and a tolerance for going under or over the cost budget if we reach enough
-// iterations.
-func (s Settings) Shuffle(seedStr string, costMax int) map[string]interface{} { // nolint:funlen
+// There is a maximum iterations count to avoid inifite loops, and a
+// tolerance for going under or over the cost budget if we reach enough
+// iterations, the budget and both limits are set by the Parameters.
+// Values are only picked if they satisfy their constraints (Requires,
+// Excludes, Groups), the result is the same for a given seed.
+func (s Settings) Shuffle(seedStr string) map[string]interface{} {
+	return s.shuffle(seedStr, nil)
+}
+
+// Explain shuffles the settings like Shuffle and returns how the result was
+// reached.
+func (s Settings) Explain(seedStr string) (map[string]interface{}, ShuffleReport) {
+	var report ShuffleReport
+	ret := s.shuffle(seedStr, &report)
+	return ret, report
+}
+
+// ShuffleReport describes a single run of Shuffle.
+type ShuffleReport struct {
+	Steps []ShuffleStep
+
+	Cost, CostMax     int
+	Iterations        int
+	HitIterationLimit bool // Shuffle gave up before matching the budget
+}
+
+// Overshoot returns by how much the cost budget was exceeded.
+func (r ShuffleReport) Overshoot() int {
+	if r.Cost > r.CostMax {
+		return r.Cost - r.CostMax
+	}
+
+	return 0
+}
+
+// A ShuffleStep is a value that won its roll, Rejected is set if it could not
+// be picked.
+type ShuffleStep struct {
+	Iteration int
+	Setting   string
+	Value     interface{}
+	Cost      int // of the value
+	TotalCost int // of all picked values after this step
+	Implies   map[string]interface{}
+	Rejected  string // reason why the value was not picked, if any
+	Forced    bool   // picked to reach the minimum of a Group
+}
+
+func (s Settings) shuffle( // nolint:funlen
+	seedStr string,
+	report *ShuffleReport,
+) map[string]interface{} {
 	r := rand.New(rand.NewSource(int64SeedFromString(seedStr))) // nolint:gosec
 
 	var costSum, iterations, tolerance int
+	costMax := s.Parameters.GetCostBudget()
 	weightSum := s.weightSum()
-	maxIterations := 1000 // arbitrary
-	ret := map[string]interface{}{}
+	maxIterations := s.Parameters.GetMaxIterations()
+	toleranceStep := s.Parameters.GetToleranceStep()
+	st := newShuffleState(s)
+	step := func(iteration int, k string, v PossibleSettingValue, rejected string) {
+		if report == nil {
+			return
+		}
+		report.Steps = append(report.Steps, ShuffleStep{
+			Iteration: iteration,
+			Setting:   k,
+			Value:     v.Value,
+			Cost:      v.Cost,
+			TotalCost: costSum,
+			Implies:   v.Implies,
+			Rejected:  rejected,
+		})
+	}
 
 	// Make map iteration deterministic
-	keys := make([]string, 0, len(s))
-	for k := range s {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+	keys := s.sortedNames()
 
 	// iterate until we matched our budget or we failed to match it
 	for abs(costMax-costSum) > tolerance && iterations < maxIterations {
@@ -95,87 +283,612 @@ func (s Settings) Shuffle(seedStr string, costMax int) map[string]interface{} {
 
 		for _, k := range keys { // iterate over all settings
 			// Already decided on a value for this setting on a previous iteration.
-			if _, ok := ret[k]; ok {
+			if _, ok := st.ret[k]; ok {
 				continue
 			}
 
-			for i := range s[k] { // iterate over all possible values
-				if s[k][i].Weight <= 0 {
+			for i := range s.Values[k] { // iterate over all possible values
+				value := s.Values[k][i]
+				if value.Weight <= 0 {
 					continue
 				}
 
-				p := s[k][i].Weight / weightSum
+				p := value.Weight / weightSum
 				if r.Float64() > p { // not selected, ignore
 					continue
 				}
 
 				// Over cost budget, ignore
-				newCost := costSum + s[k][i].Cost
+				newCost := costSum + value.Cost
 				if newCost > (costMax - tolerance) {
+					step(iterations, k, value, "over budget")
+					continue
+				}
+
+				if reason := st.rejectReason(k, value); reason != "" {
+					step(iterations, k, value, reason)
 					continue
 				}
 
 				// Selected, set value and update cost.
 				costSum = newCost
-				ret[k] = s[k][i].Value
+				st.pick(k, value)
+				step(iterations, k, value, "")
 
-				// Avoid conflicting settings
-				for impliedKey, impliedValue := range s[k][i].Implies {
-					ret[impliedKey] = impliedValue
-				}
+				break
+			}
+		}
+
+		tolerance = iterations / toleranceStep
+		iterations++
+	}
+
+	hitIterationLimit := iterations >= maxIterations && abs(costMax-costSum) > tolerance
+	forced := st.fillGroupsMin(r)
+	for _, v := range forced {
+		costSum += v.value.Cost
+		step(iterations, v.name, v.value, "")
+		if report != nil {
+			report.Steps[len(report.Steps)-1].Forced = true
+		}
+	}
+
+	if report != nil {
+		report.Cost = costSum
+		report.CostMax = costMax
+		report.Iterations = iterations
+		report.HitIterationLimit = hitIterationLimit
+	}
+
+	return st.ret
+}
+
+func (s Settings) sortedNames() []string {
+	keys := make([]string, 0, len(s.Values))
+	for k := range s.Values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// shuffleState tracks the values picked by Shuffle and the constraints they
+// impose on the next picks.
+type shuffleState struct {
+	settings Settings
+
+	ret    map[string]interface{} // picked and implied values
+	picked map[string]struct{}    // settings picked by the shuffle, used for Groups
+
+	// excluded holds the values that can no longer be set, an empty slice
+	// excludes all values.
+	excluded map[string][]interface{}
+}
+
+func newShuffleState(s Settings) *shuffleState {
+	return &shuffleState{
+		settings: s,
+		ret:      map[string]interface{}{},
+		picked:   map[string]struct{}{},
+		excluded: map[string][]interface{}{},
+	}
+}
+
+func (st *shuffleState) isExcluded(name string, value interface{}) bool {
+	values, ok := st.excluded[name]
+	return ok && (len(values) == 0 || containsValue(values, value))
+}
+
+// canPick returns true if the value satisfies all its constraints given the
+// values that were already picked or implied.
+func (st *shuffleState) canPick(name string, v PossibleSettingValue) bool {
+	return st.rejectReason(name, v) == ""
+}
+
+// rejectReason returns why the value cannot be picked given the values that
+// were already picked or implied, or an empty string if it can be picked.
+func (st *shuffleState) rejectReason(name string, v PossibleSettingValue) string {
+	if st.isExcluded(name, v.Value) {
+		return "excluded by a previous pick"
+	}
+
+	for other, values := range v.Excludes {
+		if current, ok := st.ret[other]; ok && (len(values) == 0 || containsValue(values, current)) {
+			return fmt.Sprintf("excludes %s=%v", other, current)
+		}
+	}
+
+	for other, required := range v.Requires {
+		if current, ok := st.ret[other]; !ok || !valuesEqual(current, required) {
+			return fmt.Sprintf("requires %s=%v", other, required)
+		}
+	}
+
+	// Implied values must not contradict what has already been decided.
+	for other, implied := range v.Implies {
+		if current, ok := st.ret[other]; ok && !valuesEqual(current, implied) {
+			return fmt.Sprintf("implies %s=%v but it is %v", other, implied, current)
+		}
+		if st.isExcluded(other, implied) {
+			return fmt.Sprintf("implies %s=%v which is excluded", other, implied)
+		}
+	}
+
+	for _, g := range st.settings.Groups {
+		if max := g.max(); max > 0 && g.contains(name) && st.countPicked(g) >= max {
+			return fmt.Sprintf("group %s is full", g.Name)
+		}
+	}
 
-				// Disable other bridge settings if we picked one.
-				handleBridgeSettings(k, s[k][i].Value, ret)
+	return ""
+}
+
+func (st *shuffleState) pick(name string, v PossibleSettingValue) {
+	st.ret[name] = v.Value
+	st.picked[name] = struct{}{}
+
+	for other, implied := range v.Implies {
+		st.ret[other] = implied
+	}
+	zeroUnusedBridgeSettings(name, v.Value, st.ret)
+
+	for other, values := range v.Excludes {
+		if len(values) == 0 {
+			st.excluded[other] = []interface{}{}
+			continue
+		}
+		if current, ok := st.excluded[other]; !ok || len(current) > 0 {
+			st.excluded[other] = append(current, values...)
+		}
+	}
+}
+
+// bridgeSettings hold the count of each type of rewards required by the
+// rainbow bridge.
+var bridgeSettings = []string{"bridge_stones", "bridge_medallions", "bridge_rewards", "bridge_tokens"}
+
+// zeroUnusedBridgeSettings sets the bridge counts that were not picked to 0
+// once the bridge is decided so they cannot be picked later and are always
+// part of the output.
+func zeroUnusedBridgeSettings(name string, value interface{}, ret map[string]interface{}) {
+	switch {
+	case name == "bridge":
+		if value != "open" && value != "vanilla" {
+			return
+		}
+	case !strings.HasPrefix(name, "bridge_"):
+		return
+	}
+
+	for _, v := range bridgeSettings {
+		if _, ok := ret[v]; !ok {
+			ret[v] = 0
+		}
+	}
+}
+
+func (st *shuffleState) countPicked(g Group) int {
+	var count int
+	for _, v := range g.Settings {
+		if _, ok := st.picked[v]; ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+type namedValue struct {
+	name  string
+	value PossibleSettingValue
+}
 
+// fillGroupsMin picks additional settings in the groups that did not reach
+// their minimum, regardless of the cost budget, and returns them.
+func (st *shuffleState) fillGroupsMin(r *rand.Rand) []namedValue {
+	var ret []namedValue
+	for _, g := range st.settings.Groups {
+		if st.countPicked(g) >= g.Min {
+			continue
+		}
+
+		names := append([]string(nil), g.Settings...)
+		sort.Strings(names)
+		r.Shuffle(len(names), func(i, j int) {
+			names[i], names[j] = names[j], names[i]
+		})
+
+		for _, name := range names {
+			if st.countPicked(g) >= g.Min {
 				break
 			}
+			if _, ok := st.ret[name]; ok {
+				continue
+			}
+
+			if v, ok := st.pickWeighted(r, name); ok {
+				st.pick(name, v)
+				ret = append(ret, namedValue{name, v})
+			}
 		}
 
-		tolerance = iterations / 100
-		iterations++
+		if count := st.countPicked(g); count < g.Min {
+			log.Printf("warning: could only pick %d/%d settings of group %s", count, g.Min, g.Name)
+		}
 	}
 
 	return ret
 }
 
-// handleBridgeSettings is a magic/auto Implies statement to disable all other
-// bridge settings once one has been picked.
-func handleBridgeSettings(name string, value interface{}, ret map[string]interface{}) {
-	if !strings.HasPrefix(name, "bridge") {
-		return
+// pickWeighted returns a random value of the setting among those that can be
+// picked, with respect to their weight.
+func (st *shuffleState) pickWeighted(r *rand.Rand, name string) (PossibleSettingValue, bool) {
+	var (
+		candidates []PossibleSettingValue
+		sum        float64
+	)
+	for _, v := range st.settings.Values[name] {
+		if v.Weight > 0 && st.canPick(name, v) {
+			candidates = append(candidates, v)
+			sum += v.Weight
+		}
+	}
+
+	if len(candidates) == 0 {
+		return PossibleSettingValue{}, false
+	}
+
+	x := r.Float64() * sum
+	for _, v := range candidates {
+		if x < v.Weight {
+			return v, true
+		}
+		x -= v.Weight
+	}
+
+	return candidates[len(candidates)-1], true
+}
+
+// Validate reports the constraints that can never be satisfied.
+func (s Settings) Validate() error { // nolint:funlen,gocognit
+	var errs []string
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	// All the values a setting can take, either by being picked or implied.
+	reachable := map[string][]interface{}{}
+	for k, possible := range s.Values {
+		for _, v := range possible {
+			reachable[k] = append(reachable[k], v.Value)
+			for implied, value := range v.Implies {
+				reachable[implied] = append(reachable[implied], value)
+			}
+		}
+	}
+
+	for _, k := range s.sortedNames() {
+		for _, v := range s.Values[k] {
+			where := fmt.Sprintf("%s=%v", k, v.Value)
+
+			if _, ok := v.Implies[k]; ok {
+				addErr("%s: cannot imply its own setting", where)
+			}
+
+			for other, required := range v.Requires {
+				switch {
+				case other == k:
+					addErr("%s: cannot require its own setting", where)
+				case !containsValue(reachable[other], required):
+					addErr("%s: requires %s=%v which can never be set", where, other, required)
+				}
+
+				if implied, ok := v.Implies[other]; ok && !valuesEqual(implied, required) {
+					addErr("%s: requires %s=%v but implies %s=%v", where, other, required, other, implied)
+				}
+
+				if excluded, ok := v.Excludes[other]; ok && (len(excluded) == 0 || containsValue(excluded, required)) {
+					addErr("%s: both requires and excludes %s=%v", where, other, required)
+				}
+			}
+
+			for other, excluded := range v.Excludes {
+				if other == k {
+					addErr("%s: cannot exclude its own setting", where)
+				}
+
+				if implied, ok := v.Implies[other]; ok && (len(excluded) == 0 || containsValue(excluded, implied)) {
+					addErr("%s: both implies and excludes %s=%v", where, other, implied)
+				}
+			}
+		}
+	}
+
+	if p := s.Parameters; p.CostBudget < 0 || p.MaxIterations < 0 || p.ToleranceStep < 0 {
+		addErr("parameters cannot be negative")
 	}
 
-	// If we manually set bridge, ensure we're not picking any other bridge setting.
-	if name == "bridge" {
-		vStr, ok := value.(string)
+	for _, cycle := range s.requiresCycles() {
+		addErr("circular requirement: %s", strings.Join(cycle, " -> "))
+	}
+
+	names := map[string]struct{}{}
+	for _, g := range s.Groups {
+		if g.Name == "" {
+			addErr("group with settings %v has no name", g.Settings)
+		}
+		if _, ok := names[g.Name]; ok {
+			addErr("group %s: defined twice", g.Name)
+		}
+		names[g.Name] = struct{}{}
+
+		for _, v := range g.Settings {
+			if _, ok := s.Values[v]; !ok {
+				addErr("group %s: unknown setting %s", g.Name, v)
+			}
+		}
+
+		switch {
+		case g.Min < 0 || g.Max < 0:
+			addErr("group %s: Min and Max cannot be negative", g.Name)
+		case g.Exclusive && g.Max > 1:
+			addErr("group %s: cannot be Exclusive with Max %d", g.Name, g.Max)
+		case g.max() > 0 && g.Min > g.max():
+			addErr("group %s: Min %d is greater than Max %d", g.Name, g.Min, g.max())
+		case g.Min > len(g.Settings):
+			addErr("group %s: Min %d is greater than its %d settings", g.Name, g.Min, len(g.Settings))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid shuffled settings:\n%s", strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// UnreachableValues returns the values that can never be set by Shuffle
+// using the given cost budget, either picked or implied.
+// A value is only considered reachable if its Requires can be reached within
+// the budget, if the required values do not exclude it, and if its Groups
+// have room for it and its required settings.
+func (s Settings) UnreachableValues(costMax int) []string { // nolint:funlen,gocognit
+	forced := map[string]bool{} // settings that can be picked regardless of the budget
+	for _, g := range s.Groups {
+		if g.Min > 0 {
+			for _, v := range g.Settings {
+				forced[v] = true
+			}
+		}
+	}
+
+	reachable := map[string][]reachedValue{}
+	find := func(name string, value interface{}) (reachedValue, bool) {
+		for _, v := range reachable[name] {
+			if valuesEqual(v.value, value) {
+				return v, true
+			}
+		}
+		return reachedValue{}, false
+	}
+	// reach records a value and returns true if it is new or cheaper.
+	reach := func(name string, v reachedValue) bool {
+		for i, current := range reachable[name] {
+			if !valuesEqual(current.value, v.value) {
+				continue
+			}
+			if current.cost <= v.cost && (current.implied || !v.implied) {
+				return false
+			}
+			if v.cost < current.cost {
+				reachable[name][i].cost = v.cost
+			}
+			reachable[name][i].implied = current.implied || v.implied
+			return true
+		}
+		reachable[name] = append(reachable[name], v)
+		return true
+	}
+
+	reasons := map[string]string{}
+	// Requirements may be reached after the values requiring them, iterate
+	// until nothing changes.
+	for changed := true; changed; {
+		changed = false
+		for _, k := range s.sortedNames() {
+			for _, v := range s.Values[k] {
+				if v.Weight <= 0 {
+					continue
+				}
+
+				cost, reason := s.reachCost(k, v, find)
+				if reason == "" && cost > costMax && !forced[k] {
+					if v.Cost > costMax {
+						reason = fmt.Sprintf("cost %d is over the budget of %d", v.Cost, costMax)
+					} else {
+						reason = fmt.Sprintf("cost %d with its requirements is over the budget of %d", cost, costMax)
+					}
+				}
+				if reason != "" {
+					reasons[fmt.Sprintf("%s=%v", k, v.Value)] = reason
+					continue
+				}
+
+				if reach(k, reachedValue{value: v.Value, cost: cost}) {
+					changed = true
+				}
+				for implied, value := range v.Implies {
+					if reach(implied, reachedValue{value: value, cost: cost, implied: true}) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	var ret []string
+	for _, k := range s.sortedNames() {
+		for _, v := range s.Values[k] {
+			if _, ok := find(k, v.Value); ok {
+				continue
+			}
+
+			reason := "zero weight and never implied"
+			if v.Weight > 0 {
+				reason = reasons[fmt.Sprintf("%s=%v", k, v.Value)]
+			}
+			ret = append(ret, fmt.Sprintf("%s=%v: %s", k, v.Value, reason))
+		}
+	}
+
+	return ret
+}
+
+// reachedValue is a value reachable by Shuffle along with the minimum cost to
+// reach it. Implied values do not count towards the Groups.
+type reachedValue struct {
+	value   interface{}
+	cost    int
+	implied bool
+}
+
+// reachCost returns the minimum cost of picking a value along with its
+// requirements given the currently reachable values, or the reason why it
+// cannot be picked.
+func (s Settings) reachCost(
+	name string, v PossibleSettingValue,
+	find func(string, interface{}) (reachedValue, bool),
+) (int, string) {
+	cost := v.Cost
+	others := make([]string, 0, len(v.Requires))
+	for other := range v.Requires {
+		others = append(others, other)
+	}
+	sort.Strings(others)
+
+	picked := map[string]struct{}{name: {}} // settings counting towards the Groups
+	for _, other := range others {
+		required := v.Requires[other]
+		reached, ok := find(other, required)
 		if !ok {
-			log.Printf("error: 'bridge' set with non-string value '%v'", value)
-			return
+			return 0, fmt.Sprintf("requires %s=%v which is unreachable", other, required)
 		}
-		if vStr != "open" && vStr != "vanilla" {
-			log.Printf("error: 'bridge' can only be set to 'open' or 'vanilla', '%s' provided", vStr)
-			return
+		cost += reached.cost
+		if !reached.implied {
+			picked[other] = struct{}{}
+		}
+
+		for _, candidate := range s.Values[other] {
+			if !valuesEqual(candidate.Value, required) {
+				continue
+			}
+			if excluded, ok := candidate.Excludes[name]; ok && (len(excluded) == 0 || containsValue(excluded, v.Value)) {
+				return 0, fmt.Sprintf("excluded by its requirement %s=%v", other, required)
+			}
+			for implied, value := range v.Implies {
+				if excluded, ok := candidate.Excludes[implied]; ok && (len(excluded) == 0 || containsValue(excluded, value)) {
+					return 0, fmt.Sprintf("implies %s=%v which is excluded by its requirement %s=%v", implied, value, other, required)
+				}
+			}
+		}
+	}
+
+	for _, g := range s.Groups {
+		if !g.contains(name) {
+			continue
+		}
+
+		var count int
+		for k := range picked {
+			if g.contains(k) {
+				count++
+			}
+		}
+		if max := g.max(); max > 0 && count > max {
+			return 0, fmt.Sprintf("requires %d settings of group %s which allows %d", count, g.Name, max)
+		}
+	}
+
+	return cost, ""
+}
+
+// requiresCycles returns the chains of values requiring each other, none of
+// the values in a cycle can ever be picked.
+func (s Settings) requiresCycles() [][]string {
+	type node struct {
+		name  string
+		value interface{}
+	}
+	str := func(n node) string { return fmt.Sprintf("%s=%v", n.name, n.value) }
+
+	edges := map[string][]node{}
+	var nodes []node
+	for _, k := range s.sortedNames() {
+		for _, v := range s.Values[k] {
+			n := node{k, v.Value}
+			nodes = append(nodes, n)
+
+			others := make([]string, 0, len(v.Requires))
+			for other := range v.Requires {
+				others = append(others, other)
+			}
+			sort.Strings(others)
+			for _, other := range others {
+				edges[str(n)] = append(edges[str(n)], node{other, v.Requires[other]})
+			}
 		}
-		ret["bridge_tokens"] = 0
-		ret["bridge_stones"] = 0
-		ret["bridge_rewards"] = 0
-		ret["bridge_medallions"] = 0
-		return
 	}
 
-	typ := strings.Split(name, "_")[1] // will panic on bad value, 'tis ok.
-	if typ == "rewards" {
-		ret["bridge"] = "dungeons" // OOTR and consistency…
-	} else {
-		ret["bridge"] = typ
+	var (
+		cycles  [][]string
+		done    = map[string]bool{}
+		onStack = map[string]int{}
+		stack   []string
+		visit   func(string)
+	)
+	visit = func(n string) {
+		onStack[n] = len(stack)
+		stack = append(stack, n)
+		for _, next := range edges[n] {
+			key := str(next)
+			if i, ok := onStack[key]; ok {
+				cycles = append(cycles, append(append([]string(nil), stack[i:]...), key))
+				continue
+			}
+			if !done[key] {
+				visit(key)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		delete(onStack, n)
+		done[n] = true
 	}
 
-	// Set all other settings to 0 to ensure they cannot be picked later.
-	for _, v := range []string{"stones", "medallions", "rewards", "tokens"} {
-		if v != typ {
-			ret["bridge_"+v] = 0
+	for _, n := range nodes {
+		if !done[str(n)] {
+			visit(str(n))
 		}
 	}
+
+	return cycles
+}
+
+// valuesEqual compares two setting values as they would be written in JSON.
+func valuesEqual(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if valuesEqual(v, value) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // A Setting is a collection of values that can be given to a setting key.
@@ -184,9 +897,14 @@ type Setting []PossibleSettingValue
 // A PossibleSettingValue has a cost that represents its impact on routing, a
 // weight to make it appear less or more often, and a list of implied settings
 // k/v to avoid impossible settings combo or force interesting combinations.
+// Requires and Excludes prevent the value from being picked alongside other
+// values, Excludes maps a setting to its excluded values, an empty list
+// excludes any value.
 type PossibleSettingValue struct {
-	Value   interface{}
-	Cost    int
-	Weight  float64
-	Implies map[string]interface{}
+	Value    interface{}
+	Cost     int
+	Weight   float64
+	Implies  map[string]interface{}
+	Requires map[string]interface{}
+	Excludes map[string][]interface{}
 }
@@ -1,9 +1,12 @@
 package settings_test
 
 import (
+	"io/ioutil"
 	"kaepora/internal/generator/oot/settings"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -18,30 +21,258 @@ func TestShuffle(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if len(s) == 0 {
+	if len(s.Values) == 0 {
 		t.Fatal("empty settings")
 	}
 
-	shuf1 := s.Shuffle("seed", 20)
+	s.Parameters.CostBudget = 20
+	shuf1 := s.Shuffle("seed")
 	if len(shuf1) == 0 {
 		t.Error("empty shuffled settings")
 	}
-	if len(shuf1) == len(s) {
+	if len(shuf1) == len(s.Values) {
 		t.Error("too many settings")
 	}
 
-	shuf2 := s.Shuffle("seed", 20)
+	shuf2 := s.Shuffle("seed")
 	if !reflect.DeepEqual(shuf1, shuf2) {
 		t.Error("same seed produced different settings")
 	}
 
-	shuf3 := s.Shuffle("Seed", 20)
+	shuf3 := s.Shuffle("Seed")
 	if reflect.DeepEqual(shuf2, shuf3) {
 		t.Error("different seed produced same settings")
 	}
 
-	shuf4 := s.Shuffle("Seed", 40)
+	s.Parameters.CostBudget = 40
+	shuf4 := s.Shuffle("Seed")
 	if reflect.DeepEqual(shuf3, shuf4) {
 		t.Error("diffent weights produced same settings")
 	}
 }
+
+func TestShuffleConstraints(t *testing.T) {
+	s := settings.Settings{
+		Values: map[string]settings.Setting{
+			"a": {{Value: "x", Cost: 1, Weight: 1}, {Value: "y", Cost: 1, Weight: 1}},
+			"b": {{Value: true, Cost: 1, Weight: 1, Requires: map[string]interface{}{"a": "x"}}},
+			"c": {{Value: 1, Cost: 1, Weight: 1, Excludes: map[string][]interface{}{"a": {"y"}}}},
+			"d": {{Value: 1, Cost: 1, Weight: 1}},
+			"e": {{Value: 1, Cost: 1, Weight: 1}},
+			"f": {{Value: 1, Cost: 1, Weight: 1}},
+		},
+		Groups: []settings.Group{
+			{Name: "exclusive", Settings: []string{"d", "e"}, Exclusive: true},
+			{Name: "min", Settings: []string{"f"}, Min: 1},
+		},
+		Parameters: settings.Parameters{CostBudget: 4},
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		seed := strconv.Itoa(i)
+		shuf := s.Shuffle(seed)
+		if !reflect.DeepEqual(shuf, s.Shuffle(seed)) {
+			t.Fatalf("seed %s produced different settings", seed)
+		}
+
+		if _, ok := shuf["b"]; ok && shuf["a"] != "x" {
+			t.Errorf("seed %s: b picked without a=x: %v", seed, shuf)
+		}
+		if _, ok := shuf["c"]; ok && shuf["a"] == "y" {
+			t.Errorf("seed %s: c picked with a=y: %v", seed, shuf)
+		}
+		_, d := shuf["d"]
+		_, e := shuf["e"]
+		if d && e {
+			t.Errorf("seed %s: d and e picked in an exclusive group: %v", seed, shuf)
+		}
+		if _, ok := shuf["f"]; !ok {
+			t.Errorf("seed %s: f not picked despite group minimum: %v", seed, shuf)
+		}
+	}
+}
+
+func TestShuffleBridgeSettings(t *testing.T) {
+	s := settings.Settings{
+		Values: map[string]settings.Setting{
+			"bridge_stones": {{Value: 2, Cost: 1, Weight: 1, Implies: map[string]interface{}{"bridge": "stones"}}},
+			"bridge_tokens": {{Value: 10, Cost: 1, Weight: 1, Implies: map[string]interface{}{"bridge": "tokens"}}},
+		},
+		Groups: []settings.Group{
+			{Name: "bridge", Settings: []string{"bridge_stones", "bridge_tokens"}, Exclusive: true},
+		},
+		Parameters: settings.Parameters{CostBudget: 1},
+	}
+
+	for i := 0; i < 100; i++ {
+		shuf := s.Shuffle(strconv.Itoa(i))
+		var picked string
+		for _, v := range []string{"stones", "tokens"} {
+			if shuf["bridge"] == v {
+				picked = "bridge_" + v
+			}
+		}
+		if picked == "" {
+			t.Fatalf("no bridge picked: %v", shuf)
+		}
+
+		for _, v := range []string{"bridge_stones", "bridge_medallions", "bridge_rewards", "bridge_tokens"} {
+			if v != picked && shuf[v] != 0 {
+				t.Errorf("expected unused %s to be set to 0: %v", v, shuf)
+			}
+		}
+	}
+
+	open := settings.Settings{Values: map[string]settings.Setting{
+		"bridge": {{Value: "open", Cost: 1, Weight: 1}},
+	}, Parameters: settings.Parameters{CostBudget: 1}}
+	shuf := open.Shuffle("seed")
+	for _, v := range []string{"bridge_stones", "bridge_medallions", "bridge_rewards", "bridge_tokens"} {
+		if shuf[v] != 0 {
+			t.Errorf("expected %s to be set to 0 with an open bridge: %v", v, shuf)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := settings.Settings{
+		Values: map[string]settings.Setting{
+			"a": {{Value: 1, Requires: map[string]interface{}{"b": 1}}},
+			"b": {{Value: 1, Requires: map[string]interface{}{"a": 1}}},
+			"c": {{Value: 1, Requires: map[string]interface{}{"a": 2}}},
+			"d": {{
+				Value:    1,
+				Implies:  map[string]interface{}{"a": 1},
+				Excludes: map[string][]interface{}{"a": nil},
+			}},
+		},
+		Groups: []settings.Group{
+			{Name: "g", Settings: []string{"a", "z"}, Min: 2, Max: 1},
+		},
+	}
+
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	for _, expected := range []string{
+		"circular requirement",
+		"c=1: requires a=2 which can never be set",
+		"d=1: both implies and excludes a=1",
+		"group g: unknown setting z",
+		"group g: Min 2 is greater than Max 1",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q, got:\n%s", expected, err)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	s := settings.Settings{
+		Values: map[string]settings.Setting{
+			"a": {{Value: 1, Cost: 2, Weight: 1, Implies: map[string]interface{}{"b": 2}}},
+			"b": {{Value: 1, Cost: 1, Weight: 0}, {Value: 2, Cost: 1, Weight: 0}, {Value: 3, Cost: 1, Weight: 1}},
+			"c": {{Value: 1, Cost: 50, Weight: 1}},
+		},
+		Parameters: settings.Parameters{CostBudget: 2},
+	}
+
+	shuf, report := s.Explain("seed")
+	if !reflect.DeepEqual(shuf, s.Shuffle("seed")) {
+		t.Error("Explain and Shuffle returned different settings")
+	}
+	if report.Cost > 2 || report.Overshoot() != 0 {
+		t.Errorf("unexpected cost %d", report.Cost)
+	}
+
+	var picked int
+	for _, v := range report.Steps {
+		if v.Rejected == "" {
+			picked++
+		}
+	}
+	if picked == 0 {
+		t.Error("no picked step reported")
+	}
+
+	expected := []string{
+		"b=1: zero weight and never implied",
+		"c=1: cost 50 is over the budget of 2",
+	}
+	if actual := s.UnreachableValues(2); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("unexpected unreachable values\nexpected: %v\nactual  : %v", expected, actual)
+	}
+}
+
+func TestUnreachableValuesConstraints(t *testing.T) {
+	s := settings.Settings{
+		Values: map[string]settings.Setting{
+			"a": {
+				{Value: "x", Cost: 1, Weight: 1},
+				{Value: "y", Cost: 1, Weight: 1, Excludes: map[string][]interface{}{"b": nil}},
+			},
+			"b": {{Value: 1, Cost: 1, Weight: 1, Requires: map[string]interface{}{"a": "y"}}},
+			"c": {{Value: 1, Cost: 1, Weight: 1, Requires: map[string]interface{}{"a": "x"}}},
+			"d": {{Value: 1, Cost: 2, Weight: 1, Requires: map[string]interface{}{"a": "x"}}},
+			"e": {{Value: 1, Cost: 0, Weight: 1, Requires: map[string]interface{}{"b": 1}}},
+			"f": {{Value: 1, Cost: 1, Weight: 1}},
+			"g": {{Value: 1, Cost: 1, Weight: 1, Requires: map[string]interface{}{"f": 1}}},
+		},
+		Groups: []settings.Group{
+			{Name: "exclusive", Settings: []string{"f", "g"}, Exclusive: true},
+		},
+	}
+
+	expected := []string{
+		"b=1: excluded by its requirement a=y",
+		"d=1: cost 3 with its requirements is over the budget of 2",
+		"e=1: requires b=1 which is unreachable",
+		"g=1: requires 2 settings of group exclusive which allows 1",
+	}
+	if actual := s.UnreachableValues(2); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("unexpected unreachable values\nexpected: %v\nactual  : %v", expected, actual)
+	}
+}
+
+func TestParameters(t *testing.T) {
+	f, err := ioutil.TempFile("", "kaepora-shuffled-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(`{
+		"a": [{"Value": 1, "Cost": 50, "Weight": 1}],
+		"$parameters": {"CostBudget": 5, "MaxIterations": 3}
+	}`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	s, err := settings.Load(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Parameters.GetCostBudget() != 5 || s.Parameters.GetMaxIterations() != 3 {
+		t.Errorf("unexpected parameters %+v", s.Parameters)
+	}
+	if s.Parameters.GetToleranceStep() != settings.DefaultToleranceStep {
+		t.Errorf("expected default tolerance step, got %d", s.Parameters.GetToleranceStep())
+	}
+
+	_, report := s.Explain("seed")
+	if report.Iterations != 3 || !report.HitIterationLimit {
+		t.Errorf("expected the iteration limit to be hit after 3 iterations, got %d", report.Iterations)
+	}
+
+	s.Parameters.CostBudget = -1
+	if err := s.Validate(); err == nil {
+		t.Error("expected negative parameters to be rejected")
+	}
+}
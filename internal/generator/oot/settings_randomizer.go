@@ -7,45 +7,59 @@ import (
 	"kaepora/internal/generator"
 	"kaepora/internal/generator/oot/settings"
 	"os"
-	"path/filepath"
 	"strings"
 
 	jsonpatch "github.com/evanphx/json-patch"
 )
 
-const (
-	SettingsRandomizerName = "oot-settings-randomizer"
-	SettingsCostBudget     = 20
-)
+const SettingsRandomizerName = "oot-settings-randomizer"
 
 // SettingsRandomizer is the "Shuffled Settings" using the local OOTR.
 type SettingsRandomizer struct {
 	oot *Randomizer
 }
 
-func NewSettingsRandomizer(version string) *SettingsRandomizer {
+func NewSettingsRandomizer(version string, runner Runner) *SettingsRandomizer {
 	return &SettingsRandomizer{
-		oot: NewRandomizer(version),
+		oot: NewRandomizer(version, runner),
 	}
 }
 
+// getShuffledSettings shuffles the settings using the budget set in the
+// shuffled settings file.
 func getShuffledSettings(
-	seed string, cost int,
+	seed string,
 	baseDir, shuffledSettingsName string,
 ) (map[string]interface{}, error) {
-	s, err := settings.Load(filepath.Join(baseDir, shuffledSettingsName))
+	s, err := settings.Load(generator.SettingsPath(baseDir, shuffledSettingsName))
 	if err != nil {
 		return nil, err
 	}
 
-	return s.Shuffle(seed, cost), nil
+	return s.Shuffle(seed), nil
+}
+
+// LoadShuffledSettings loads the shuffled settings of a League.Settings value
+// in the form '<basefile.json>:<shuffled.json>'.
+func LoadShuffledSettings(combinedSettingsName string) (settings.Settings, error) {
+	baseDir, err := GetBaseDir()
+	if err != nil {
+		return settings.Settings{}, err
+	}
+
+	_, shuffledSettingsName, err := getBaseAndShuffledFromCombinedSettings(combinedSettingsName)
+	if err != nil {
+		return settings.Settings{}, err
+	}
+
+	return settings.Load(generator.SettingsPath(baseDir, shuffledSettingsName))
 }
 
 func getMergedShuffledSettingsJSON(
 	settings map[string]interface{},
 	base, baseSettingsName string,
 ) ([]byte, error) {
-	original, err := ioutil.ReadFile(filepath.Join(base, baseSettingsName))
+	original, err := ioutil.ReadFile(generator.SettingsPath(base, baseSettingsName))
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +77,17 @@ func getMergedShuffledSettingsJSON(
 	return patched, nil
 }
 
+// MergeShuffledSettings returns the base settings file patched with the
+// shuffled settings, as given to the randomizer.
+func MergeShuffledSettings(settings map[string]interface{}, baseSettingsName string) ([]byte, error) {
+	baseDir, err := GetBaseDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return getMergedShuffledSettingsJSON(settings, baseDir, baseSettingsName)
+}
+
 func getMergedShuffledSettingsPath(
 	settings map[string]interface{},
 	baseDir, baseSettingsName string,
@@ -111,7 +136,7 @@ func (r *SettingsRandomizer) Generate(
 		return generator.Output{}, err
 	}
 
-	settings, err := getShuffledSettings(seed, SettingsCostBudget, baseDir, shuffledSettingsName)
+	settings, err := getShuffledSettings(seed, baseDir, shuffledSettingsName)
 	if err != nil {
 		return generator.Output{}, err
 	}
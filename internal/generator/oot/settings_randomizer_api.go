@@ -30,7 +30,7 @@ func (r *SettingsRandomizerAPI) Generate(combinedSettingsName, seed string) (gen
 		return generator.Output{}, err
 	}
 
-	settingsDiff, err := getShuffledSettings(seed, SettingsCostBudget, baseDir, shuffledSettingsName)
+	settingsDiff, err := getShuffledSettings(seed, baseDir, shuffledSettingsName)
 	if err != nil {
 		return generator.Output{}, err
 	}
@@ -12,7 +12,7 @@ import (
 func TestOOTSettingsRandomizer(t *testing.T) {
 	t.Parallel()
 
-	f := factory.New(nil)
+	f := factory.New(nil, nil, nil)
 	g, err := f.NewGenerator(oot.SettingsRandomizerName + ":5.2.13")
 	if err != nil {
 		t.Fatal(err)
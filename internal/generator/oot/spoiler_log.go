@@ -63,6 +63,36 @@ func (e Exit) Exit() string {
 	return parts[1]
 }
 
+// EntranceDestination returns the region a shuffled entrance leads to. The
+// spoiler log holds either the region name or an object with the region and
+// the region it is entered from.
+func EntranceDestination(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		region, _ := v["region"].(string)
+		if from, ok := v["from"].(string); ok {
+			return fmt.Sprintf("%s (from %s)", region, from)
+		}
+
+		return region
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// EntranceRegion is the same as EntranceDestination without the region the
+// entrance is entered from.
+func EntranceRegion(v interface{}) string {
+	if m, ok := v.(map[string]interface{}); ok {
+		region, _ := m["region"].(string)
+		return region
+	}
+
+	return EntranceDestination(v)
+}
+
 // Spheres returns the playthrough as an ordered slice. The playthrough is
 // returned as a map with numeric keys a string which makes iterating over it
 // in order impossible.
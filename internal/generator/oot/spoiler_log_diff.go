@@ -0,0 +1,233 @@
+package oot
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SpoilerLogDiff holds the differences between two spoiler logs, A and B.
+type SpoilerLogDiff struct {
+	Settings    []SettingDiff
+	MovedItems  []ItemMove
+	WOTH        SetDiff
+	Barren      SetDiff
+	Spheres     []SphereChange
+	SphereDepth [2]int
+}
+
+// SettingDiff is a setting having different values in A and B, a missing
+// setting has an empty value.
+type SettingDiff struct {
+	Name string
+	A, B string
+}
+
+// ItemMove is an item placed in different locations in A and B.
+type ItemMove struct {
+	Item SpoilerLogItem
+	A, B []SpoilerLogLocation
+}
+
+// SetDiff holds the elements found in only one of A and B.
+type SetDiff struct {
+	OnlyA, OnlyB []string
+}
+
+// IsEmpty returns true if both sets were the same.
+func (d SetDiff) IsEmpty() bool {
+	return len(d.OnlyA) == 0 && len(d.OnlyB) == 0
+}
+
+// SphereChange is an item first found in a different sphere in A and B, 0 if
+// the item is not part of the playthrough.
+type SphereChange struct {
+	Item SpoilerLogItem
+	A, B int
+}
+
+// IsEmpty returns true if both spoiler logs had the same settings, placements,
+// and playthrough.
+func (d SpoilerLogDiff) IsEmpty() bool {
+	return len(d.Settings) == 0 && len(d.MovedItems) == 0 &&
+		d.WOTH.IsEmpty() && d.Barren.IsEmpty() &&
+		len(d.Spheres) == 0 && d.SphereDepth[0] == d.SphereDepth[1]
+}
+
+// DiffSpoilerLogs compares two spoiler logs. Junk items and keys are not
+// listed as moved items as they are too numerous to be meaningful.
+func DiffSpoilerLogs(a, b SpoilerLog) SpoilerLogDiff {
+	return SpoilerLogDiff{
+		Settings:    diffSettings(a.Settings, b.Settings),
+		MovedItems:  diffItemLocations(a.Locations, b.Locations),
+		WOTH:        diffSets(locationNames(a.WOTHLocations), locationNames(b.WOTHLocations)),
+		Barren:      diffSets(a.BarrenRegions, b.BarrenRegions),
+		Spheres:     diffSpheres(a, b),
+		SphereDepth: [2]int{len(a.Playthrough), len(b.Playthrough)},
+	}
+}
+
+func diffSettings(a, b map[string]interface{}) []SettingDiff {
+	names := map[string]struct{}{}
+	for k := range a {
+		names[k] = struct{}{}
+	}
+	for k := range b {
+		names[k] = struct{}{}
+	}
+
+	var ret []SettingDiff
+	for name := range names {
+		valueA, valueB := settingString(a, name), settingString(b, name)
+		if valueA != valueB {
+			ret = append(ret, SettingDiff{Name: name, A: valueA, B: valueB})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+
+	return ret
+}
+
+func settingString(m map[string]interface{}, name string) string {
+	v, ok := m[name]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+func diffItemLocations(a, b map[SpoilerLogLocation]SpoilerLogItem) []ItemMove {
+	locationsA, locationsB := itemLocations(a), itemLocations(b)
+	items := map[SpoilerLogItem]struct{}{}
+	for k := range locationsA {
+		items[k] = struct{}{}
+	}
+	for k := range locationsB {
+		items[k] = struct{}{}
+	}
+
+	var ret []ItemMove
+	for item := range items {
+		if !sameLocations(locationsA[item], locationsB[item]) {
+			ret = append(ret, ItemMove{Item: item, A: locationsA[item], B: locationsB[item]})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Item < ret[j].Item })
+
+	return ret
+}
+
+// itemLocations returns the sorted locations of each meaningful item.
+func itemLocations(m map[SpoilerLogLocation]SpoilerLogItem) map[SpoilerLogItem][]SpoilerLogLocation {
+	ret := map[SpoilerLogItem][]SpoilerLogLocation{}
+	for location, item := range m {
+		switch item.GetCategory() { // nolint:exhaustive
+		case SpoilerLogItemCategoryJunk, SpoilerLogItemCategoryIceTrap,
+			SpoilerLogItemCategorySmallKey, SpoilerLogItemCategoryBossKey,
+			SpoilerLogItemCategoryBombchu, SpoilerLogItemCategoryPoH:
+			continue
+		}
+
+		ret[item] = append(ret[item], location)
+	}
+
+	for k := range ret {
+		locations := ret[k]
+		sort.Slice(locations, func(i, j int) bool { return locations[i] < locations[j] })
+	}
+
+	return ret
+}
+
+func sameLocations(a, b []SpoilerLogLocation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func locationNames(m map[SpoilerLogLocation]SpoilerLogItem) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, string(k))
+	}
+
+	return ret
+}
+
+func diffSets(a, b []string) (ret SetDiff) {
+	inA, inB := map[string]struct{}{}, map[string]struct{}{}
+	for _, v := range a {
+		inA[v] = struct{}{}
+	}
+	for _, v := range b {
+		inB[v] = struct{}{}
+	}
+
+	for v := range inA {
+		if _, ok := inB[v]; !ok {
+			ret.OnlyA = append(ret.OnlyA, v)
+		}
+	}
+	for v := range inB {
+		if _, ok := inA[v]; !ok {
+			ret.OnlyB = append(ret.OnlyB, v)
+		}
+	}
+
+	sort.Strings(ret.OnlyA)
+	sort.Strings(ret.OnlyB)
+
+	return ret
+}
+
+func diffSpheres(a, b SpoilerLog) []SphereChange {
+	spheresA, spheresB := firstSpheres(a), firstSpheres(b)
+	items := map[SpoilerLogItem]struct{}{}
+	for k := range spheresA {
+		items[k] = struct{}{}
+	}
+	for k := range spheresB {
+		items[k] = struct{}{}
+	}
+
+	var ret []SphereChange
+	for item := range items {
+		if spheresA[item] != spheresB[item] {
+			ret = append(ret, SphereChange{Item: item, A: spheresA[item], B: spheresB[item]})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Item < ret[j].Item })
+
+	return ret
+}
+
+// firstSpheres returns the first sphere (1-indexed) each playthrough item
+// appears in, keys excluded.
+func firstSpheres(l SpoilerLog) map[SpoilerLogItem]int {
+	ret := map[SpoilerLogItem]int{}
+	for k, sphere := range l.Spheres() {
+		for _, item := range sphere {
+			switch item.GetCategory() { // nolint:exhaustive
+			case SpoilerLogItemCategorySmallKey, SpoilerLogItemCategoryBossKey:
+				continue
+			}
+
+			if _, ok := ret[item]; !ok {
+				ret[item] = k + 1
+			}
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,53 @@
+package oot_test
+
+import (
+	"encoding/json"
+	"kaepora/internal/generator/oot"
+	"reflect"
+	"testing"
+)
+
+func TestDiffSpoilerLogs(t *testing.T) {
+	parse := func(str string) oot.SpoilerLog {
+		var ret oot.SpoilerLog
+		if err := json.Unmarshal([]byte(str), &ret); err != nil {
+			t.Fatal(err)
+		}
+		return ret
+	}
+
+	a := parse(`{
+        "settings": {"shuffle_song_items": false, "bridge": "medallions"},
+        "locations": {"Song from Impa": "Zeldas Lullaby", "KF Midos Top Left Chest": "Bow", "LW Skull Kid": "Rupees (5)"},
+        ":woth_locations": {"KF Midos Top Left Chest": "Bow"},
+        ":barren_regions": ["Lost Woods"],
+        ":playthrough": {"1": {"KF Midos Top Left Chest": "Bow"}, "2": {"Song from Impa": "Zeldas Lullaby"}}
+    }`)
+	b := parse(`{
+        "settings": {"shuffle_song_items": true, "bridge": "medallions"},
+        "locations": {"Song from Impa": "Bow", "KF Midos Top Left Chest": "Zeldas Lullaby", "LW Skull Kid": "Recovery Heart"},
+        ":woth_locations": {"Song from Impa": "Bow"},
+        ":barren_regions": ["Lost Woods"],
+        ":playthrough": {"1": {"Song from Impa": "Bow"}}
+    }`)
+
+	expected := oot.SpoilerLogDiff{
+		Settings: []oot.SettingDiff{{Name: "shuffle_song_items", A: "false", B: "true"}},
+		MovedItems: []oot.ItemMove{
+			{Item: "Bow", A: []oot.SpoilerLogLocation{"KF Midos Top Left Chest"}, B: []oot.SpoilerLogLocation{"Song from Impa"}},
+			{Item: "Zeldas Lullaby", A: []oot.SpoilerLogLocation{"Song from Impa"}, B: []oot.SpoilerLogLocation{"KF Midos Top Left Chest"}},
+		},
+		WOTH:        oot.SetDiff{OnlyA: []string{"KF Midos Top Left Chest"}, OnlyB: []string{"Song from Impa"}},
+		Spheres:     []oot.SphereChange{{Item: "Zeldas Lullaby", A: 2, B: 0}},
+		SphereDepth: [2]int{2, 1},
+	}
+
+	actual := oot.DiffSpoilerLogs(a, b)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected\n%#v\ngot\n%#v", expected, actual)
+	}
+
+	if diff := oot.DiffSpoilerLogs(a, a); !diff.IsEmpty() {
+		t.Errorf("expected no difference between a log and itself, got %#v", diff)
+	}
+}
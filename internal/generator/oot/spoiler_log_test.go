@@ -0,0 +1,35 @@
+package oot_test
+
+import (
+	"encoding/json"
+	"kaepora/internal/generator/oot"
+	"testing"
+)
+
+func TestEntranceDestination(t *testing.T) {
+	var log oot.SpoilerLog
+	if err := json.Unmarshal([]byte(`{"entrances": {
+        "KF Outside Deku Tree -> Deku Tree Lobby": "Fire Temple Lower",
+        "Kakariko Village -> Death Mountain": {"region": "Lake Hylia", "from": "Zoras Domain"}
+    }}`), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		exit                oot.Exit
+		destination, region string
+	}{
+		{"KF Outside Deku Tree -> Deku Tree Lobby", "Fire Temple Lower", "Fire Temple Lower"},
+		{"Kakariko Village -> Death Mountain", "Lake Hylia (from Zoras Domain)", "Lake Hylia"},
+	}
+
+	for _, c := range cases {
+		v := log.Entrances[c.exit]
+		if actual := oot.EntranceDestination(v); actual != c.destination {
+			t.Errorf("expected destination %q, got %q", c.destination, actual)
+		}
+		if actual := oot.EntranceRegion(v); actual != c.region {
+			t.Errorf("expected region %q, got %q", c.region, actual)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+// ProcessConfig describes how to run a local randomizer as an external
+// process, it allows adding a new randomizer without writing Go code.
+type ProcessConfig struct {
+	// Command is the program to run and its arguments, each element is a
+	// text/template receiving a ProcessArgs.
+	// eg. ["python3", "/opt/rando/main.py", "--seed", "{{.Seed}}", "--out", "{{.OutputDir}}"]
+	Command []string
+
+	// SettingsDir is the directory in which the settings names given to
+	// Generate are resolved.
+	SettingsDir string
+
+	// PatchGlob and SpoilerLogGlob match the files written by the command,
+	// relative to its output directory. SpoilerLogGlob is optional.
+	PatchGlob      string
+	SpoilerLogGlob string
+
+	// Timeout is the maximum number of seconds a single run can take,
+	// defaults to 10 minutes.
+	Timeout int
+}
+
+// ProcessArgs are the values available in ProcessConfig.Command templates.
+type ProcessArgs struct {
+	Seed      string
+	Settings  string // absolute path to the settings file
+	OutputDir string // empty temporary directory where the outputs must be written
+	Version   string // version part of the generator ID, may be empty
+}
+
+func (c ProcessConfig) GetTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 10 * time.Minute
+	}
+
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// Validate returns an error if the configuration cannot be used to run a
+// generator.
+func (c ProcessConfig) Validate() error {
+	if len(c.Command) == 0 {
+		return errors.New("empty Command")
+	}
+
+	if c.PatchGlob == "" {
+		return errors.New("empty PatchGlob")
+	}
+
+	for _, v := range c.Command {
+		if _, err := template.New("").Parse(v); err != nil {
+			return fmt.Errorf("invalid Command template `%s`: %w", v, err)
+		}
+	}
+
+	return nil
+}
+
+// Process is a generic local generator that runs a configured command.
+type Process struct {
+	version string
+	config  ProcessConfig
+}
+
+func NewProcess(version string, config ProcessConfig) *Process {
+	return &Process{
+		version: version,
+		config:  config,
+	}
+}
+
+func (p *Process) Generate(settings, seed string) (Output, error) {
+	outDir, err := ioutil.TempDir("", "kaepora-process-output-")
+	if err != nil {
+		return Output{}, fmt.Errorf("unable to create output directory: %s", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	settingsPath, err := filepath.Abs(SettingsPath(p.config.SettingsDir, settings))
+	if err != nil {
+		return Output{}, err
+	}
+
+	args, err := p.command(ProcessArgs{
+		Seed:      seed,
+		Settings:  settingsPath,
+		OutputDir: outDir,
+		Version:   p.version,
+	})
+	if err != nil {
+		return Output{}, err
+	}
+
+	if err := RunCommand(args, outDir, p.config.GetTimeout()); err != nil {
+		return Output{}, fmt.Errorf("unable to generate seed: %w", err)
+	}
+
+	patch, err := ReadFirstGlob(filepath.Join(outDir, p.config.PatchGlob))
+	if err != nil {
+		return Output{}, err
+	}
+
+	var spoilerLog []byte
+	if p.config.SpoilerLogGlob != "" {
+		spoilerLog, err = ReadFirstGlob(filepath.Join(outDir, p.config.SpoilerLogGlob))
+		if err != nil {
+			return Output{}, err
+		}
+	}
+
+	return Output{
+		SeedPatch:  patch,
+		SpoilerLog: spoilerLog,
+	}, nil
+}
+
+func (p *Process) command(args ProcessArgs) ([]string, error) {
+	ret := make([]string, 0, len(p.config.Command))
+	for _, v := range p.config.Command {
+		tpl, err := template.New("").Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Command template `%s`: %w", v, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, args); err != nil {
+			return nil, fmt.Errorf("unable to render Command template `%s`: %w", v, err)
+		}
+
+		ret = append(ret, buf.String())
+	}
+
+	return ret, nil
+}
+
+func (*Process) GetDownloadURL([]byte) string {
+	return ""
+}
+
+func (*Process) IsExternal() bool {
+	return false
+}
+
+func (*Process) UnlockSpoilerLog([]byte) error {
+	return nil
+}
+
+// ErrCommandTimeout is returned by RunCommand when the command was killed
+// because it did not complete in time.
+var ErrCommandTimeout = errors.New("command timed out")
+
+// RunCommand runs a command in the given directory and kills it if it did
+// not complete before the timeout. Outputs are logged on failure.
+func RunCommand(args []string, dir string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// There's no user input, unless the configuration has been taken over.
+	// nolint: gosec
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		log.Printf("stdout: %s", stdout.String())
+		log.Printf("stderr: %s", stderr.String())
+
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%w after %s", ErrCommandTimeout, timeout)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// SettingsPath returns the path of a settings file name given to a generator,
+// names are relative to dir unless they are absolute (eg. resolved presets).
+func SettingsPath(dir, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(dir, name)
+}
+
+// ReadFirstGlob returns the contents of the one file matching the pattern.
+func ReadFirstGlob(pattern string) ([]byte, error) {
+	names, err := filepath.Glob(pattern)
+	if err != nil || len(names) != 1 {
+		return nil, fmt.Errorf("could not find file with glob `%s`: %w", pattern, err)
+	}
+
+	out, err := ioutil.ReadFile(names[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to read seed back: %w", err)
+	}
+
+	return out, nil
+}
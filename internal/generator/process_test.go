@@ -0,0 +1,66 @@
+package generator_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"kaepora/internal/generator"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProcess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kaepora-process-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "s.json"), []byte(`{"foo": 1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := generator.NewProcess("v1", generator.ProcessConfig{
+		Command: []string{
+			"sh", "-c",
+			`echo "{{.Version}} {{.Seed}}" > "{{.OutputDir}}/seed.patch" && cp "{{.Settings}}" "{{.OutputDir}}/seed.spoiler.json"`,
+		},
+		SettingsDir:    dir,
+		PatchGlob:      "*.patch",
+		SpoilerLogGlob: "*.spoiler.json",
+	})
+
+	out, err := gen.Generate("s.json", "some-seed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := strings.TrimSpace(string(out.SeedPatch)); actual != "v1 some-seed" {
+		t.Errorf("unexpected patch: %s", actual)
+	}
+	if actual := string(out.SpoilerLog); actual != `{"foo": 1}` {
+		t.Errorf("unexpected spoiler log: %s", actual)
+	}
+}
+
+func TestProcessTimeout(t *testing.T) {
+	gen := generator.NewProcess("", generator.ProcessConfig{
+		Command:   []string{"sleep", "10"},
+		PatchGlob: "*",
+		Timeout:   1,
+	})
+
+	if _, err := gen.Generate("s.json", "seed"); !errors.Is(err, generator.ErrCommandTimeout) {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestSettingsPath(t *testing.T) {
+	if actual := generator.SettingsPath("/base", "s3.json"); actual != "/base/s3.json" {
+		t.Errorf("expected relative names to be resolved in dir, got %s", actual)
+	}
+	if actual := generator.SettingsPath("/base", "/cache/s3@1.json"); actual != "/cache/s3@1.json" {
+		t.Errorf("expected absolute names to be kept, got %s", actual)
+	}
+}
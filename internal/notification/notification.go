@@ -0,0 +1,232 @@
+// Package notification renders the platform-neutral payloads of
+// back.Notification into the messages of a front-end platform (eg. Discord)
+// using per-platform templates translated using per-locale catalogs.
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"kaepora/internal/back"
+	"kaepora/internal/util"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// DefaultLocale is used for recipients without a known locale.
+const DefaultLocale = "en"
+
+// Locales are the locales notifications can be rendered in, lowercase ISO
+// 639-1 (eg. "fr"). The catalogs are shared with the website.
+var Locales = []string{"en", "fr"}
+
+// Renderer renders notifications for a single platform.
+type Renderer struct {
+	platform string
+	tpl      map[string]*template.Template // indexed by locale
+	catalogs map[string]*gotext.Locale     // indexed by locale
+}
+
+// GetResourcesDir returns the directory holding the resources/notifications
+// templates and the resources/web/locales catalogs.
+func GetResourcesDir() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(wd, "resources"), nil
+}
+
+// NewRenderer loads the templates of the given platform from
+// resources/notifications/PLATFORM/*.tmpl, one template per NotificationType
+// named after the type (eg. "MatchEnd.tmpl").
+func NewRenderer(platform string) (*Renderer, error) {
+	baseDir, err := GetResourcesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return newRendererFromDir(baseDir, platform)
+}
+
+func newRendererFromDir(baseDir, platform string) (*Renderer, error) {
+	r := &Renderer{
+		platform: platform,
+		tpl:      make(map[string]*template.Template, len(Locales)),
+		catalogs: make(map[string]*gotext.Locale, len(Locales)),
+	}
+
+	for _, locale := range Locales {
+		catalog := gotext.NewLocale(filepath.Join(baseDir, "web/locales"), locale)
+		catalog.AddDomain("default")
+
+		tpl := template.New(platform)
+		tpl.Funcs(templateFuncs(tpl, catalog, locale))
+		if _, err := tpl.ParseGlob(filepath.Join(baseDir, "notifications", platform, "*.tmpl")); err != nil {
+			return nil, fmt.Errorf("unable to load %s templates: %w", platform, err)
+		}
+
+		r.tpl[locale] = tpl
+		r.catalogs[locale] = catalog
+	}
+
+	return r, nil
+}
+
+// Catalog returns the translations of the given locale for the platform to
+// localize its own messages, falling back to DefaultLocale.
+func (r *Renderer) Catalog(locale string) *gotext.Locale {
+	if catalog, ok := r.catalogs[locale]; ok {
+		return catalog
+	}
+
+	return r.catalogs[DefaultLocale]
+}
+
+// HasLocale returns true if the given locale is one of Locales.
+func HasLocale(locale string) bool {
+	for _, v := range Locales {
+		if v == locale {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Render returns the text of the notification in the given locale, falling
+// back to DefaultLocale for unknown locales.
+func (r *Renderer) Render(locale string, notif back.Notification) (string, error) {
+	tpl, ok := r.tpl[locale]
+	if !ok {
+		tpl = r.tpl[DefaultLocale]
+	}
+
+	name := back.NotificationTypeName(notif.Type) + ".tmpl"
+	if tpl.Lookup(name) == nil {
+		return "", fmt.Errorf("no %s template for notification %s", r.platform, name)
+	}
+
+	var buf strings.Builder
+	if err := tpl.ExecuteTemplate(&buf, name, notif.Payload); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// nolint:funlen
+func templateFuncs(tpl *template.Template, catalog *gotext.Locale, locale string) template.FuncMap {
+	return template.FuncMap{
+		"t": func(str string, args ...interface{}) string {
+			return catalog.Get(str, args...)
+		},
+		"tn": func(singular, plural string, count int, args ...interface{}) string {
+			return catalog.GetN(singular, plural, count, args...)
+		},
+
+		// include executes a template into a string so it can be piped.
+		"include": func(name string, data interface{}) (string, error) {
+			var buf strings.Builder
+			err := tpl.ExecuteTemplate(&buf, name, data)
+			return buf.String(), err
+		},
+		// tabwrite aligns tab-separated columns.
+		"tabwrite": func(str string) (string, error) {
+			var buf strings.Builder
+			w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+			if _, err := w.Write([]byte(str)); err != nil {
+				return "", err
+			}
+			err := w.Flush()
+			return buf.String(), err
+		},
+
+		"datetime": util.Datetime,
+		"duration": func(d time.Duration) string {
+			return d.Round(time.Second).String()
+		},
+		"until": func(t time.Time) string {
+			return time.Until(t).Round(time.Second).String()
+		},
+		"add": func(a, b int) int {
+			return a + b
+		},
+
+		"sessionStatus": sessionStatus,
+		"settingsPatch": func(patch map[string]interface{}) ([]documentedSetting, error) {
+			return documentSettingsPatch(locale, patch)
+		},
+	}
+}
+
+// sessionStatus returns a name for a status usable in templates.
+func sessionStatus(status back.MatchSessionStatus) string {
+	switch status {
+	case back.MatchSessionStatusWaiting:
+		return "waiting"
+	case back.MatchSessionStatusJoinable:
+		return "joinable"
+	case back.MatchSessionStatusPreparing:
+		return "preparing"
+	case back.MatchSessionStatusInProgress:
+		return "in_progress"
+	case back.MatchSessionStatusClosed:
+		return "closed"
+	default:
+		return "invalid"
+	}
+}
+
+type documentedSetting struct {
+	Title, Value string
+}
+
+// documentSettingsPatch returns the human-readable version of the settings
+// used to generate a seed, sorted by title.
+func documentSettingsPatch(locale string, patch map[string]interface{}) ([]documentedSetting, error) {
+	if len(patch) == 0 {
+		return nil, nil
+	}
+
+	doc, err := back.LoadSettingsDocumentation(locale)
+	if errors.Is(err, os.ErrNotExist) {
+		doc, err = back.LoadSettingsDocumentation(DefaultLocale)
+	}
+	if err != nil {
+		// Not worth failing the whole notification.
+		log.Printf("warning: unable to send settings info: %v", err)
+		return nil, nil
+	}
+
+	ret := make([]documentedSetting, 0, len(patch))
+	for k, v := range patch {
+		setting := doc[k]
+		value := setting.GetValueEntry(v)
+
+		if setting.Title == "" {
+			log.Printf("warning: no title for setting %s", k)
+			continue
+		}
+		if value.Title == "" {
+			log.Printf("warning: no title for value %s = %v", k, v)
+			continue
+		}
+
+		ret = append(ret, documentedSetting{setting.Title, value.Title})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Title < ret[j].Title
+	})
+
+	return ret, nil
+}
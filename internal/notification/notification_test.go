@@ -0,0 +1,216 @@
+package notification // nolint:testpackage
+
+import (
+	"kaepora/internal/back"
+	"kaepora/internal/generator/oot"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createTestRenderer(t *testing.T) *Renderer {
+	r, err := newRendererFromDir("../../resources", "discord")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+// nolint:funlen
+func TestRender(t *testing.T) {
+	r := createTestRenderer(t)
+	start := time.Now().Add(time.Hour)
+
+	winner := back.NotificationEntry{
+		Player: "Alice", Status: back.MatchEntryStatusFinished,
+		Outcome: back.MatchEntryOutcomeWin, Started: true, Duration: 2 * time.Hour,
+	}
+	loser := back.NotificationEntry{
+		Player: "Bob", Status: back.MatchEntryStatusForfeit,
+		Outcome: back.MatchEntryOutcomeLoss, Started: true, Duration: time.Hour,
+	}
+
+	cases := []struct {
+		name     string
+		notif    back.Notification
+		expected []string
+	}{
+		{
+			"joinable",
+			back.Notification{
+				Type: back.NotificationTypeMatchSessionStatusUpdate,
+				Payload: &back.MatchSessionStatusUpdatePayload{
+					League: "std", Status: back.MatchSessionStatusJoinable,
+					StartDate: start, Settings: "s3.json",
+					VoteOptions: []string{"a.json", "b.json"},
+				},
+			},
+			[]string{
+				"The race for league `std` can now be joined!",
+				"You can join using `!join std`.",
+				"1. `a.json`\n2. `b.json`",
+			},
+		},
+		{
+			"preparing",
+			back.Notification{
+				Type: back.NotificationTypeMatchSessionStatusUpdate,
+				Payload: &back.MatchSessionStatusUpdatePayload{
+					League: "std", Status: back.MatchSessionStatusPreparing,
+					StartDate: start, Settings: "a.json", Contestants: 4,
+					VoteResults: []back.SettingsVoteResult{
+						{Settings: "a.json", Votes: 3},
+						{Settings: "b.json", Votes: 1},
+					},
+				},
+			},
+			[]string{
+				"Seeds will soon be sent to the 4 contestants.",
+				"The settings vote is closed: `a.json` (3 votes), `b.json` (1 vote). The race will use `a.json`.",
+			},
+		},
+		{
+			"match end",
+			back.Notification{
+				Type: back.NotificationTypeMatchEnd,
+				Payload: &back.MatchEndPayload{
+					Self: winner, Opponent: loser, OpponentStreamURL: "https://example.com",
+				},
+			},
+			[]string{
+				"Alice, your race against Bob has ended.",
+				"You completed your race in 2h0m0s.",
+				"Bob forfeited after 1h0m0s.",
+				"**You won!**",
+				"Your opponent stream: <https://example.com>",
+			},
+		},
+		{
+			"match end running opponent",
+			back.Notification{
+				Type: back.NotificationTypeMatchEnd,
+				Payload: &back.MatchEndPayload{
+					Self:     loser,
+					Opponent: back.NotificationEntry{Player: "Alice", Status: back.MatchEntryStatusInProgress, Started: true},
+				},
+			},
+			[]string{
+				"You forfeited your race after 1h0m0s.",
+				"Alice is still running.",
+				"You can only hope your opponent forfeits now.",
+			},
+		},
+		{
+			"recap",
+			back.Notification{
+				Type: back.NotificationTypeMatchSessionRecap,
+				Payload: &back.MatchSessionRecapPayload{
+					League: "std", StartDate: start,
+					Rows: []back.RecapRow{{
+						Entries:    [2]back.NotificationEntry{winner, loser},
+						Difficulty: oot.SeedDifficulty{SphereDepth: 12, WOTHCount: 5},
+					}},
+					URL: "https://example.com/en/sessions/x",
+				},
+			},
+			[]string{
+				"Player 1          vs  Player 2                    Seed\n" +
+					"*Alice*   2h0m0s      Bob       forfeit (1h0m0s)  12 spheres, 5 WotH",
+				"Get the seeds and spoiler logs on <https://example.com/en/sessions/x>",
+			},
+		},
+		{
+			"recap in progress",
+			back.Notification{
+				Type: back.NotificationTypeMatchSessionRecap,
+				Payload: &back.MatchSessionRecapPayload{
+					League: "std", StartDate: start, Unknown: 2,
+				},
+			},
+			[]string{
+				"There are still 2 races in progress.",
+				"You can get an up to date recap with `!recap std`.",
+			},
+		},
+		{
+			"leaderboard",
+			back.Notification{
+				Type: back.NotificationTypeLeagueLeaderboardUpdate,
+				Payload: &back.LeagueLeaderboardUpdatePayload{
+					League: "std", Top: []string{"Alice", "Bob"},
+				},
+			},
+			[]string{"Top players for league `std`:\n```\n  1. Alice\n  2. Bob\n```"},
+		},
+		{
+			"seed failure admin",
+			back.Notification{
+				Type: back.NotificationTypeMatchSeedFailure,
+				Payload: &back.MatchSeedFailurePayload{
+					ForAdmin: true, MatchID: "m", Player1: "Alice", Player2: "Bob", Error: "boom",
+				},
+			},
+			[]string{"Unable to generate a seed for match `m` (Alice vs. Bob): boom"},
+		},
+	}
+
+	for _, v := range cases {
+		actual, err := r.Render(DefaultLocale, v.notif)
+		if err != nil {
+			t.Fatalf("%s: %s", v.name, err)
+		}
+
+		for _, expected := range v.expected {
+			if !strings.Contains(actual, expected) {
+				t.Errorf("%s: expected %q in:\n%s", v.name, expected, actual)
+			}
+		}
+	}
+}
+
+// Every notification type must render on every platform, even from an empty
+// payload.
+func TestRenderAllTypes(t *testing.T) {
+	r := createTestRenderer(t)
+
+	for typ := back.NotificationTypeMatchSessionStatusUpdate; typ <= back.NotificationTypeMatchSeedFailure; typ++ {
+		payload, err := back.NewNotificationPayload(typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := r.Render("xx", back.Notification{Type: typ, Payload: payload})
+		if err != nil {
+			t.Fatalf("%s: %s", back.NotificationTypeName(typ), err)
+		}
+		if actual == "" {
+			t.Errorf("%s: empty notification", back.NotificationTypeName(typ))
+		}
+	}
+}
+
+func TestRenderLocalized(t *testing.T) {
+	r := createTestRenderer(t)
+	notif := back.Notification{
+		Type: back.NotificationTypeMatchSessionRecap,
+		Payload: &back.MatchSessionRecapPayload{
+			League: "std", Unknown: 2,
+		},
+	}
+
+	actual, err := r.Render("fr", notif)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Il reste encore 2 courses en cours.\n" +
+		"Vous pouvez obtenir un récapitulatif à jour avec `!recap std`."
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	if r.Catalog("xx") != r.Catalog(DefaultLocale) {
+		t.Error("expected unknown locales to fall back to the default one")
+	}
+}
@@ -73,7 +73,7 @@ func (ns NullUUIDAsBlob) Value() (driver.Value, error) {
 		return nil, nil
 	}
 
-	return ns.UUID, nil
+	return ns.UUID.Value()
 }
 
 func (ns NullUUIDAsBlob) MarshalJSON() ([]byte, error) {
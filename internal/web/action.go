@@ -20,21 +20,31 @@ func (s *Server) doAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var sessionID util.UUIDAsBlob
+	if sessionIDStr := r.PostForm.Get("MatchSessionID"); sessionIDStr != "" {
+		id, err := uuid.Parse(sessionIDStr)
+		if err != nil {
+			s.error(w, r, err, http.StatusBadRequest)
+			return
+		}
+		sessionID = util.UUIDAsBlob(id)
+	}
+
 	var err error
 	switch r.PostForm.Get("Action") {
 	case "join":
-		var sessionID util.UUIDAsBlob
-		if sessionIDStr := r.PostForm.Get("MatchSessionID"); sessionIDStr != "" {
-			id, err := uuid.Parse(sessionIDStr)
-			if err != nil {
-				s.error(w, r, err, http.StatusBadRequest)
-				return
-			}
-			sessionID = util.UUIDAsBlob(id)
-		}
 		err = s.back.JoinMatchSessionByID(sessionID, player.ID)
+	case "vote":
+		err = s.back.VoteForSettings(sessionID, player.ID, r.PostForm.Get("Option"))
 	case "cancel":
 		_, err = s.back.CancelActiveMatchSession(player.ID)
+	case "locale":
+		locale := r.PostForm.Get("Locale")
+		if _, ok := localeNames[locale]; !ok {
+			s.error(w, r, fmt.Errorf("invalid locale: %q", locale), http.StatusBadRequest)
+			return
+		}
+		err = s.back.SetPlayerLocale(player.ID, locale)
 	}
 
 	if err != nil {
@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"kaepora/internal/back"
+	"kaepora/internal/back/rotation"
 	"kaepora/internal/back/schedule"
-	"kaepora/internal/generator/factory"
 	"kaepora/internal/util"
 	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 func (s *Server) adminAllLeagues(w http.ResponseWriter, r *http.Request) {
@@ -25,6 +28,106 @@ func (s *Server) adminAllLeagues(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *Server) adminSeedgenQueue(w http.ResponseWriter, r *http.Request) {
+	s.response(w, r, http.StatusOK, "admin/seedgen.html", struct {
+		Jobs []back.SeedgenJob
+	}{
+		s.back.GetSeedgenQueue(),
+	})
+}
+
+func (s *Server) adminFailedNotifications(w http.ResponseWriter, r *http.Request) {
+	var errStr string
+
+	if r.Method == "POST" {
+		id, err := uuid.Parse(r.PostFormValue("ID"))
+		if err != nil {
+			s.error(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case r.PostFormValue("action-retry") != "":
+			err = s.back.RetryNotification(util.UUIDAsBlob(id))
+		case r.PostFormValue("action-discard") != "":
+			err = s.back.DiscardNotification(util.UUIDAsBlob(id))
+		}
+		if err != nil {
+			errStr = err.Error()
+		}
+	}
+
+	notifications, err := s.back.GetFailedNotifications()
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	s.response(w, r, http.StatusOK, "admin/notifications.html", struct {
+		Notifications []back.OutboxNotification
+		Error         string
+	}{
+		notifications,
+		errStr,
+	})
+}
+
+func (s *Server) adminWebhooks(w http.ResponseWriter, r *http.Request) {
+	var errStr string
+
+	if r.Method == "POST" {
+		if err := r.ParseForm(); err != nil {
+			s.error(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		switch {
+		case r.PostFormValue("action-create") != "":
+			_, err = s.back.CreateWebhook(r.PostFormValue("URL"), r.PostForm["Types"])
+		case r.PostFormValue("action-delete") != "":
+			var id uuid.UUID
+			id, err = uuid.Parse(r.PostFormValue("ID"))
+			if err != nil {
+				s.error(w, r, err, http.StatusBadRequest)
+				return
+			}
+			err = s.back.DeleteWebhook(util.UUIDAsBlob(id))
+		}
+		if err != nil {
+			errStr = err.Error()
+		}
+	}
+
+	webhooks, err := s.back.GetWebhooks()
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	types := back.WebhookEventTypes()
+	typeNames := make([]string, 0, len(types))
+	for _, v := range types {
+		typeNames = append(typeNames, back.NotificationTypeName(v))
+	}
+
+	s.response(w, r, http.StatusOK, "admin/webhooks.html", struct {
+		Webhooks        []back.Webhook
+		Types           []string
+		SignatureHeader string
+		EventHeader     string
+		DeliveryHeader  string
+		Error           string
+	}{
+		webhooks,
+		typeNames,
+		back.WebhookSignatureHeader,
+		back.WebhookEventHeader,
+		back.WebhookDeliveryHeader,
+		errStr,
+	})
+}
+
 func (s *Server) adminOneLeague(w http.ResponseWriter, r *http.Request) {
 	id, err := urlID(r, "id")
 	if err != nil {
@@ -93,12 +196,23 @@ func (s *Server) adminSaveOneLeague(r *http.Request, l back.League) (back.League
 		e = append(e, errors.New("field Settings must not be empty"))
 	}
 
-	l.Generator = r.PostFormValue("Generator")
-	_, err := factory.New(nil).NewGenerator(l.Generator)
+	game, err := s.back.GetGame(l.GameID)
 	if err != nil {
+		return l, err
+	}
+
+	l.Generator = r.PostFormValue("Generator")
+	if err := s.validateLeagueGenerator(game, l.Generator); err != nil {
 		e = append(e, fmt.Errorf("unable to parse Generator: %s", err))
 	}
 
+	l.FallbackGenerators = r.PostFormValue("FallbackGenerators")
+	for _, v := range l.FallbackGeneratorsList() {
+		if err := s.validateLeagueGenerator(game, v); err != nil {
+			e = append(e, fmt.Errorf("unable to parse fallback Generator %s: %s", v, err))
+		}
+	}
+
 	var conf schedule.Config
 	if err := json.Unmarshal([]byte(r.PostFormValue("Schedule")), &conf); err != nil {
 		e = append(e, fmt.Errorf("invalid Schedule JSON: %s", err))
@@ -110,9 +224,37 @@ func (s *Server) adminSaveOneLeague(r *http.Request, l back.League) (back.League
 		}
 	}
 
+	l.SettingsVoteOptions = r.PostFormValue("SettingsVoteOptions")
+
+	var rotationConf rotation.Config
+	if err := json.Unmarshal([]byte(r.PostFormValue("SettingsRotation")), &rotationConf); err != nil {
+		e = append(e, fmt.Errorf("invalid SettingsRotation JSON: %s", err))
+	} else {
+		l.SettingsRotation = rotationConf
+
+		if _, err := rotation.New(l.SettingsRotation); err != nil {
+			e = append(e, fmt.Errorf("invalid SettingsRotation configuration: %s", err))
+		}
+	}
+
 	if err := util.ConcatErrors(e); err != nil {
 		return l, err
 	}
 
 	return l, s.back.UpdateLeague(l)
 }
+
+// validateLeagueGenerator ensures the generator exists and, if it is a
+// configured generic generator, that it belongs to the League Game.
+func (s *Server) validateLeagueGenerator(game back.Game, id string) error {
+	if _, err := s.back.GetGenerator(id); err != nil {
+		return err
+	}
+
+	name := strings.SplitN(id, ":", 2)[0]
+	if owner, ok := s.config.GameOfGenerator(name); ok && owner != game.Name {
+		return fmt.Errorf("generator %s belongs to game %s, not %s", name, owner, game.Name)
+	}
+
+	return nil
+}
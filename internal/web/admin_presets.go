@@ -0,0 +1,111 @@
+package web
+
+import (
+	"kaepora/internal/back"
+	"kaepora/internal/util"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (s *Server) adminAllSettingsPresets(w http.ResponseWriter, r *http.Request) {
+	var errStr string
+	if r.Method == "POST" {
+		preset, err := s.back.CreateSettingsPresetVersion(
+			r.PostFormValue("Name"),
+			r.PostFormValue("Content"),
+			r.PostFormValue("Changelog"),
+		)
+		if err == nil {
+			s.redirectToSettingsPreset(w, r, preset)
+			return
+		}
+		errStr = err.Error()
+	}
+
+	presets, err := s.back.GetSettingsPresets()
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	s.response(w, r, http.StatusOK, "admin/all_presets.html", struct {
+		Presets []back.SettingsPreset
+		Error   string
+	}{
+		presets,
+		errStr,
+	})
+}
+
+func (s *Server) adminOneSettingsPreset(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r, "id")
+	if err != nil {
+		s.error(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	preset, versions, err := s.back.GetSettingsPreset(id)
+	if err != nil {
+		s.notFound(w, r)
+		return
+	}
+
+	var (
+		rolled bool
+		errStr string
+	)
+
+	if r.Method == "POST" {
+		switch {
+		case r.PostFormValue("action-create") != "":
+			next, err := s.back.CreateSettingsPresetVersion(
+				preset.Name,
+				r.PostFormValue("Content"),
+				r.PostFormValue("Changelog"),
+			)
+			if err == nil {
+				s.redirectToSettingsPreset(w, r, next)
+				return
+			}
+			errStr = err.Error()
+		case r.PostFormValue("action-roll") != "":
+			leagueID, err := uuid.Parse(r.PostFormValue("LeagueID"))
+			if err != nil {
+				errStr = "invalid LeagueID"
+				break
+			}
+
+			if err := s.back.RollLeagueOntoSettingsPreset(util.UUIDAsBlob(leagueID), preset.ID); err != nil {
+				errStr = err.Error()
+			} else {
+				rolled = true
+			}
+		}
+	}
+
+	leagues, err := s.back.GetLeagues()
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	s.response(w, r, http.StatusOK, "admin/one_preset.html", struct {
+		Preset   back.SettingsPreset
+		Versions []back.SettingsPreset
+		Leagues  []back.League
+		Rolled   bool
+		Error    string
+	}{
+		preset,
+		versions,
+		leagues,
+		rolled,
+		errStr,
+	})
+}
+
+func (s *Server) redirectToSettingsPreset(w http.ResponseWriter, r *http.Request, preset back.SettingsPreset) {
+	locale := r.Context().Value(ctxKeyLocale).(string)
+	http.Redirect(w, r, "/"+locale+"/admin/presets/"+preset.ID.String(), http.StatusFound)
+}
@@ -29,6 +29,11 @@ func (s *Server) writeSettingsRelationSVG(w http.ResponseWriter, r *http.Request
 		return err
 	}
 
+	return s.writeDOTAsSVG(w, r, dot)
+}
+
+// writeDOTAsSVG renders a graphviz graph using the dot command.
+func (s *Server) writeDOTAsSVG(w http.ResponseWriter, r *http.Request, dot string) error {
 	tmp, err := ioutil.TempFile("", "*.dot")
 	if err != nil {
 		return err
@@ -74,26 +79,40 @@ func getSettingsRelationsDOT(name string) (string, error) {
 	dot.WriteString("\toverlap = false;\n")
 	dot.WriteString("\tsplines = true;\n")
 
-	for key, possible := range s {
+	for key, possible := range s.Values {
 		for _, value := range possible {
-			// Only display values with relations
-			if len(value.Implies) == 0 {
-				continue
-			}
-
 			for impliedKey, impliedValue := range value.Implies {
-				dot.WriteString("\t")
-				fmt.Fprintf(
-					&dot,
-					`"%s" -> "%s" [label="%v -> %v"];`,
-					key, impliedKey,
-					value.Value, impliedValue,
-				)
-				dot.WriteString("\n")
+				writeSettingsRelation(&dot, key, impliedKey, value.Value, impliedValue, "")
+			}
+			for requiredKey, requiredValue := range value.Requires {
+				writeSettingsRelation(&dot, key, requiredKey, value.Value, requiredValue, "dashed")
+			}
+			for excludedKey, excludedValues := range value.Excludes {
+				writeSettingsRelation(&dot, key, excludedKey, value.Value, excludedValues, "dotted")
 			}
 		}
 	}
+
+	for _, group := range s.Groups {
+		fmt.Fprintf(&dot, "\tsubgraph \"cluster_%s\" {\n", group.Name)
+		fmt.Fprintf(&dot, "\t\tlabel = \"%s\";\n", group.Name)
+		for _, name := range group.Settings {
+			fmt.Fprintf(&dot, "\t\t\"%s\";\n", name)
+		}
+		dot.WriteString("\t}\n")
+	}
 	dot.WriteString("}\n")
 
 	return dot.String(), nil
 }
+
+// writeSettingsRelation writes a single edge between two settings, solid for
+// Implies, dashed for Requires, and dotted for Excludes.
+func writeSettingsRelation(dot *strings.Builder, from, to string, fromValue, toValue interface{}, style string) {
+	dot.WriteString("\t")
+	fmt.Fprintf(dot, `"%s" -> "%s" [label="%v -> %v"`, from, to, fromValue, toValue)
+	if style != "" {
+		fmt.Fprintf(dot, `, style=%s`, style)
+	}
+	dot.WriteString("];\n")
+}
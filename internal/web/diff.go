@@ -0,0 +1,79 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"kaepora/internal/back"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/util"
+	"net/http"
+)
+
+// getSpoilerLogDiff compares the spoiler logs of two matches.
+func (s *Server) getSpoilerLogDiff(w http.ResponseWriter, r *http.Request) {
+	var (
+		matches [2]back.Match
+		logs    [2]oot.SpoilerLog
+	)
+
+	for k, name := range []string{"id", "otherID"} {
+		id, err := urlID(r, name)
+		if err != nil {
+			s.error(w, r, err, http.StatusNotFound)
+			return
+		}
+
+		matches[k], logs[k], err = s.getViewableSpoilerLog(r, id)
+		if err != nil {
+			s.error(w, r, err, viewableSpoilerLogErrorCode(err))
+			return
+		}
+	}
+
+	s.response(w, r, http.StatusOK, "spoilers_diff.html", struct {
+		A, B back.Match
+		Diff oot.SpoilerLogDiff
+	}{matches[0], matches[1], oot.DiffSpoilerLogs(logs[0], logs[1])})
+}
+
+// getViewableSpoilerLog returns a match and its parsed spoiler log if the
+// current user is allowed to see it, see getSpoilerLog.
+func (s *Server) getViewableSpoilerLog(r *http.Request, id util.UUIDAsBlob) (back.Match, oot.SpoilerLog, error) {
+	match, err := s.back.GetMatchWithoutBlobs(id)
+	if err != nil {
+		return back.Match{}, oot.SpoilerLog{}, err
+	}
+
+	if !s.isAuthenticatedUserAdmin(r) && !match.HasEnded() {
+		if err := s.canAuthenticatedPlayerSeeSpoilerLog(r, match); err != nil {
+			return back.Match{}, oot.SpoilerLog{}, err
+		}
+	}
+
+	if err := s.back.LoadMatchBlobs(&match); err != nil {
+		return back.Match{}, oot.SpoilerLog{}, err
+	}
+
+	raw, err := ioutil.ReadAll(match.SpoilerLog.Uncompressed())
+	if err != nil {
+		return back.Match{}, oot.SpoilerLog{}, err
+	}
+
+	var parsed oot.SpoilerLog
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return back.Match{}, oot.SpoilerLog{}, err
+	}
+
+	return match, parsed, nil
+}
+
+// viewableSpoilerLogErrorCode returns the HTTP status matching an error of
+// getViewableSpoilerLog.
+func viewableSpoilerLogErrorCode(err error) int {
+	if errors.Is(err, errForbidden) {
+		return http.StatusForbidden
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"fmt"
+	"kaepora/internal/generator/oot"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// getEntranceGraph renders the shuffled entrances of a Match spoiler log as
+// a graph of regions.
+func (s *Server) getEntranceGraph(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r, "id")
+	if err != nil {
+		s.error(w, r, err, http.StatusNotFound)
+		return
+	}
+
+	_, parsed, err := s.getViewableSpoilerLog(r, id)
+	if err != nil {
+		s.error(w, r, err, viewableSpoilerLogErrorCode(err))
+		return
+	}
+
+	if len(parsed.Entrances) == 0 {
+		s.notFound(w, r)
+		return
+	}
+
+	if err := s.writeDOTAsSVG(w, r, getEntrancesDOT(parsed)); err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// getEntrancesDOT returns a graph with one node per scene or region and one
+// edge per shuffled entrance, labeled with the exit taken.
+func getEntrancesDOT(l oot.SpoilerLog) string {
+	exits := make([]oot.Exit, 0, len(l.Entrances))
+	for k := range l.Entrances {
+		exits = append(exits, k)
+	}
+	// Map order is random, keep the layout stable between renders.
+	sort.Slice(exits, func(i, j int) bool { return exits[i] < exits[j] })
+
+	var dot strings.Builder
+	dot.WriteString("digraph G {\n")
+	dot.WriteString("\trankdir = LR;\n")
+	dot.WriteString("\toverlap = false;\n")
+	dot.WriteString("\tsplines = true;\n")
+
+	for _, exit := range exits {
+		fmt.Fprintf(
+			&dot, "\t%s -> %s [label=%s];\n",
+			dotQuote(exit.Scene()),
+			dotQuote(oot.EntranceRegion(l.Entrances[exit])),
+			dotQuote(exit.Exit()),
+		)
+	}
+
+	dot.WriteString("}\n")
+
+	return dot.String()
+}
+
+func dotQuote(str string) string {
+	return `"` + strings.ReplaceAll(str, `"`, `\"`) + `"`
+}
@@ -36,21 +36,55 @@ func (s *Server) getOnePlayer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	settingsStats, _, err := memoizer(
+		s.statsCache,
+		"player_settings_"+player.ID.String(),
+		time.Hour,
+		func() (interface{}, error) {
+			return getPlayerSettingsStats(player.ID, matches)
+		},
+	)
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
 	s.response(w, r, http.StatusOK, "one_player.html", struct {
-		Player      back.Player
-		PlayerStats back.PlayerStats
-		Leagues     map[util.UUIDAsBlob]back.League
-		Matches     []back.Match
-		Players     map[util.UUIDAsBlob]back.Player
+		Player        back.Player
+		PlayerStats   back.PlayerStats
+		Leagues       map[util.UUIDAsBlob]back.League
+		Matches       []back.Match
+		Players       map[util.UUIDAsBlob]back.Player
+		SettingsStats map[util.UUIDAsBlob][]back.PlayerSettingValueStats
 	}{
-		Player:      player,
-		PlayerStats: stats,
-		Leagues:     leagues,
-		Matches:     matches,
-		Players:     players,
+		Player:        player,
+		PlayerStats:   stats,
+		Leagues:       leagues,
+		Matches:       matches,
+		Players:       players,
+		SettingsStats: settingsStats.(map[util.UUIDAsBlob][]back.PlayerSettingValueStats),
 	})
 }
 
+// getPlayerSettingsStats returns the player performance per settings value,
+// indexed by league.
+func getPlayerSettingsStats(
+	playerID util.UUIDAsBlob,
+	matches []back.Match,
+) (map[util.UUIDAsBlob][]back.PlayerSettingValueStats, error) {
+	stats, err := back.ComputePlayerSettingsValueStats(playerID, matches)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[util.UUIDAsBlob][]back.PlayerSettingValueStats{}
+	for _, v := range stats {
+		ret[v.LeagueID] = append(ret[v.LeagueID], v)
+	}
+
+	return ret, nil
+}
+
 func (s *Server) getOnePlayerGraph(w http.ResponseWriter, r *http.Request) {
 	playerName := chi.URLParam(r, "playerName")
 	shortcode := chi.URLParam(r, "shortcode")
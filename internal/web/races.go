@@ -87,16 +87,49 @@ func (s *Server) getOneMatchSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var (
+		votes   []back.SettingsVoteResult
+		voted   string
+		canVote bool
+	)
+	voteOpen := session.IsJoinable()
+	if league.HasSettingsVote() {
+		votes, err = s.back.GetSettingsVoteResults(session, league)
+		if err != nil {
+			s.error(w, r, err, http.StatusInternalServerError)
+			return
+		}
+
+		if player := playerFromRequest(r); player != nil && session.HasPlayerID(player.ID.UUID()) {
+			canVote = voteOpen
+			voted, err = s.back.GetPlayerSettingsVote(session.ID, player.ID)
+			if err != nil {
+				s.error(w, r, err, http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
 	s.response(w, r, http.StatusOK, "one_session.html", struct {
 		MatchSession back.MatchSession
 		League       back.League
 		Matches      []back.Match
 		Players      map[util.UUIDAsBlob]back.Player
+		Settings     string
+		Votes        []back.SettingsVoteResult
+		Voted        string
+		VoteOpen     bool
+		CanVote      bool
 	}{
 		MatchSession: session,
 		League:       league,
 		Matches:      matches,
 		Players:      players,
+		Settings:     session.SettingsOrDefault(league),
+		Votes:        votes,
+		Voted:        voted,
+		VoteOpen:     voteOpen,
+		CanVote:      canVote,
 	})
 }
 
@@ -150,14 +183,19 @@ func (s *Server) getSpoilerLog(w http.ResponseWriter, r *http.Request) {
 	}
 
 	stale := time.Since(match.CreatedAt.Time()) > (30 * 24 * time.Hour)
+	difficulty := match.Difficulty
+	if difficulty.IsZero() { // generated before difficulties were stored
+		difficulty = parsed.Difficulty()
+	}
 
 	s.response(w, r, http.StatusOK, "spoilers.html", struct {
-		Match    back.Match
-		Settings map[string]back.SettingsDocumentationValueEntry
-		JSON     string
-		Log      oot.SpoilerLog
-		Stale    bool // true if seed patch is no longer accessible on ootrandomizer.com
-	}{match, settings, string(raw), parsed, stale})
+		Match      back.Match
+		Settings   map[string]back.SettingsDocumentationValueEntry
+		JSON       string
+		Log        oot.SpoilerLog
+		Difficulty oot.SeedDifficulty
+		Stale      bool // true if seed patch is no longer accessible on ootrandomizer.com
+	}{match, settings, string(raw), parsed, difficulty, stale})
 }
 
 func (s *Server) canAuthenticatedPlayerSeeSpoilerLog(r *http.Request, match back.Match) error {
@@ -178,6 +216,80 @@ func (s *Server) canAuthenticatedPlayerSeeSpoilerLog(r *http.Request, match back
 	return nil
 }
 
+// getSeedPatch sends the patch of a Match to its players, or to anyone once
+// the Match has ended.
+func (s *Server) getSeedPatch(w http.ResponseWriter, r *http.Request) {
+	id, err := urlID(r, "id")
+	if err != nil {
+		s.error(w, r, err, http.StatusNotFound)
+		return
+	}
+
+	// Blobs are only loaded once we know the player can download the patch.
+	match, err := s.back.GetMatchWithoutBlobs(id)
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	if !s.isAuthenticatedUserAdmin(r) {
+		if err := s.canAuthenticatedPlayerDownloadPatch(r, match); err != nil {
+			s.error(w, r, err, http.StatusForbidden)
+			return
+		}
+	}
+
+	if !match.HasSeedPatch() {
+		s.notFound(w, r)
+		return
+	}
+
+	if err := s.back.LoadMatchBlobs(&match); err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	league, err := s.back.GetLeague(match.LeagueID)
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zlib")
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf(
+			`attachment; filename="%s_%s_%s.zpf"`,
+			league.ShortCode,
+			match.CreatedAt.Time().Format("2006-01-02_15h04"),
+			match.Seed,
+		),
+	)
+
+	if _, err := w.Write(match.SeedPatch); err != nil {
+		log.Printf("warning: %s", err)
+	}
+}
+
+// canAuthenticatedPlayerDownloadPatch allows the players of a Match to
+// download their patch at any time, and everyone once the Match has ended.
+func (s *Server) canAuthenticatedPlayerDownloadPatch(r *http.Request, match back.Match) error {
+	if match.HasEnded() {
+		return nil
+	}
+
+	player := playerFromRequest(r)
+	if player == nil {
+		return errForbidden
+	}
+
+	if _, _, err := match.GetPlayerAndOpponentEntries(player.ID); err != nil {
+		return errForbidden
+	}
+
+	return nil
+}
+
 func (s *Server) sendRawSpoilerLog(
 	w http.ResponseWriter, r *http.Request,
 	league back.League, match back.Match, raw []byte,
@@ -302,6 +414,7 @@ func (s *Server) getSchedulesBetween(start, end time.Time) ([]scheduleEntry, err
 	var ret []scheduleEntry
 
 	for k := range leagues {
+		var dates []time.Time
 		lastFoundStart := start
 		scheduler := leagues[k].Scheduler()
 		for next := start; !next.IsZero() && next.Before(end); next = scheduler.NextBetween(next, end) {
@@ -310,9 +423,19 @@ func (s *Server) getSchedulesBetween(start, end time.Time) ([]scheduleEntry, err
 			}
 
 			lastFoundStart = next
+			dates = append(dates, next)
+		}
+
+		settings, err := s.back.GetScheduledSettings(leagues[k], dates)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range dates {
 			ret = append(ret, scheduleEntry{
 				LeagueName: leagues[k].Name,
-				StartDate:  next,
+				StartDate:  dates[i],
+				Settings:   settings[i],
 			})
 		}
 	}
@@ -327,4 +450,5 @@ func (s *Server) getSchedulesBetween(start, end time.Time) ([]scheduleEntry, err
 type scheduleEntry struct {
 	LeagueName string
 	StartDate  time.Time
+	Settings   string
 }
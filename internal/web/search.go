@@ -0,0 +1,80 @@
+package web
+
+import (
+	"kaepora/internal/back"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+// searchSeeds looks for item placements in the past seeds of a league.
+func (s *Server) searchSeeds(w http.ResponseWriter, r *http.Request) {
+	shortcode := chi.URLParam(r, "shortcode")
+	league, err := s.back.GetLeagueByShortcode(shortcode)
+	if err != nil {
+		s.error(w, r, err, http.StatusNotFound)
+		return
+	}
+
+	params := r.URL.Query()
+	query := back.SeedSearchQuery{
+		Item:      params.Get("item"),
+		Location:  params.Get("location"),
+		Generator: params.Get("generator"),
+	}
+	if query.From, err = parseSearchDate(params.Get("from")); err != nil {
+		s.error(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if query.To, err = parseSearchDate(params.Get("to")); err != nil {
+		s.error(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if !query.To.IsZero() { // inclusive in the form
+		query.To = query.To.AddDate(0, 0, 1)
+	}
+
+	generators, err := s.back.GetLeagueGenerators(shortcode)
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	var result *back.SeedSearchResult
+	if query.Item != "" || query.Location != "" {
+		res, err := s.back.SearchSeeds(shortcode, query)
+		if err != nil {
+			s.error(w, r, err, http.StatusInternalServerError)
+			return
+		}
+		result = &res
+	}
+
+	s.response(w, r, http.StatusOK, "search.html", struct {
+		League     back.League
+		Generators []string
+		Form       map[string]string
+		Result     *back.SeedSearchResult
+	}{
+		League:     league,
+		Generators: generators,
+		Form: map[string]string{
+			"item":      query.Item,
+			"location":  query.Location,
+			"generator": query.Generator,
+			"from":      params.Get("from"),
+			"to":        params.Get("to"),
+		},
+		Result: result,
+	})
+}
+
+// parseSearchDate parses an optional YYYY-MM-DD UTC date.
+func parseSearchDate(str string) (time.Time, error) {
+	if str == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse("2006-01-02", str)
+}
@@ -31,6 +31,7 @@ import (
 var (
 	errForbidden     = errors.New("forbidden")
 	availableLocales = []string{"en", "fr"}
+	localeNames      = map[string]string{"en": "English", "fr": "Français"} // in their own language
 )
 
 // Server contains the state required to serve the OOTRLadder website over HTTP.
@@ -137,6 +138,11 @@ func (s *Server) setupRouter(baseDir string) *chi.Mux {
 		r.With(s.ensureAdmin).Route("/admin", func(r chi.Router) {
 			r.Get("/leagues", s.adminAllLeagues)
 			r.HandleFunc("/leagues/{id}", s.adminOneLeague)
+			r.Get("/seedgen", s.adminSeedgenQueue)
+			r.HandleFunc("/notifications", s.adminFailedNotifications)
+			r.HandleFunc("/webhooks", s.adminWebhooks)
+			r.HandleFunc("/presets", s.adminAllSettingsPresets)
+			r.HandleFunc("/presets/{id}", s.adminOneSettingsPreset)
 		})
 
 		r.Get("/rules", s.markdownContent(baseDir, "rules.md"))
@@ -148,12 +154,16 @@ func (s *Server) setupRouter(baseDir string) *chi.Mux {
 		r.Get("/sessions", s.getAllMatchSession)
 		r.Get("/sessions/{id}", s.getOneMatchSession)
 		r.Get("/matches/{id}/spoilers", s.getSpoilerLog)
+		r.Get("/matches/{id}/entrances.svg", s.getEntranceGraph)
+		r.Get("/matches/{id}/diff/{otherID}", s.getSpoilerLogDiff)
+		r.Get("/matches/{id}/patch", s.getSeedPatch)
 		r.Get("/player/{name}", s.getOnePlayer)
 		r.Get("/player/{playerName}/graph/{shortcode}/{graphName}.svg", s.getOnePlayerGraph)
 
 		r.Get("/schedule", s.schedule)
 		r.Get("/stats/{shortcode}", s.leagueStats)
 		r.Get("/stats/{shortcode}/graph/{graphName}.svg", s.leagueStatsGraph)
+		r.Get("/search/{shortcode}", s.searchSeeds)
 
 		r.Get("/", s.index)
 		r.Post("/do", s.doAction)
@@ -411,7 +421,7 @@ func (s *Server) redirectToLocale(w http.ResponseWriter, r *http.Request) {
 
 // urlID parses an URL parameter as an UUID.
 func urlID(r *http.Request, name string) (util.UUIDAsBlob, error) {
-	str := chi.URLParam(r, "id")
+	str := chi.URLParam(r, name)
 	if str == "" {
 		return util.UUIDAsBlob{}, fmt.Errorf("empty ID in URL param %s", name)
 	}
@@ -432,10 +442,18 @@ func (s *Server) shuffledSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	leagues, err := s.back.GetShuffledSettingsLeagues()
+	if err != nil {
+		s.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
 	s.response(w, r, http.StatusOK, "shuffled-settings.html", struct {
-		Doc back.SettingsDocumentation
+		Doc     back.SettingsDocumentation
+		Leagues []back.ShuffledSettingsLeague
 	}{
-		Doc: doc,
+		Doc:     doc,
+		Leagues: leagues,
 	})
 }
 
@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"kaepora/internal/back"
 	"log"
-	"math"
 	"net/http"
 	"time"
 
@@ -15,6 +14,7 @@ type leagueStats struct {
 	Misc          back.StatsMisc
 	Attendance    []attendanceEntry
 	Seed          statsSeed
+	SettingsTimes []back.SettingValueStats
 	ShortCode     string
 	ExtendedStats bool
 }
@@ -88,61 +88,37 @@ func (s *Server) computeLeagueStats(shortcode string) (*leagueStats, error) {
 		return nil, err
 	}
 
-	payload.Attendance, err = s.getAttendanceStats(payload.ShortCode)
+	payload.SettingsTimes, err = s.back.GetSettingsValueStats(payload.ShortCode)
 	if err != nil {
 		return nil, err
 	}
 
+	attendance, err := s.back.GetAttendanceStats(payload.ShortCode)
+	if err != nil {
+		return nil, err
+	}
+	payload.Attendance = getAttendanceEntries(attendance, payload.Misc.MostPlayersInARace)
+
 	return payload, nil
 }
 
 type attendanceEntry struct {
-	From, To    string    // HH:MM, always UTC
-	Color       [7]string // color to use in heatmap
-	Average     [7]int    // average player count per dow
-	Accumulated [7]int    // total player count per dow
-	Counted     [7]int    // sessions counted in this slot
+	From, To string    // HH:MM, always UTC
+	Color    [7]string // color to use in heatmap
+	Average  [7]int    // average player count per dow
 }
 
-func (s *Server) getAttendanceStats(shortcode string) ([]attendanceEntry, error) {
-	start := time.Now()
-	defer func() { log.Printf("info: computed attendance in %s", time.Since(start)) }()
-
-	bins := 3
-	ret := make([]attendanceEntry, bins)
+// getAttendanceEntries lays out the stored attendance of a league as a
+// heatmap, max is the highest player count in a session.
+func getAttendanceEntries(attendance back.Attendance, max int) []attendanceEntry {
+	ret := make([]attendanceEntry, back.AttendanceSlots)
 	for i := 0; i < len(ret); i++ {
-		t := time.Unix(int64(24/bins*i*3600), 0).UTC()
+		t := time.Unix(int64(24/back.AttendanceSlots*i*3600), 0).UTC()
 		ret[i].From = t.Format("15")
-		ret[i].To = t.Add(time.Duration(24/bins) * time.Hour).Format("15")
-	}
-
-	max := math.MinInt64
-	if err := s.back.MapMatchSessions(shortcode, func(m back.MatchSession) error {
-		players := len(m.PlayerIDs)
-		if players > max {
-			max = players
-		}
+		ret[i].To = t.Add(time.Duration(24/back.AttendanceSlots) * time.Hour).Format("15")
 
-		t := m.StartDate.Time().UTC()
-		bin := t.Hour() / (24 / bins)
-		dow := t.Weekday() - 1
-		if dow < 0 {
-			dow = 6
-		}
-
-		ret[bin].Accumulated[dow] += players
-		ret[bin].Counted[dow]++
-
-		return nil
-	}); err != nil {
-		return nil, err
-	}
-
-	for i := 0; i < len(ret); i++ {
 		for dow := 0; dow < 7; dow++ {
-			if ret[i].Counted[dow] > 0 {
-				ret[i].Average[dow] = ret[i].Accumulated[dow] / ret[i].Counted[dow]
-			}
+			ret[i].Average[dow] = attendance.Average(i, dow)
 
 			if max > 0 {
 				ret[i].Color[dow] = lerpColor(float64(ret[i].Average[dow]) / float64(max))
@@ -152,7 +128,7 @@ func (s *Server) getAttendanceStats(shortcode string) ([]attendanceEntry, error)
 		}
 	}
 
-	return ret, nil
+	return ret
 }
 
 func lerpColor(r float64) string {
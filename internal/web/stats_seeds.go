@@ -1,14 +1,9 @@
 package web
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
+	"kaepora/internal/back/seedstats"
 	"kaepora/internal/generator/oot"
-	"log"
 	"sort"
-	"strings"
-	"time"
 )
 
 type locationPct struct {
@@ -58,145 +53,55 @@ func (a byName) Swap(i, j int) {
 }
 
 func (s *Server) getSeedStats(shortcode string) (statsSeed, error) {
-	start := time.Now()
-	seedTotal := 0
-
-	wothLocations := map[string]int{}
-	wothItems := map[string]int{}
-	barrenRegions := map[string]int{}
-	settings := map[string]map[string]int{} // name => value => count
-	locationsAcc := map[string]map[oot.SpoilerLogItemCategory]int{}
-	sphereSum := map[string]int{}   // total sphere sum by item
-	sphereCount := map[string]int{} // item occurrence count
-
-	if err := s.back.MapSpoilerLogs(shortcode, func(raw io.Reader) error {
-		seedTotal++
-
-		var l oot.SpoilerLog
-		dec := json.NewDecoder(raw)
-		if err := dec.Decode(&l); err != nil {
-			return err
-		}
-
-		for _, name := range l.BarrenRegions {
-			barrenRegions[name]++
-		}
-
-		computeWOTHStats(l, wothLocations, wothItems)
-		computeLocationStats(l, locationsAcc)
-		computeSettingsStats(l, settings)
-		computeSphereStats(l, sphereSum, sphereCount)
-
-		return nil
-	}); err != nil {
+	stats, err := s.back.GetSeedStats(shortcode)
+	if err != nil {
 		return statsSeed{}, err
 	}
 
-	defer func() { log.Printf("info: computed stats for %d seeds in %s", seedTotal, time.Since(start)) }()
 	return statsSeed{
-		Barren:    namedPctFromMap(barrenRegions, seedTotal),
-		WOTH:      namedPctFromMap(wothLocations, seedTotal),
-		WOTHItems: namedPctFromMap(wothItems, seedTotal),
-		Locations: locationPctFromMap(locationsAcc, seedTotal),
-		Settings:  NamedPct2DFrom2DMap(settings, seedTotal),
-		Spheres:   namedAvgFromSumAndCount(sphereSum, sphereCount),
+		Barren:    namedPctFromMap(stats.BarrenRegions, stats.Seeds),
+		WOTH:      namedPctFromMap(stats.WOTHLocations, stats.Seeds),
+		WOTHItems: namedPctFromMap(stats.WOTHItems, stats.Seeds),
+		Locations: locationPctFromMap(stats.Locations, stats.Seeds),
+		Settings:  NamedPct2DFrom2DMap(stats.Settings, stats.Seeds),
+		Spheres:   namedAvgFromSumAndCount(stats.SphereSum, stats.SphereCount),
+		Difficulty: statsDifficulty{
+			SphereDepth:      avg(stats.SphereDepthSum, stats.Seeds),
+			WOTHCount:        avg(stats.WOTHCountSum, stats.Seeds),
+			HardProgression:  avg(stats.HardProgressionSum, stats.Seeds),
+			RequiredDungeons: namedPctFromMap(stats.RequiredDungeons, stats.Seeds),
+		},
+		Hints:     hintStats(stats),
+		Entrances: NamedPct2DFrom2DMap(stats.Entrances, stats.Seeds),
 	}, nil
 }
 
-func computeWOTHStats(l oot.SpoilerLog, wothLocations, wothItems map[string]int) {
-	progressive := map[string]int{}
-	for location, item := range l.WOTHLocations {
-		wothLocations[string(location)]++
-
-		if strings.HasPrefix(string(item), "Progressive") {
-			wothItems[progressiveItemName(progressive, string(item))]++
-		} else {
-			wothItems[string(item)]++
-		}
-	}
-}
-
-func computeLocationStats(l oot.SpoilerLog, locationsAcc map[string]map[oot.SpoilerLogItemCategory]int) {
-	for name, item := range l.Locations {
-		if _, ok := locationsAcc[string(name)]; !ok {
-			locationsAcc[string(name)] = make(
-				map[oot.SpoilerLogItemCategory]int,
-				oot.SpoilerLogItemCategoryCount,
-			)
-		}
-
-		locationsAcc[string(name)][item.GetCategory()]++
+func hintStats(stats seedstats.Stats) statsHints {
+	ret := statsHints{
+		Places: namedPctFromMap(stats.HintedPlaces, stats.Seeds),
 	}
-}
 
-func computeSettingsStats(l oot.SpoilerLog, settings map[string]map[string]int) {
-	for name, value := range l.Settings {
-		// HACK, ugly array we don't care about.
-		if name == "allowed_tricks" {
+	for typ := oot.GossipHintType(0); typ < oot.GossipHintTypeCount; typ++ {
+		if stats.HintTypes[typ] == 0 {
 			continue
 		}
 
-		if _, ok := settings[name]; !ok {
-			settings[name] = map[string]int{}
-		}
-
-		settings[name][fmt.Sprintf("%v", value)]++
+		ret.Types = append(ret.Types, statsHintType{
+			Name:    typ.String(),
+			Pct:     100.0 * avg(stats.HintTypes[typ], stats.Hints),
+			WOTHPct: 100.0 * avg(stats.HintTypeWOTH[typ], stats.HintTypes[typ]),
+		})
 	}
-}
-
-func computeSphereStats(l oot.SpoilerLog, sphereSum, sphereCount map[string]int) {
-	progressive := map[string]int{}
 
-	for k, sphere := range l.Spheres() {
-		for _, item := range sphere {
-			itemName := progressiveItemName(progressive, string(item))
-			switch item.GetCategory() { // nolint:exhaustive
-			case oot.SpoilerLogItemCategorySmallKey, oot.SpoilerLogItemCategoryBossKey:
-				continue
-			}
-
-			sphereSum[itemName] += k
-			sphereCount[itemName]++
-		}
-	}
+	return ret
 }
 
-func progressiveItemName(cache map[string]int, item string) string {
-	cache[item]++
-	switch item {
-	case "Progressive Strength Upgrade":
-		switch cache[item] {
-		case 1:
-			return "Goron's Bracelet"
-		case 2:
-			return "Silver Gauntlets"
-		case 3:
-			return "Golden Gauntlets"
-		}
-	case "Progressive Hookshot":
-		switch cache[item] {
-		case 1:
-			return "Hookshot"
-		case 2:
-			return "Longshot"
-		}
-	case "Progressive Scale":
-		switch cache[item] {
-		case 1:
-			return "Silver Scale"
-		case 2:
-			return "Golden Scale"
-		}
-	case "Progressive Wallet":
-		switch cache[item] {
-		case 1:
-			return "Adult's Wallet"
-		case 2:
-			return "Giant's Wallet"
-		}
+func avg(sum, count int) float64 {
+	if count == 0 {
+		return 0
 	}
 
-	return item
+	return float64(sum) / float64(count)
 }
 
 func namedPctFromMap(m map[string]int, totalInt int) (ret []namedPct) {
@@ -250,6 +155,26 @@ type statsSeed struct {
 	Spheres                 []namedPct
 	Locations               []locationPct
 	Settings                []NamedPct2D
+	Difficulty              statsDifficulty
+	Hints                   statsHints
+	Entrances               []NamedPct2D // exit => destination, empty without entrance shuffle
+}
+
+type statsHints struct {
+	Types  []statsHintType
+	Places []namedPct // % of seeds with at least one hint for the place
+}
+
+type statsHintType struct {
+	Name    string
+	Pct     float64 // % of all hints
+	WOTHPct float64 // % of the hints of this type pointing at the WotH
+}
+
+// statsDifficulty holds the average oot.SeedDifficulty of a league seeds.
+type statsDifficulty struct {
+	SphereDepth, WOTHCount, HardProgression float64
+	RequiredDungeons                        []namedPct // % of seeds requiring each dungeon
 }
 
 type namedPct struct {
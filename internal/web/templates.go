@@ -95,11 +95,7 @@ func (s *Server) getTemplateFuncMap(ctx context.Context, baseDir string) templat
 
 		"uri": func(parts ...string) string {
 			locale := ctx.Value(ctxKeyLocale).(string)
-			if len(parts) == 0 {
-				return "/" + locale
-			}
-
-			return "/" + locale + "/" + filepath.Join(parts...)
+			return tplURI(locale, parts...)
 		},
 
 		"tmd": func(str string, args ...interface{}) template.HTML {
@@ -109,6 +105,10 @@ func (s *Server) getTemplateFuncMap(ctx context.Context, baseDir string) templat
 			))
 		},
 
+		"localeName": func(locale string) string {
+			return localeNames[locale]
+		},
+
 		"ignoreZero": func(i int) string {
 			if i == 0 {
 				return ""
@@ -126,14 +126,19 @@ func (s *Server) getTemplateFuncMap(ctx context.Context, baseDir string) templat
 			return s.tplMatchSessionStatusTag(locale, status)
 		},
 
-		"matchSeedURL": s.tplMatchSeedURL,
+		"matchSeedURL": func(m back.Match) string {
+			locale := ctx.Value(ctxKeyLocale).(string)
+			return s.tplMatchSeedURL(locale, m)
+		},
 
 		"isAdmin":        s.isPlayerAdmin,
 		"alternate":      s.tplAlternate,
 		"assetIntegrity": tplAssetIntegrity(baseDir),
 		"gossipText":     tplGossipText,
+		"entrance":       oot.EntranceDestination,
 		"assetURL":       tplAssetURL(baseDir),
 		"datetime":       tplLocalDateTime,
+		"duration":       util.FormatDuration,
 		"date":           util.Date,
 		"future":         tplFuture,
 		"percentage":     tplPercentage,
@@ -151,6 +156,15 @@ func (s *Server) getTemplateFuncMap(ctx context.Context, baseDir string) templat
 	}
 }
 
+// tplURI returns the absolute path of a page in the given locale.
+func tplURI(locale string, parts ...string) string {
+	if len(parts) == 0 {
+		return "/" + locale
+	}
+
+	return "/" + locale + "/" + filepath.Join(parts...)
+}
+
 func tplLocalDateTime(iface interface{}) template.HTML {
 	// nolint:gosec
 	return template.HTML(fmt.Sprintf(
@@ -225,23 +239,26 @@ func tplRanking(v interface{}) template.HTML {
 	))
 }
 
-func (s *Server) tplMatchSeedURL(m back.Match) string {
-	if len(m.GeneratorState) == 0 {
-		return "#"
-	}
-
+// tplMatchSeedURL returns the external download URL of the seed if there is
+// one, or the URL of the patch stored with the Match.
+func (s *Server) tplMatchSeedURL(locale string, m back.Match) string {
 	gen, err := s.back.GetGenerator(m.Generator)
 	if err != nil {
 		log.Printf("warning: %s", err)
 		return "#"
 	}
 
-	url := gen.GetDownloadURL(m.GeneratorState)
-	if url == "" {
-		return "#"
+	if len(m.GeneratorState) > 0 {
+		if url := gen.GetDownloadURL(m.GeneratorState); url != "" {
+			return url
+		}
+	}
+
+	if m.HasSeedPatch() {
+		return tplURI(locale, "matches", m.ID.String(), "patch")
 	}
 
-	return url
+	return "#"
 }
 
 func (s *Server) tplMatchEntryStatus(locale string, e back.MatchEntry) string {
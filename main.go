@@ -35,7 +35,7 @@ func main() {
 		fmt.Fprint(os.Stdout, help())
 		return
 	case "settings":
-		if err := generateSettingsStats(flag.Arg(1)); err != nil {
+		if err := runSettingsCommand(flag.Args()[1:]); err != nil {
 			log.Fatal(err)
 		}
 		return
@@ -65,6 +65,30 @@ func main() {
 		if err := b.Rerank(flag.Arg(1)); err != nil {
 			log.Fatal(err)
 		}
+	case "blobs":
+		if err := blobs(b, flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+	case "difficulty":
+		count, err := b.ComputeMatchDifficulties()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("info: computed the difficulty of %d seeds", count)
+	case "diff":
+		if err := diffSpoilerLogs(os.Stdout, b, flag.Arg(1), flag.Arg(2)); err != nil {
+			log.Fatal(err)
+		}
+	case "stats":
+		if err := stats(b, flag.Arg(1), flag.Arg(2)); err != nil {
+			log.Fatal(err)
+		}
+	case "search-index":
+		count, err := b.IndexSeedLocations()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("info: indexed %d seeds", count)
 	default:
 		fmt.Fprint(os.Stderr, help())
 		os.Exit(1)
@@ -84,13 +108,66 @@ COMMANDS
     serve       start the Discord bot
     version     display the current version
 
+    blobs migrate      move spoiler logs and patches from the DB to BlobStoreDir
+    blobs verify       check the integrity of the stored spoiler logs and patches
+    blobs gc           delete the stored spoiler logs and patches no longer in use
+    difficulty         compute the difficulty of seeds generated without one
+    diff A B           compare two spoiler logs, A and B are match IDs or files
     rerank SHORTCODE   recompute all rankings in a league
+    search-index       index the item placements of seeds for searching
     settings FILENAME  output settings randomizer stats
+    stats rebuild SHORTCODE
+                       recompute the stored stats of a league from scratch
+    settings validate FILENAME
+                       check a shuffled settings file for errors and unreachable values
+    settings explain FILENAME SEED
+                       show how the settings are shuffled for a seed, FILENAME
+                       can be "base.json:shuffled.json" to show the merged settings
 `,
 		os.Args[0],
 	)
 }
 
+func blobs(b *back.Back, cmd string) error {
+	switch cmd {
+	case "migrate":
+		count, err := b.MigrateBlobsToStore()
+		if err != nil {
+			return err
+		}
+		log.Printf("info: moved the blobs of %d matches and pooled seeds to the blob store", count)
+	case "verify":
+		count, err := b.VerifyBlobs()
+		if err != nil {
+			return err
+		}
+		log.Printf("info: %d blobs verified", count)
+	case "gc":
+		count, err := b.CollectBlobs()
+		if err != nil {
+			return err
+		}
+		log.Printf("info: deleted %d unreferenced blobs", count)
+	default:
+		return fmt.Errorf("unknown blobs command: %q", cmd)
+	}
+
+	return nil
+}
+
+func stats(b *back.Back, cmd, shortcode string) error {
+	switch cmd {
+	case "rebuild":
+		if shortcode == "" {
+			return errors.New("you must specify a league shortcode")
+		}
+
+		return b.RebuildLeagueStats(shortcode)
+	default:
+		return fmt.Errorf("unknown stats command: %q", cmd)
+	}
+}
+
 func serve(b *back.Back, conf *config.Config) error {
 	done := make(chan struct{})
 	signaled := make(chan os.Signal, 1)
@@ -140,7 +217,7 @@ func generateSettingsStats(path string) error {
 	max := 102400
 	count := map[string]map[string]int{} // name => value => count
 	for i := 0; i < max; i++ {
-		settings := s.Shuffle(uuid.New().String(), oot.SettingsCostBudget)
+		settings := s.Shuffle(uuid.New().String())
 
 		for name, value := range settings {
 			if _, ok := count[name]; !ok {
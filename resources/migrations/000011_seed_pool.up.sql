@@ -0,0 +1,20 @@
+CREATE TABLE "PooledSeed" (
+    "ID"        blob(16) NOT NULL,
+    "LeagueID"  blob(16) NOT NULL,
+    "MatchID"   blob(16) NULL, -- set once the seed has been handed to a Match
+    "CreatedAt" INT      NOT NULL,
+
+    "Generator" TEXT NOT NULL, -- League.Generator at generation time
+    "Settings"  TEXT NOT NULL, -- League.Settings at generation time
+    "Seed"      TEXT NOT NULL,
+
+    "SpoilerLog"     blob NOT NULL,
+    "GeneratorState" blob NOT NULL,
+    "SeedPatch"      blob NOT NULL,
+
+    PRIMARY KEY ("ID"),
+    FOREIGN KEY(LeagueID) REFERENCES League(ID) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_PooledSeed_LeagueID ON PooledSeed (LeagueID);
+CREATE UNIQUE INDEX idx_unique_PooledSeed_MatchID ON PooledSeed (MatchID);
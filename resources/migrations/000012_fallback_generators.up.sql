@@ -0,0 +1,2 @@
+-- Comma-separated list of generators to use when League.Generator fails.
+ALTER TABLE "League" ADD "FallbackGenerators" TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Keys of the Match blobs when stored outside of the DB, see internal/blob.
+ALTER TABLE "Match" ADD "SpoilerLogKey" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "Match" ADD "SeedPatchKey" TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+-- Immutable versions of generator settings, referenced as "Name@Version".
+CREATE TABLE "SettingsPreset" (
+    "ID"        blob(16) NOT NULL,
+    "CreatedAt" INT      NOT NULL,
+    "Name"      TEXT     NOT NULL,
+    "Version"   INT      NOT NULL,
+    "Content"   TEXT     NOT NULL,
+    "Changelog" TEXT     NOT NULL DEFAULT '',
+
+    PRIMARY KEY ("ID")
+);
+CREATE UNIQUE INDEX idx_unique_SettingsPreset_Name_Version ON SettingsPreset (Name, Version);
@@ -0,0 +1,43 @@
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE "backup_League" (
+    "ID"        blob(16) NOT NULL,
+    "CreatedAt" INT      NOT NULL,
+    "Name"      TEXT     NOT NULL,
+    "ShortCode" TEXT     NOT NULL,
+    "GameID"    blob(16) NOT NULL,
+    "Settings"  TEXT     NOT NULL,
+    "Schedule"  TEXT     NOT NULL,
+    "AnnounceDiscordChannelID" TEXT NULL,
+    "Generator" TEXT     NOT NULL DEFAULT '',
+    "FallbackGenerators" TEXT NOT NULL DEFAULT '',
+
+    PRIMARY KEY ("ID"),
+    FOREIGN KEY(GameID) REFERENCES Game(ID) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+INSERT INTO "backup_League" ("ID", "CreatedAt", "Name", "ShortCode", "GameID", "Settings", "Schedule", "AnnounceDiscordChannelID", "Generator", "FallbackGenerators")
+    SELECT "ID", "CreatedAt", "Name", "ShortCode", "GameID", "Settings", "Schedule", "AnnounceDiscordChannelID", "Generator", "FallbackGenerators" FROM "League";
+
+DROP TABLE "League";
+ALTER TABLE "backup_League" RENAME TO "League";
+CREATE UNIQUE INDEX idx_unique_ShortCode ON League (ShortCode);
+
+CREATE TABLE "backup_MatchSession" (
+    "ID"        blob(16) NOT NULL,
+    "LeagueID"  blob(16) NOT NULL,
+    "CreatedAt" INT  NOT NULL,
+    "StartDate" TEXT NOT NULL,
+    "Status" INT NOT NULL,
+    "PlayerIDs" TEXT NOT NULL,
+
+    PRIMARY KEY ("ID"),
+    FOREIGN KEY(LeagueID) REFERENCES League(ID) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+INSERT INTO "backup_MatchSession" ("ID", "LeagueID", "CreatedAt", "StartDate", "Status", "PlayerIDs")
+    SELECT "ID", "LeagueID", "CreatedAt", "StartDate", "Status", "PlayerIDs" FROM "MatchSession";
+
+DROP TABLE "MatchSession";
+ALTER TABLE "backup_MatchSession" RENAME TO "MatchSession";
+CREATE INDEX idx_Status ON MatchSession (Status);
+
+PRAGMA foreign_keys = ON;
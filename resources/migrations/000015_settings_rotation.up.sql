@@ -0,0 +1,7 @@
+-- JSON, eg. {"Type": "round-robin", "Payload": {"Settings": ["a@1", "b@1"]}},
+-- see internal/back/rotation.
+ALTER TABLE "League" ADD "SettingsRotation" TEXT NOT NULL DEFAULT '{}';
+
+-- Settings picked by the League SettingsRotation when the session was
+-- created, empty to use the League Settings.
+ALTER TABLE "MatchSession" ADD "Settings" TEXT NOT NULL DEFAULT '';
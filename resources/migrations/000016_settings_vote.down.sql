@@ -0,0 +1,28 @@
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE "backup_League" (
+    "ID"        blob(16) NOT NULL,
+    "CreatedAt" INT      NOT NULL,
+    "Name"      TEXT     NOT NULL,
+    "ShortCode" TEXT     NOT NULL,
+    "GameID"    blob(16) NOT NULL,
+    "Settings"  TEXT     NOT NULL,
+    "Schedule"  TEXT     NOT NULL,
+    "AnnounceDiscordChannelID" TEXT NULL,
+    "Generator" TEXT     NOT NULL DEFAULT '',
+    "FallbackGenerators" TEXT NOT NULL DEFAULT '',
+    "SettingsRotation" TEXT NOT NULL DEFAULT '{}',
+
+    PRIMARY KEY ("ID"),
+    FOREIGN KEY(GameID) REFERENCES Game(ID) ON UPDATE CASCADE ON DELETE RESTRICT
+);
+INSERT INTO "backup_League" ("ID", "CreatedAt", "Name", "ShortCode", "GameID", "Settings", "Schedule", "AnnounceDiscordChannelID", "Generator", "FallbackGenerators", "SettingsRotation")
+    SELECT "ID", "CreatedAt", "Name", "ShortCode", "GameID", "Settings", "Schedule", "AnnounceDiscordChannelID", "Generator", "FallbackGenerators", "SettingsRotation" FROM "League";
+
+DROP TABLE "League";
+ALTER TABLE "backup_League" RENAME TO "League";
+CREATE UNIQUE INDEX idx_unique_ShortCode ON League (ShortCode);
+
+DROP TABLE "SettingsVote";
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,14 @@
+-- Comma-separated list of settings runners can vote for, empty to disable
+-- voting.
+ALTER TABLE "League" ADD "SettingsVoteOptions" TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE "SettingsVote" (
+    "MatchSessionID" blob(16) NOT NULL,
+    "PlayerID"       blob(16) NOT NULL,
+    "CreatedAt"      INT      NOT NULL,
+    "Settings"       TEXT     NOT NULL, -- one of League.SettingsVoteOptions
+
+    PRIMARY KEY ("MatchSessionID", "PlayerID"),
+    FOREIGN KEY(MatchSessionID) REFERENCES MatchSession(ID) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY(PlayerID) REFERENCES Player(ID) ON UPDATE CASCADE ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+-- JSON oot.SeedDifficulty computed from the spoiler log, empty if unknown.
+ALTER TABLE "Match" ADD "Difficulty" TEXT NOT NULL DEFAULT '';
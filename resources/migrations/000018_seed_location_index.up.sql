@@ -0,0 +1,14 @@
+-- Item placements of the seeds of closed sessions, indexed once so they can
+-- be searched without decoding every spoiler log.
+CREATE TABLE "SeedLocation" (
+    "MatchID"  blob(16) NOT NULL,
+    "LeagueID" blob(16) NOT NULL,
+    "Location" TEXT     NOT NULL,
+    "Item"     TEXT     NOT NULL,
+
+    PRIMARY KEY ("MatchID", "Location"),
+    FOREIGN KEY(MatchID) REFERENCES Match(ID) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY(LeagueID) REFERENCES League(ID) ON UPDATE CASCADE ON DELETE CASCADE
+);
+CREATE INDEX idx_SeedLocation_Item ON SeedLocation (LeagueID, Item);
+CREATE INDEX idx_SeedLocation_Location ON SeedLocation (LeagueID, Location);
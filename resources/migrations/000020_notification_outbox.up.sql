@@ -0,0 +1,20 @@
+-- Notifications waiting to be sent, written in the same transaction as the
+-- state change they notify about. Sent notifications are deleted, the ones
+-- that failed too many times are kept as dead for the admins to look at.
+CREATE TABLE "NotificationOutbox" (
+    "ID"            blob(16) NOT NULL,
+    "CreatedAt"     INT      NOT NULL,
+    "Type"          INT      NOT NULL,
+    "RecipientType" INT      NOT NULL,
+    "Recipient"     TEXT     NOT NULL,
+    "Payload"       TEXT     NOT NULL, -- JSON
+    "Files"         blob     NOT NULL, -- zlib JSON
+
+    "Status"        INT      NOT NULL,
+    "Attempts"      INT      NOT NULL DEFAULT 0,
+    "NextAttemptAt" INT      NOT NULL,
+    "LastError"     TEXT     NOT NULL DEFAULT '',
+
+    PRIMARY KEY ("ID")
+);
+CREATE INDEX idx_NotificationOutbox_Status ON NotificationOutbox (Status, NextAttemptAt);
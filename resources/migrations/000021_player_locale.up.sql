@@ -0,0 +1,3 @@
+-- Language of the bot replies and notifications sent to the player, empty
+-- for the default one.
+ALTER TABLE "Player" ADD "Locale" text NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DELETE FROM "NotificationOutbox" WHERE "RecipientType" = 2;
+DROP TABLE "Webhook";
@@ -0,0 +1,11 @@
+-- HTTP endpoints of community tools receiving the notifications of the types
+-- they subscribed to, deliveries go through the NotificationOutbox.
+CREATE TABLE "Webhook" (
+    "ID"        blob(16) NOT NULL,
+    "CreatedAt" INT      NOT NULL,
+    "URL"       TEXT     NOT NULL,
+    "Secret"    TEXT     NOT NULL, -- HMAC-SHA256 key signing the payloads
+    "Types"     TEXT     NOT NULL, -- comma-separated NotificationType names
+
+    PRIMARY KEY ("ID")
+);
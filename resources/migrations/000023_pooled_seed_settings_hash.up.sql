@@ -0,0 +1,4 @@
+-- Hash of the settings files content at generation time, seeds pooled before
+-- this migration can't be verified and are discarded.
+ALTER TABLE "PooledSeed" ADD "SettingsHash" TEXT NOT NULL DEFAULT '';
+DELETE FROM "PooledSeed" WHERE "MatchID" IS NULL;
@@ -0,0 +1,34 @@
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE "backup_PooledSeed" (
+    "ID"        blob(16) NOT NULL,
+    "LeagueID"  blob(16) NOT NULL,
+    "MatchID"   blob(16) NULL,
+    "CreatedAt" INT      NOT NULL,
+
+    "Generator" TEXT NOT NULL,
+    "Settings"  TEXT NOT NULL,
+    "SettingsHash" TEXT NOT NULL DEFAULT '',
+    "Seed"      TEXT NOT NULL,
+
+    "SpoilerLog"     blob NOT NULL,
+    "GeneratorState" blob NOT NULL,
+    "SeedPatch"      blob NOT NULL,
+
+    PRIMARY KEY ("ID"),
+    FOREIGN KEY(LeagueID) REFERENCES League(ID) ON UPDATE CASCADE ON DELETE CASCADE
+);
+INSERT INTO "backup_PooledSeed" (
+    "ID", "LeagueID", "MatchID", "CreatedAt", "Generator", "Settings", "SettingsHash", "Seed",
+    "SpoilerLog", "GeneratorState", "SeedPatch"
+) SELECT
+    "ID", "LeagueID", "MatchID", "CreatedAt", "Generator", "Settings", "SettingsHash", "Seed",
+    "SpoilerLog", "GeneratorState", "SeedPatch"
+FROM "PooledSeed";
+
+DROP TABLE "PooledSeed";
+ALTER TABLE "backup_PooledSeed" RENAME TO "PooledSeed";
+CREATE INDEX idx_PooledSeed_LeagueID ON PooledSeed (LeagueID);
+CREATE UNIQUE INDEX idx_unique_PooledSeed_MatchID ON PooledSeed (MatchID);
+
+PRAGMA foreign_keys = ON;
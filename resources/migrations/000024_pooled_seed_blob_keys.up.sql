@@ -0,0 +1,3 @@
+-- Keys of the PooledSeed blobs when stored outside of the DB, see internal/blob.
+ALTER TABLE "PooledSeed" ADD "SpoilerLogKey" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "PooledSeed" ADD "SeedPatchKey" TEXT NOT NULL DEFAULT '';
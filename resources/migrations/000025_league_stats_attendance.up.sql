@@ -0,0 +1,5 @@
+-- Attendance and seed times are now stored with the league stats, existing
+-- stats lack them and are discarded to be rebuilt when the Back starts.
+DELETE FROM "LeagueStats";
+ALTER TABLE "LeagueStats" ADD "Attendance" TEXT NOT NULL DEFAULT '{}'; -- JSON Attendance
+ALTER TABLE "LeagueStats" ADD "SeedTimes" TEXT NOT NULL DEFAULT '[]'; -- JSON SeedTimes
@@ -0,0 +1,35 @@
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE "backup_LeagueStats" (
+    "LeagueID"  blob(16) NOT NULL,
+    "UpdatedAt" INT      NOT NULL,
+
+    "RankedPlayers"         INT NOT NULL,
+    "PlayersOnLeaderboard"  INT NOT NULL,
+    "SeedsPlayed"           INT NOT NULL,
+    "Forfeits"              INT NOT NULL,
+    "DoubleForfeits"        INT NOT NULL,
+    "FirstLadderRace"       TEXT NOT NULL,
+    "TotalSeedTime"         INT NOT NULL,
+    "AveragePlayersPerRace" INT NOT NULL,
+    "MostPlayersInARace"    INT NOT NULL,
+
+    "SeedStats"  TEXT NOT NULL, -- JSON seedstats.Stats
+    "Attendance" TEXT NOT NULL DEFAULT '{}', -- JSON Attendance
+    "SeedTimes"  TEXT NOT NULL DEFAULT '[]', -- JSON SeedTimes
+
+    PRIMARY KEY ("LeagueID"),
+    FOREIGN KEY(LeagueID) REFERENCES League(ID) ON UPDATE CASCADE ON DELETE CASCADE
+);
+INSERT INTO "backup_LeagueStats" SELECT
+    "LeagueID", "UpdatedAt",
+    "RankedPlayers", "PlayersOnLeaderboard", "SeedsPlayed", "Forfeits",
+    "DoubleForfeits", "FirstLadderRace", "TotalSeedTime",
+    "AveragePlayersPerRace", "MostPlayersInARace",
+    "SeedStats", "Attendance", "SeedTimes"
+FROM "LeagueStats";
+
+DROP TABLE "LeagueStats";
+ALTER TABLE "backup_LeagueStats" RENAME TO "LeagueStats";
+
+PRAGMA foreign_keys = ON;
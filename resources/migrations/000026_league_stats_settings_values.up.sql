@@ -0,0 +1,4 @@
+-- Settings value stats are now stored with the league stats, existing stats
+-- lack them and are discarded to be rebuilt when the Back starts.
+DELETE FROM "LeagueStats";
+ALTER TABLE "LeagueStats" ADD "SettingsValues" TEXT NOT NULL DEFAULT '[]'; -- JSON SettingsValueStats
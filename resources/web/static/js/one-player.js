@@ -2,4 +2,5 @@
 
 (function (){
     window.Kaepora.bindFragmentlessTabs("PlayerPerformances");
+    window.Kaepora.bindSortableTables(".js-table-sortable");
 })();
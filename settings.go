@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"kaepora/internal/back"
+	"kaepora/internal/generator"
+	"kaepora/internal/generator/oot"
+	"kaepora/internal/generator/oot/settings"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// settingsValidationShuffles is the number of shuffles run to detect budget
+// overshoots and iteration-limit hits.
+const settingsValidationShuffles = 10240
+
+// validateSettings reports everything that could go wrong with a shuffled
+// settings file, it returns an error if the file cannot be used.
+func validateSettings(w io.Writer, name string) error {
+	if name == "" {
+		return errors.New("you must specify a shuffled JSON configuration file name")
+	}
+
+	baseDir, err := oot.GetBaseDir()
+	if err != nil {
+		return err
+	}
+
+	s, err := settings.Read(generator.SettingsPath(baseDir, name))
+	if err != nil {
+		return err
+	}
+
+	invalid := s.Validate()
+	if invalid != nil {
+		fmt.Fprintf(w, "%s\n\n", invalid)
+	}
+
+	doc, err := back.LoadSettingsDocumentation("en")
+	if err != nil {
+		return err
+	}
+	printSettingsSection(w, "Undocumented settings and values", undocumentedSettings(s, doc))
+	budget := s.Parameters.GetCostBudget()
+	printSettingsSection(w, "Unreachable values", s.UnreachableValues(budget))
+
+	var overshoots, limitHits, maxOvershoot int
+	for i := 0; i < settingsValidationShuffles; i++ {
+		_, report := s.Explain(strconv.Itoa(i))
+		if report.HitIterationLimit {
+			limitHits++
+		}
+		if v := report.Overshoot(); v > 0 {
+			overshoots++
+			if v > maxOvershoot {
+				maxOvershoot = v
+			}
+		}
+	}
+	fmt.Fprintf(
+		w, "Over %d shuffles with a budget of %d:\n", settingsValidationShuffles, budget,
+	)
+	fmt.Fprintf(w, "    %d went over budget (by up to %d)\n", overshoots, maxOvershoot)
+	fmt.Fprintf(w, "    %d hit the iteration limit\n", limitHits)
+
+	if invalid != nil {
+		return errors.New("invalid shuffled settings")
+	}
+
+	return nil
+}
+
+// undocumentedSettings returns the settings and values of s that have no
+// entry in the documentation and would show up blank on the website.
+func undocumentedSettings(s settings.Settings, doc back.SettingsDocumentation) []string {
+	var ret []string
+	for name, possible := range s.Values {
+		entry, ok := doc[name]
+		if !ok {
+			ret = append(ret, fmt.Sprintf("%s: undocumented setting", name))
+			continue
+		}
+
+		for _, v := range possible {
+			if entry.GetValueEntry(v.Value).Title == "" {
+				ret = append(ret, fmt.Sprintf("%s=%v: undocumented value", name, v.Value))
+			}
+		}
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+func printSettingsSection(w io.Writer, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, v := range lines {
+		fmt.Fprintf(w, "    %s\n", v)
+	}
+	fmt.Fprintln(w)
+}
+
+// explainSettings prints the steps taken to shuffle the settings of the given
+// file with the given seed. If name is in the League form
+// "base.json:shuffled.json" the merged settings are printed too.
+func explainSettings(w io.Writer, name, seed string) error {
+	if name == "" || seed == "" {
+		return errors.New("you must specify a shuffled JSON configuration file name and a seed")
+	}
+
+	var base string
+	if parts := strings.SplitN(name, ":", 2); len(parts) == 2 {
+		base, name = parts[0], parts[1]
+	}
+
+	baseDir, err := oot.GetBaseDir()
+	if err != nil {
+		return err
+	}
+
+	s, err := settings.Load(generator.SettingsPath(baseDir, name))
+	if err != nil {
+		return err
+	}
+
+	shuffled, report := s.Explain(seed)
+	rejected := map[string]struct{}{}
+	var repeated int
+	for _, v := range report.Steps {
+		if v.Rejected != "" {
+			// The same value is usually rejected on every iteration.
+			key := fmt.Sprintf("%s=%v:%s", v.Setting, jsonValue(v.Value), v.Rejected)
+			if _, ok := rejected[key]; ok {
+				repeated++
+				continue
+			}
+			rejected[key] = struct{}{}
+		}
+
+		fmt.Fprintf(w, "#%03d %s=%v (cost %d): ", v.Iteration, v.Setting, jsonValue(v.Value), v.Cost)
+		switch {
+		case v.Rejected != "":
+			fmt.Fprintf(w, "rejected, %s\n", v.Rejected)
+			continue
+		case v.Forced:
+			fmt.Fprintf(w, "forced by a group minimum, total %d\n", v.TotalCost)
+		default:
+			fmt.Fprintf(w, "picked, total %d\n", v.TotalCost)
+		}
+
+		implied := make([]string, 0, len(v.Implies))
+		for k, value := range v.Implies {
+			implied = append(implied, fmt.Sprintf("%s=%v", k, jsonValue(value)))
+		}
+		sort.Strings(implied)
+		for _, v := range implied {
+			fmt.Fprintf(w, "     implies %s\n", v)
+		}
+	}
+
+	if repeated > 0 {
+		fmt.Fprintf(w, "(%d repeated rejections omitted)\n", repeated)
+	}
+
+	fmt.Fprintf(
+		w, "\nFinal cost %d/%d after %d iterations", report.Cost, report.CostMax, report.Iterations,
+	)
+	if report.HitIterationLimit {
+		fmt.Fprint(w, " (iteration limit reached)")
+	}
+	fmt.Fprint(w, "\n\nMerge patch:\n")
+
+	patch, err := json.MarshalIndent(shuffled, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s\n", patch)
+
+	if base == "" {
+		return nil
+	}
+
+	merged, err := oot.MergeShuffledSettings(shuffled, base)
+	if err != nil {
+		return err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, merged, "", "    "); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nMerged with %s:\n%s\n", base, indented.String())
+
+	return nil
+}
+
+func jsonValue(v interface{}) string {
+	str, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(str)
+}
+
+func runSettingsCommand(args []string) error {
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+
+	switch arg(0) {
+	case "validate":
+		return validateSettings(os.Stdout, arg(1))
+	case "explain":
+		return explainSettings(os.Stdout, arg(1), arg(2))
+	default:
+		return generateSettingsStats(arg(0))
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func writeTestShuffledSettings(t *testing.T, contents string) string {
+	t.Helper()
+
+	f, err := ioutil.TempFile("", "kaepora-shuffled-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Remove(f.Name())
+	})
+
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	return f.Name()
+}
+
+func TestValidateSettings(t *testing.T) {
+	name := writeTestShuffledSettings(t, `{
+		"a": [{"Value": 1, "Cost": 1, "Weight": 1}],
+		"b": [{"Value": 1, "Cost": 1, "Weight": 1, "Requires": {"a": 1}}],
+		"$groups": [{"Name": "g", "Settings": ["a", "b"], "Exclusive": true}],
+		"$parameters": {"CostBudget": 2}
+	}`)
+
+	var w bytes.Buffer
+	if err := validateSettings(&w, name); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"Unreachable values:\n    b=1: requires 2 settings of group g which allows 1\n",
+		"with a budget of 2",
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, w.String())
+		}
+	}
+
+	invalid := writeTestShuffledSettings(t, `{"a": [{"Value": 1, "Requires": {"a": 1}}]}`)
+	if err := validateSettings(&w, invalid); err == nil {
+		t.Error("expected invalid settings to be rejected")
+	}
+}
+
+func TestExplainSettings(t *testing.T) {
+	name := writeTestShuffledSettings(t, `{
+		"a": [{"Value": 1, "Cost": 1, "Weight": 1, "Implies": {"c": true}}],
+		"b": [{"Value": 1, "Cost": 50, "Weight": 1}]
+	}`)
+
+	var w bytes.Buffer
+	if err := explainSettings(&w, name, "seed"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"a=1 (cost 1): picked, total 1\n     implies c=true\n",
+		"b=1 (cost 50): rejected, over budget\n",
+		"Merge patch:\n",
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, w.String())
+		}
+	}
+
+	if err := explainSettings(&w, name, ""); err == nil {
+		t.Error("expected a missing seed to be rejected")
+	}
+}